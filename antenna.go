@@ -78,6 +78,12 @@ func (app *AntennaApp) Run(in io.Reader, out io.Writer, eout io.Writer, cfgName
 		return app.GenerateCSS(out, cfgName, args)
 	case "items":
 		return app.Items(out, cfgName, args)
+	case "feeds":
+		return app.Feeds(out, cfgName, args)
+	case "discover":
+		return app.Discover(out, eout, cfgName, args)
+	case "prune":
+		return app.Prune(out, cfgName, args)
 	case "list":
 		return app.ListCollectionFiles(out, cfgName, args)
 	case "harvest", "fetch":
@@ -0,0 +1,172 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// archiveKeyRE matches the key of a year or month archive page
+var archiveKeyRE = regexp.MustCompile(`^[0-9]{4}(-[0-9]{2})?$`)
+
+// archivePeriod is a year or a month of items in the archive
+type archivePeriod struct {
+	// Key is the year, "2025", or year and month, "2025-09"
+	Key    string
+	Name   string
+	Count  int
+	Months []*archivePeriod
+}
+
+// archivePeriods returns the years of SQLArchiveMonths, newest first,
+// each holding its months.
+func archivePeriods(db *sql.DB) ([]*archivePeriod, error) {
+	rows, err := db.Query(SQLArchiveMonths)
+	if err != nil {
+		return nil, fmt.Errorf("%s\nstmt: %s", err, SQLArchiveMonths)
+	}
+	defer rows.Close()
+	years := []*archivePeriod{}
+	for rows.Next() {
+		month := &archivePeriod{}
+		if err := rows.Scan(&month.Key, &month.Count); err != nil {
+			return nil, err
+		}
+		t, err := time.Parse("2006-01", month.Key)
+		if err != nil {
+			continue
+		}
+		month.Name = t.Format("January 2006")
+		if len(years) == 0 || years[len(years)-1].Key != t.Format("2006") {
+			years = append(years, &archivePeriod{Key: t.Format("2006"), Name: t.Format("2006")})
+		}
+		year := years[len(years)-1]
+		year.Count += month.Count
+		year.Months = append(year.Months, month)
+	}
+	return years, rows.Err()
+}
+
+// archiveName returns the file name of an archive page for htmlName,
+// NAME-archive.html for the index and NAME-archive-KEY.html for a year
+// or month.
+func archiveName(htmlName string, key string) string {
+	xName := filepath.Ext(htmlName)
+	if key == "" {
+		return strings.TrimSuffix(htmlName, xName) + "-archive" + xName
+	}
+	return strings.TrimSuffix(htmlName, xName) + "-archive-" + key + xName
+}
+
+// removeStaleArchives removes the year and month archive pages of
+// htmlName that aren't in periods, e.g. left from a month whose items
+// have since been removed or unpublished.
+func removeStaleArchives(htmlName string, periods []*archivePeriod) error {
+	current := map[string]bool{}
+	for _, period := range periods {
+		current[period.Key] = true
+	}
+	xName := filepath.Ext(htmlName)
+	prefix := strings.TrimSuffix(filepath.Base(htmlName), xName) + "-archive-"
+	entries, err := os.ReadDir(filepath.Dir(htmlName))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || filepath.Ext(name) != xName {
+			continue
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(name, prefix), xName)
+		if !archiveKeyRE.MatchString(key) || current[key] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(filepath.Dir(htmlName), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeArchiveIndex writes a page listing each year and month with
+// their item counts.
+func (gen *Generator) writeArchiveIndex(out io.Writer, htmlName string, years []*archivePeriod) error {
+	content := new(bytes.Buffer)
+	fmt.Fprintln(content, "    <ul>")
+	for _, year := range years {
+		fmt.Fprintf(content, "      <li><a href=\"%s\">%s</a> (%d)\n", html.EscapeString(filepath.Base(archiveName(htmlName, year.Key))),
+			html.EscapeString(year.Name), year.Count)
+		fmt.Fprintln(content, "        <ul>")
+		for _, month := range year.Months {
+			fmt.Fprintf(content, "          <li><a href=\"%s\">%s</a> (%d)</li>\n", html.EscapeString(filepath.Base(archiveName(htmlName, month.Key))),
+				html.EscapeString(month.Name), month.Count)
+		}
+		fmt.Fprintln(content, "        </ul>")
+		fmt.Fprintln(content, "      </li>")
+	}
+	fmt.Fprintln(content, "    </ul>")
+	return gen.writeListPage(out, "Archive", content.String())
+}
+
+// GenerateArchive writes a page for each year and month of the
+// collection's published items, NAME-archive-YYYY.html and
+// NAME-archive-YYYY-MM.html, and an archive index, NAME-archive.html,
+// next to the collection's page, htmlName. The pages of years and months
+// without published items are removed.
+func (gen *Generator) GenerateArchive(db *sql.DB, htmlName string, collection *Collection) error {
+	years, err := archivePeriods(db)
+	if err != nil {
+		return err
+	}
+	title := gen.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(collection.File), filepath.Ext(collection.File))
+	}
+	periods := []*archivePeriod{}
+	for _, year := range years {
+		periods = append(periods, year)
+		periods = append(periods, year.Months...)
+	}
+	for _, period := range periods {
+		page := *gen
+		page.Title = fmt.Sprintf("%s: %s", title, period.Name)
+		page.pagePath = webPath(gen.htdocs, archiveName(htmlName, period.Key))
+		if err := writeFile(archiveName(htmlName, period.Key), func(out io.Writer) error {
+			return page.WriteCustomHTML(out, db, period.Name, SQLDisplayDateItems, period.Key)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := removeStaleArchives(htmlName, periods); err != nil {
+		return err
+	}
+	index := *gen
+	index.Title = fmt.Sprintf("%s: Archive", title)
+	index.pagePath = webPath(gen.htdocs, archiveName(htmlName, ""))
+	return writeFile(archiveName(htmlName, ""), func(out io.Writer) error {
+		return index.writeArchiveIndex(out, htmlName, years)
+	})
+}
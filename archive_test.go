@@ -0,0 +1,104 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateArchive(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for _, item := range []struct {
+		link, pubDate, status string
+	}{
+		{"https://example.com/one", "2024-12-31 23:00:00", "published"},
+		{"https://example.com/two", "2025-01-15T08:00:00Z", "published"},
+		{"https://example.com/three", "2025-09-01", "published"},
+		{"https://example.com/four", "2025-09-20 10:00:00", "draft"},
+		{"https://example.com/five", "Mon, 01 Sep 2025 10:00:00 GMT", "published"},
+	} {
+		if _, err := db.Exec(`INSERT INTO items (link, title, description, pubDate, status)
+			VALUES (?, 'Title', 'An item', ?, ?)`, item.link, item.pubDate, item.status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	htmlName := filepath.Join(t.TempDir(), "blog.html")
+	gen, _ := NewGenerator("antenna", "https://example.com")
+	gen.eout = io.Discard
+	gen.Title = "Blog"
+	gen.Header = "<h1>Blog</h1>"
+	stale := []string{archiveName(htmlName, "2019"), archiveName(htmlName, "2019-03")}
+	kept := []string{archiveName(htmlName, "notes"), pageName(htmlName, 2)}
+	for _, fName := range append(stale, kept...) {
+		if err := os.WriteFile(fName, []byte("<html></html>"), 0664); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gen.GenerateArchive(db, htmlName, &Collection{File: "blog.md"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, fName := range stale {
+		if _, err := os.Stat(fName); err == nil {
+			t.Errorf("expected the stale archive page %s to be removed", fName)
+		}
+	}
+	for _, fName := range kept {
+		if _, err := os.Stat(fName); err != nil {
+			t.Errorf("expected %s to be kept, %s", fName, err)
+		}
+	}
+	index, err := os.ReadFile(archiveName(htmlName, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<a href="blog-archive-2025.html">2025</a> (2)`,
+		`<a href="blog-archive-2025-09.html">September 2025</a> (1)`,
+		`<a href="blog-archive-2025-01.html">January 2025</a> (1)`,
+		`<a href="blog-archive-2024.html">2024</a> (1)`,
+		"<title>Blog: Archive</title>",
+	} {
+		if !strings.Contains(string(index), expected) {
+			t.Errorf("expected %q in the archive index\n%s", expected, index)
+		}
+	}
+	if strings.Index(string(index), "2025-09") > strings.Index(string(index), "2025-01") {
+		t.Errorf("expected the newest month first\n%s", index)
+	}
+	page, err := os.ReadFile(archiveName(htmlName, "2025"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "https://example.com/two") || !strings.Contains(string(page), "https://example.com/three") ||
+		strings.Contains(string(page), "https://example.com/one") || strings.Contains(string(page), "https://example.com/four") {
+		t.Errorf("unexpected items in the 2025 page\n%s", page)
+	}
+	page, err = os.ReadFile(archiveName(htmlName, "2025-09"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "<h2>September 2025</h2>") || !strings.Contains(string(page), "https://example.com/three") ||
+		strings.Contains(string(page), "https://example.com/two") {
+		t.Errorf("unexpected items in the September 2025 page\n%s", page)
+	}
+}
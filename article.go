@@ -0,0 +1,400 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	// 3rd Party pacakges
+	html2md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+)
+
+// fullArticle reports if the articles behind the items of the feed at
+// link should be retrieved, either for every feed in the collection or
+// for the feeds listed by URL or label in full_article_feeds.
+func (collection *Collection) fullArticle(link Link) bool {
+	if collection.FullArticle {
+		return true
+	}
+	for _, feed := range collection.FullArticleFeeds {
+		if feed == link.URL || feed == link.Label {
+			return true
+		}
+	}
+	return false
+}
+
+// articleFetcher retrieves the web page an item links to and extracts
+// the article from it. Items whose article has already been saved are
+// skipped.
+type articleFetcher struct {
+	client    *http.Client
+	userAgent string
+	// saved holds the links of the items with a saved article
+	saved map[string]bool
+	// hosts limits the requests made at once to each host
+	hosts *hostLimiter
+}
+
+// newArticleFetcher returns an articleFetcher that skips the items in db
+// which already have their article saved. Its requests wait on hosts,
+// the limiter of the harvest's feed requests.
+func newArticleFetcher(db *sql.DB, userAgent string, timeout time.Duration, hosts *hostLimiter) (*articleFetcher, error) {
+	saved := map[string]bool{}
+	rows, err := db.Query(SQLFullArticleLinks)
+	if err != nil {
+		return nil, fmt.Errorf("%s\nstmt: %s", err, SQLFullArticleLinks)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, err
+		}
+		saved[link] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &articleFetcher{
+		client: &http.Client{
+			CheckRedirect: redirectHandler,
+			Timeout:       timeout,
+		},
+		userAgent: userAgent,
+		saved:     saved,
+		hosts:     hosts,
+	}, nil
+}
+
+// fetchArticles retrieves the articles for the items in feed which don't
+// have one saved yet. It returns the article Markdown keyed by item link
+// along with the errors for the articles that couldn't be retrieved.
+// Only http and https links are followed.
+func (fetcher *articleFetcher) fetchArticles(feed *gofeed.Feed) (map[string]string, []error) {
+	articles := map[string]string{}
+	errList := []error{}
+	for _, item := range feed.Items {
+		link := harvestItemLink(feed, item)
+		if fetcher.saved[link] || articles[link] != "" {
+			continue
+		}
+		if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+			continue
+		}
+		src, err := fetcher.fetchArticle(link)
+		if err != nil {
+			errList = append(errList, fmt.Errorf("article %s, %s", link, err))
+			continue
+		}
+		articles[link] = src
+	}
+	return articles, errList
+}
+
+// fetchArticle retrieves the page at href and returns its article as
+// Markdown.
+func (fetcher *articleFetcher) fetchArticle(href string) (string, error) {
+	req, err := http.NewRequest("GET", href, nil)
+	if err != nil {
+		return "", err
+	}
+	if fetcher.userAgent == "" {
+		req.Header.Set("User-Agent", fmt.Sprintf("antenna/%s %s", Version, ReleaseHash))
+	} else {
+		req.Header.Set("User-Agent", fetcher.userAgent)
+	}
+	req.Header.Set("accept", "text/html, application/xhtml+xml;q=0.9, */*;q=0.8")
+	if fetcher.hosts != nil {
+		release := fetcher.hosts.acquire(href)
+		defer release()
+	}
+	res, err := fetcher.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", &statusError{StatusCode: res.StatusCode, Status: res.Status}
+	}
+	src, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if !isHTMLDocument(res.Header.Get("Content-Type"), src) {
+		return "", fmt.Errorf("not an HTML page")
+	}
+	return articleMarkdown(src, res.Request.URL)
+}
+
+// articleMarkdown extracts the article from the HTML page in src and
+// converts it to Markdown. Relative links are resolved against base.
+func articleMarkdown(src []byte, base *url.URL) (string, error) {
+	innerHTML, err := extractArticle(src, base)
+	if err != nil {
+		return "", err
+	}
+	converter := html2md.NewConverter("", true, nil)
+	markdown, err := converter.ConvertString(innerHTML)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(markdown), nil
+}
+
+// articleSkipTags are elements that never hold article text
+var articleSkipTags = map[string]bool{
+	"aside":    true,
+	"button":   true,
+	"embed":    true,
+	"footer":   true,
+	"form":     true,
+	"header":   true,
+	"iframe":   true,
+	"input":    true,
+	"nav":      true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"select":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+}
+
+var (
+	// unlikelyArticle matches the class and id of page furniture such as
+	// sidebars, comments and share buttons
+	unlikelyArticle = regexp.MustCompile(`(?i)\bads?\b|advert|banner|comment|cookie|footer|menu|modal|nav|newsletter|popup|promo|related|share|sidebar|social|sponsor|subscribe`)
+	// likelyArticle matches the class and id of the containers articles
+	// are usually found in
+	likelyArticle = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+)
+
+// minParagraphLength is the number of characters a paragraph needs before
+// it counts towards the score of its container
+const minParagraphLength = 25
+
+// extractArticle finds the main content of the HTML page in src, in the
+// manner of Readability. Page furniture is removed first, then the largest
+// <article> or the <main> element is used when the page has one. Otherwise
+// each paragraph scores its parent and grandparent and the container with
+// the highest score, discounted by its share of link text, is taken to be
+// the article. The inner HTML of the article is returned with its links
+// resolved against base.
+func extractArticle(src []byte, base *url.URL) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(src))
+	if err != nil {
+		return "", err
+	}
+	removeUnlikely(doc)
+	article := largestElement(doc, func(n *html.Node) bool { return n.Data == "article" })
+	if article == nil {
+		article = largestElement(doc, func(n *html.Node) bool {
+			return n.Data == "main" || nodeAttr(n, "role") == "main"
+		})
+	}
+	if article == nil {
+		article = highestScoring(doc)
+	}
+	if article == nil || textLength(article) == 0 {
+		return "", fmt.Errorf("no article found")
+	}
+	resolveArticleLinks(article, base)
+	buf := new(bytes.Buffer)
+	for c := article.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(buf, c); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// nodeAttr returns the value of the attribute key of n
+func nodeAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// removeUnlikely removes the elements listed in articleSkipTags and those
+// whose class or id look like page furniture rather than content.
+func removeUnlikely(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && c.Data != "body" && c.Data != "html" {
+			names := nodeAttr(c, "class") + " " + nodeAttr(c, "id")
+			if articleSkipTags[c.Data] || (unlikelyArticle.MatchString(names) && !likelyArticle.MatchString(names)) {
+				n.RemoveChild(c)
+				c = next
+				continue
+			}
+		}
+		removeUnlikely(c)
+		c = next
+	}
+}
+
+// textLength returns the number of characters of text in n, ignoring
+// runs of white space
+func textLength(n *html.Node) int {
+	if n.Type == html.TextNode {
+		return len(strings.Join(strings.Fields(n.Data), " "))
+	}
+	total := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		total += textLength(c)
+	}
+	return total
+}
+
+// linkDensity returns the share of the text in n that is link text
+func linkDensity(n *html.Node) float64 {
+	total := textLength(n)
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			links += textLength(n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return float64(links) / float64(total)
+}
+
+// largestElement returns the element matching match with the most text
+func largestElement(doc *html.Node, match func(*html.Node) bool) *html.Node {
+	var (
+		found *html.Node
+		size  int
+		walk  func(*html.Node)
+	)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && match(n) {
+			if l := textLength(n); l > size {
+				found, size = n, l
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return found
+}
+
+// highestScoring scores the containers of each paragraph and returns the
+// one with the highest score.
+func highestScoring(doc *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	// candidates holds the scored containers in document order so ties
+	// go to the first one
+	candidates := []*html.Node{}
+	addScore := func(n *html.Node, score float64) {
+		if _, ok := scores[n]; !ok {
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.Data == "p" || n.Data == "pre") {
+			if l := textLength(n); l >= minParagraphLength {
+				text := new(strings.Builder)
+				collectText(text, n)
+				score := 1 + float64(strings.Count(text.String(), ",")) + float64(min(l/100, 3))
+				if parent := n.Parent; parent != nil {
+					addScore(parent, score)
+					if grandparent := parent.Parent; grandparent != nil {
+						addScore(grandparent, score/2)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+	for _, n := range candidates {
+		score := scores[n]
+		names := nodeAttr(n, "class") + " " + nodeAttr(n, "id")
+		if likelyArticle.MatchString(names) {
+			score += 5
+		}
+		score *= 1 - linkDensity(n)
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+// collectText writes the text of n to buf
+func collectText(buf *strings.Builder, n *html.Node) {
+	if n.Type == html.TextNode {
+		buf.WriteString(n.Data)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectText(buf, c)
+	}
+}
+
+// resolveArticleLinks makes the href and src attributes in n absolute
+func resolveArticleLinks(n *html.Node, base *url.URL) {
+	if base == nil {
+		return
+	}
+	if n.Type == html.ElementNode {
+		for i, attr := range n.Attr {
+			if attr.Key != "href" && attr.Key != "src" {
+				continue
+			}
+			if u, err := url.Parse(strings.TrimSpace(attr.Val)); err == nil {
+				n.Attr[i].Val = base.ResolveReference(u).String()
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		resolveArticleLinks(c, base)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArticleMarkdown(t *testing.T) {
+	for fName, expected := range map[string]struct {
+		base     string
+		contains []string
+		excludes []string
+	}{
+		"blog-post.html": {
+			base: "https://blog.example.org/2025/09/harvest/",
+			contains: []string{
+				"# Harvesting feeds with Antenna",
+				"saves their items in an SQLite database",
+				"[link](https://blog.example.org/2025/09/harvest/)",
+				"(https://blog.example.org/2025/09/harvest/images/diagram.png)",
+			},
+			excludes: []string{"Archive", "Share this post", "Popular posts", "Copyright", "trackPageView"},
+		},
+		"news-story.html": {
+			base: "https://news.example.com/2025/library/",
+			contains: []string{
+				"## City council approves new library",
+				"ending a debate that lasted more than three years",
+				"house a maker space and community rooms",
+			},
+			excludes: []string{"Sports", "related story", "First comment", "newsletter", "font-family"},
+		},
+	} {
+		src, err := os.ReadFile(filepath.Join("testdata", "articles", fName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		base, _ := url.Parse(expected.base)
+		markdown, err := articleMarkdown(src, base)
+		if err != nil {
+			t.Errorf("%s: %s", fName, err)
+			continue
+		}
+		for _, s := range expected.contains {
+			if !strings.Contains(markdown, s) {
+				t.Errorf("%s: expected %q in article, got\n%s", fName, s, markdown)
+			}
+		}
+		for _, s := range expected.excludes {
+			if strings.Contains(markdown, s) {
+				t.Errorf("%s: expected %q to be left out of the article, got\n%s", fName, s, markdown)
+			}
+		}
+	}
+	if _, err := articleMarkdown([]byte(`<html><body><script>x()</script></body></html>`), nil); err == nil {
+		t.Errorf("expected an error for a page without an article")
+	}
+}
+
+func TestHarvestFullArticle(t *testing.T) {
+	article, err := os.ReadFile(filepath.Join("testdata", "articles", "blog-post.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>A Blog</title>
+    <link>http://%s/</link>
+    <description>A test feed</description>
+    <item>
+      <title>Harvesting feeds with Antenna</title>
+      <link>/2025/09/harvest/</link>
+      <description>How a harvest works.</description>
+    </item>
+  </channel>
+</rss>`, r.Host)
+		case "/2025/09/harvest/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(article)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "planet.md")
+	src := fmt.Sprintf("# Planet\n\n- [A Blog](%s/feed.xml)\n", ts.URL)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{
+		File:             cName,
+		DbName:           filepath.Join(dName, "planet.db"),
+		Concurrency:      1,
+		FullArticleFeeds: []string{"A Blog"},
+	}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := 0; i < 2; i++ {
+		if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+			t.Fatalf("Harvest: %s", err)
+		}
+	}
+	var (
+		link           string
+		sourceMarkdown string
+		fullMarkdown   string
+	)
+	if err := db.QueryRow(`SELECT link, sourceMarkdown, fullMarkdown FROM items`).Scan(&link, &sourceMarkdown, &fullMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	if link != ts.URL+"/2025/09/harvest/" {
+		t.Errorf("unexpected item link %q", link)
+	}
+	if sourceMarkdown != "How a harvest works." {
+		t.Errorf("expected the description in sourceMarkdown, got %q", sourceMarkdown)
+	}
+	if !strings.Contains(fullMarkdown, "saves their items in an SQLite database") {
+		t.Errorf("expected the article in fullMarkdown, got %q", fullMarkdown)
+	}
+	if requests["/2025/09/harvest/"] != 1 {
+		t.Errorf("expected the saved article to be retrieved once, got %d requests", requests["/2025/09/harvest/"])
+	}
+}
+
+func TestHarvestArticlesHostLimit(t *testing.T) {
+	article, err := os.ReadFile(filepath.Join("testdata", "articles", "blog-post.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, ".xml") {
+			name := strings.TrimSuffix(r.URL.Path, ".xml")
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>%s</title>
+    <link>http://%s/</link>
+    <description>A test feed</description>
+    <item><title>One</title><link>%s/one/</link><description>One.</description></item>
+    <item><title>Two</title><link>%s/two/</link><description>Two.</description></item>
+  </channel>
+</rss>`, name, r.Host, name, name)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(article)
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "planet.md")
+	src := []string{"# Planet", ""}
+	for i := 0; i < 4; i++ {
+		src = append(src, fmt.Sprintf("- [Feed %d](%s/feed%d.xml)", i, ts.URL, i))
+	}
+	if err := os.WriteFile(cName, []byte(strings.Join(src, "\n")), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{
+		File:            cName,
+		DbName:          filepath.Join(dName, "planet.db"),
+		Concurrency:     4,
+		HostConcurrency: 1,
+		FullArticle:     true,
+	}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	if maxInFlight != 1 {
+		t.Errorf("expected feed and article requests to keep to one at a time for the host, got %d", maxInFlight)
+	}
+}
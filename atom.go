@@ -0,0 +1,257 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+// atomNamespace is the XML namespace of Atom 1.0, see RFC 4287
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// atomID returns an Atom id for an entry. Atom requires an IRI so the
+// guid is used when it is an absolute URI, otherwise the link.
+func atomID(guid string, link string) string {
+	guid = strings.TrimSpace(guid)
+	if u, err := url.Parse(guid); err == nil && u.IsAbs() {
+		return guid
+	}
+	if link = strings.TrimSpace(link); link != "" {
+		return link
+	}
+	if guid != "" {
+		return "urn:antenna:" + url.PathEscape(guid)
+	}
+	return ""
+}
+
+// WriteItemAtom writes an item as an Atom entry. entryUpdated is the
+// RFC 3339 timestamp to use when the item has no date of its own.
+func (gen *Generator) WriteItemAtom(out io.Writer, link string, title string, description string, authors []*gofeed.Person,
+	enclosures []*Enclosure, guid string, pubDate string, updated string, sourceMarkdown string, categories string, entryUpdated string) error {
+	fmt.Fprintf(out, "  <entry>\n")
+	defer fmt.Fprintf(out, "  </entry>\n")
+	fmt.Fprintf(out, "    <id>%s</id>\n", toXMLString(atomID(guid, link)))
+	fmt.Fprintf(out, "    <title>%s</title>\n", strings.TrimSpace(toXMLString(title)))
+	if link != "" {
+		fmt.Fprintf(out, "    <link rel=\"alternate\" href=\"%s\" />\n", strings.TrimSpace(toXMLString(link)))
+	}
+	published := rfc3339Date(pubDate)
+	modified := rfc3339Date(updated)
+	if modified == "" {
+		modified = published
+	}
+	if modified == "" {
+		modified = entryUpdated
+	}
+	fmt.Fprintf(out, "    <updated>%s</updated>\n", modified)
+	if published != "" {
+		fmt.Fprintf(out, "    <published>%s</published>\n", published)
+	}
+	for _, author := range authors {
+		if author == nil || (author.Name == "" && author.Email == "") {
+			continue
+		}
+		name := author.Name
+		if name == "" {
+			name = author.Email
+		}
+		fmt.Fprintf(out, "    <author>\n      <name>%s</name>\n", toXMLString(name))
+		if author.Email != "" {
+			fmt.Fprintf(out, "      <email>%s</email>\n", toXMLString(author.Email))
+		}
+		fmt.Fprintf(out, "    </author>\n")
+	}
+	if categories != "" {
+		var cats []string
+		if err := json.Unmarshal([]byte(categories), &cats); err == nil {
+			for _, cat := range cats {
+				if cat = strings.TrimSpace(cat); cat != "" {
+					fmt.Fprintf(out, "    <category term=\"%s\" />\n", toXMLString(cat))
+				}
+			}
+		}
+	}
+	for _, enclosure := range enclosures {
+		if enclosure == nil || strings.TrimSpace(enclosure.Url) == "" {
+			continue
+		}
+		fmt.Fprintf(out, "    <link rel=\"enclosure\" href=\"%s\"", toXMLString(strings.TrimSpace(enclosure.Url)))
+		if enclosure.Type != "" {
+			fmt.Fprintf(out, " type=\"%s\"", toXMLString(strings.TrimSpace(enclosure.Type)))
+		}
+		if enclosure.Length != "" {
+			fmt.Fprintf(out, " length=\"%s\"", toXMLString(strings.TrimSpace(enclosure.Length)))
+		}
+		fmt.Fprintf(out, " />\n")
+	}
+	content, _, err := feedContent(description, sourceMarkdown, gen.CMarkFilters)
+	if err != nil {
+		fmt.Fprintf(gen.eout, "error (%s): %s\n", link, err)
+		content = description
+	}
+	if content = strings.TrimSpace(content); content != "" {
+		fmt.Fprintf(out, "    <content type=\"html\">%s</content>\n", toXMLString(content))
+	}
+	return nil
+}
+
+// WriteAtom writes the collection's items as an Atom 1.0 feed, using the
+// same items as WriteRSS.
+func (gen *Generator) WriteAtom(out io.Writer, db *sql.DB, appName string, collection *Collection) error {
+	feedLink := collection.feedLink(gen.BaseURL, ".atom")
+	return gen.WriteCustomAtom(out, db, SQLDisplayItems, feedLink, appName, collection)
+}
+
+// WriteCustomAtom generates an Atom 1.0 feed given one of the SQL
+// statements used by WriteCustomRSS.
+func (gen *Generator) WriteCustomAtom(out io.Writer, db *sql.DB, sqlStmt string, feedLink string, appName string, collection *Collection, args ...any) error {
+	// The feed's updated element comes first but depends on the entries,
+	// they are written to a buffer and the latest date noted.
+	now := gen.buildTime().UTC().Format(time.RFC3339)
+	feedUpdated, latest := "", time.Time{}
+	entries := new(bytes.Buffer)
+	rows, err := db.Query(sqlStmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			link           string
+			title          string
+			description    string
+			authorsSrc     string
+			authors        []*gofeed.Person
+			enclosuresSrc  string
+			enclosures     []*Enclosure
+			guid           string
+			pubDate        string
+			dcExt          string
+			channel        string
+			status         string
+			updated        string
+			label          string
+			postPath       string
+			sourceMarkdown string
+			categories     string
+			fullMarkdown   string
+			sourceOutline  string
+			podcastExt     string
+		)
+		if err := rows.Scan(&link, &title, &description, &authorsSrc,
+			&enclosuresSrc, &guid, &pubDate, &dcExt,
+			&channel, &status, &updated, &label, &postPath, &sourceMarkdown,
+			&categories, &fullMarkdown, &sourceOutline, &podcastExt); err != nil {
+			return err
+		}
+		if authorsSrc != "" {
+			authors = []*gofeed.Person{}
+			if err := json.Unmarshal([]byte(authorsSrc), &authors); err != nil {
+				fmt.Fprintf(gen.eout, "error (%s): %s\n", authorsSrc, err)
+				authors = nil
+			}
+		}
+		if enclosuresSrc != "" {
+			if err := json.Unmarshal([]byte(enclosuresSrc), &enclosures); err != nil {
+				fmt.Fprintf(gen.eout, "error (%s): %s\n", enclosuresSrc, err)
+				enclosures = nil
+			}
+		}
+		// Posts include their Markdown document like they do in RSS, a
+		// podcast episode's enclosure is its media, not the Markdown
+		if postPath != "" && !(collection.Podcast && len(enclosures) > 0) {
+			if fi, err := os.Stat(postPath); err == nil {
+				markdownURL := gen.BaseURL + "/" + postPath
+				found := false
+				for _, enclosure := range enclosures {
+					if enclosure.Url == markdownURL {
+						found = true
+						break
+					}
+				}
+				if !found {
+					enclosures = append(enclosures, &Enclosure{
+						Url:    markdownURL,
+						Length: fmt.Sprintf("%d", fi.Size()),
+						Type:   "text/markdown",
+					})
+				}
+			}
+		}
+		for _, d := range []string{rfc3339Date(updated), rfc3339Date(pubDate)} {
+			if t, err := time.Parse(time.RFC3339, d); err == nil && t.After(latest) {
+				latest, feedUpdated = t, d
+			}
+		}
+		if err := gen.WriteItemAtom(entries, link, title, description, authors,
+			enclosures, guid, pubDate, updated, sourceMarkdown, categories, now); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if feedUpdated == "" {
+		feedUpdated = now
+	}
+
+	title := collection.Title
+	if title == "" {
+		title = collection.baseName()
+	}
+	fmt.Fprintf(out, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns=%q`, atomNamespace)
+	if collection.Language != "" {
+		fmt.Fprintf(out, ` xml:lang="%s"`, toXMLString(collection.Language))
+	}
+	fmt.Fprintf(out, ">\n")
+	fmt.Fprintf(out, "  <id>%s</id>\n", toXMLString(feedLink))
+	fmt.Fprintf(out, "  <title>%s</title>\n", toXMLString(strings.TrimSpace(title)))
+	if collection.Description != "" {
+		fmt.Fprintf(out, "  <subtitle>%s</subtitle>\n", toXMLString(strings.TrimSpace(collection.Description)))
+	}
+	fmt.Fprintf(out, "  <link rel=\"self\" type=\"application/atom+xml\" href=\"%s\" />\n", toXMLString(feedLink))
+	if gen.BaseURL != "" {
+		fmt.Fprintf(out, "  <link rel=\"alternate\" type=\"text/html\" href=\"%s/%s.html\" />\n",
+			toXMLString(gen.BaseURL), toXMLString(collection.baseName()))
+	}
+	fmt.Fprintf(out, "  <updated>%s</updated>\n", feedUpdated)
+	if collection.ManagingEditor != "" {
+		fmt.Fprintf(out, "  <author>\n    <name>%s</name>\n  </author>\n", toXMLString(strings.TrimSpace(collection.ManagingEditor)))
+	}
+	if collection.Copyright != "" {
+		fmt.Fprintf(out, "  <rights>%s</rights>\n", toXMLString(strings.TrimSpace(collection.Copyright)))
+	}
+	fmt.Fprintf(out, "  <generator version=\"%s\">%s</generator>\n", toXMLString(Version), toXMLString(appName))
+	if _, err := entries.WriteTo(out); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "</feed>\n")
+	return nil
+}
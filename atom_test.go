@@ -0,0 +1,189 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed/atom"
+)
+
+func TestWriteCustomAtom(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	_, err := db.Exec(`INSERT INTO items
+		(link, title, description, authors, enclosures, guid, pubDate,
+		 dcExt, channel, status, updated, label, postPath, sourceMarkdown, categories)
+		VALUES (?, ?, ?, ?, ?, ?, ?, '', '', 'published', ?, '', '', ?, ?)`,
+		"https://example.com/episode-1",
+		"Episode 1 & more",
+		"<p>The <em>first</em> episode</p>",
+		`[{"name":"Jane Doe","email":"jane@example.com"}]`,
+		`[{"url":"https://example.com/episode-1.mp3","length":"1234","type":"audio/mpeg"}]`,
+		"episode-1",
+		"2025-09-01 10:30:00",
+		"2025-09-02 08:00:00",
+		"",
+		`["podcast","antenna"]`,
+	)
+	if err != nil {
+		t.Fatalf("insert item: %s", err)
+	}
+
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	col := &Collection{
+		Title:       "Test Feed",
+		Description: "A feed of test items",
+		File:        "test.md",
+		Language:    "en-US",
+		Copyright:   "CC-BY",
+	}
+	var buf bytes.Buffer
+	if err := gen.WriteAtom(&buf, db, "antenna", col); err != nil {
+		t.Fatalf("WriteAtom: %s", err)
+	}
+	feed, err := (&atom.Parser{}).Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Atom feed does not parse: %s\n%s", err, buf.String())
+	}
+	if feed.ID != "https://example.com/test.atom" || feed.Title != "Test Feed" || feed.Updated != "2025-09-02T08:00:00Z" ||
+		feed.Subtitle != "A feed of test items" || feed.Rights != "CC-BY" || feed.Language != "en-US" {
+		t.Errorf("unexpected feed metadata %+v", feed)
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d\n%s", len(feed.Entries), buf.String())
+	}
+	entry := feed.Entries[0]
+	// The guid isn't a URI so the link identifies the entry
+	if entry.ID != "https://example.com/episode-1" || entry.Title != "Episode 1 & more" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Updated != "2025-09-02T08:00:00Z" || entry.Published != "2025-09-01T10:30:00Z" {
+		t.Errorf("expected RFC 3339 dates, got %q and %q", entry.Updated, entry.Published)
+	}
+	if len(entry.Authors) != 1 || entry.Authors[0].Name != "Jane Doe" || entry.Authors[0].Email != "jane@example.com" {
+		t.Errorf("unexpected authors %+v", entry.Authors)
+	}
+	terms := []string{}
+	for _, category := range entry.Categories {
+		terms = append(terms, category.Term)
+	}
+	if strings.Join(terms, ",") != "podcast,antenna" {
+		t.Errorf("expected categories from the item, got %v", terms)
+	}
+	found := false
+	for _, link := range entry.Links {
+		if link.Rel == "enclosure" {
+			found = true
+			if link.Href != "https://example.com/episode-1.mp3" || link.Type != "audio/mpeg" || link.Length != "1234" {
+				t.Errorf("unexpected enclosure %+v", link)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected an enclosure link\n%s", buf.String())
+	}
+	if entry.Content == nil || entry.Content.Type != "html" || entry.Content.Value != "<p>The <em>first</em> episode</p>" {
+		t.Errorf("unexpected content %+v", entry.Content)
+	}
+}
+
+func TestSyndicationFormats(t *testing.T) {
+	col := &Collection{File: "test.md"}
+	if formats, err := col.SyndicationFormats(); err != nil || strings.Join(formats, ",") != "rss,json" {
+		t.Errorf("expected the default formats, got %v, %v", formats, err)
+	}
+	col.Formats = []string{"Atom", "rss", "atom"}
+	if formats, err := col.SyndicationFormats(); err != nil || strings.Join(formats, ",") != "atom,rss" {
+		t.Errorf("expected atom,rss, got %v, %v", formats, err)
+	}
+	col.Formats = []string{"rdf"}
+	if _, err := col.SyndicationFormats(); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestAtomLinksUseBaseName(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	// An ODT collection in a subdirectory is written as htdocs/links.html
+	col := &Collection{File: filepath.Join("feeds", "links.odt")}
+	var buf bytes.Buffer
+	if err := gen.WriteAtom(&buf, db, "antenna", col); err != nil {
+		t.Fatalf("WriteAtom: %s", err)
+	}
+	feed, err := (&atom.Parser{}).Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alternate := ""
+	for _, link := range feed.Links {
+		if link.Rel == "alternate" {
+			alternate = link.Href
+		}
+	}
+	if feed.ID != "https://example.com/links.atom" || alternate != "https://example.com/links.html" || feed.Title != "links" {
+		t.Errorf("expected links named for the collection's base name, got %q, %q, %q", feed.ID, alternate, feed.Title)
+	}
+
+	// The collection's link names the RSS feed, the Atom feed is next to it
+	for link, expected := range map[string]string{
+		"https://feeds.example.org/planet.xml": "https://feeds.example.org/planet.atom",
+		"feeds/planet.xml":                     "https://example.com/feeds/planet.atom",
+	} {
+		buf.Reset()
+		if err := gen.WriteAtom(&buf, db, "antenna", &Collection{File: "planet.md", Link: link}); err != nil {
+			t.Fatalf("WriteAtom: %s", err)
+		}
+		if !strings.Contains(buf.String(), fmt.Sprintf(`<link rel="self" type="application/atom+xml" href="%s" />`, expected)) {
+			t.Errorf("expected the self link %q for link %q\n%s", expected, link, buf.String())
+		}
+	}
+}
+
+func TestAtomPodcastEnclosures(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("episode-1.md", []byte("The first episode\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := newTestItemsDB(t)
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, enclosures, guid, status, postPath)
+		VALUES ('https://example.com/episode-1.html', 'Episode 1', 'The first episode', ?, 'https://example.com/episode-1.html', 'published', 'episode-1.md')`,
+		`[{"url":"https://example.com/episode-1.mp3","length":"1234","type":"audio/mpeg"}]`); err != nil {
+		t.Fatal(err)
+	}
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	// A podcast episode's enclosure is its media, like in RSS
+	for podcast, expected := range map[bool]int{true: 1, false: 2} {
+		var buf bytes.Buffer
+		if err := gen.WriteAtom(&buf, db, "antenna", &Collection{File: "podcast.md", Podcast: podcast}); err != nil {
+			t.Fatalf("WriteAtom: %s", err)
+		}
+		if cnt := strings.Count(buf.String(), `rel="enclosure"`); cnt != expected || strings.Contains(buf.String(), "text/markdown") == podcast {
+			t.Errorf("expected %d enclosures with podcast %t, got %s", expected, podcast, buf.String())
+		}
+	}
+}
@@ -47,6 +47,14 @@ type CommonMark struct {
 	Text string `json:"text,omitempty" yaml:"text,omitempty"`
 	// useMathJax controls if MathJax support is included when rendering HTML
 	useMathJax bool `json:"-"`
+	// cmFilters holds the Lua filters run over the document when rendering HTML
+	cmFilters []string `json:"-"`
+	// headingIDs controls if headings are given ids when rendering HTML
+	headingIDs bool `json:"-"`
+	// headingAnchors controls if headings end with a link to themselves
+	headingAnchors bool `json:"-"`
+	// toc controls if a table of contents is put at the top of the document
+	toc bool `json:"-"`
 }
 
 
@@ -134,6 +142,30 @@ func (doc *CommonMark) UseMathJax(value bool) {
 	doc.useMathJax = value
 }
 
+// UseFilters sets the Lua filters, a generator's cm_filters, run over the
+// document when converting to HTML
+func (doc *CommonMark) UseFilters(filters []string) {
+	doc.cmFilters = filters
+}
+
+// UseHeadingIDs will set whether or not headings are given ids made
+// from their text when converting to HTML
+func (doc *CommonMark) UseHeadingIDs(value bool) {
+	doc.headingIDs = value
+}
+
+// UseHeadingAnchors will set whether or not headings end with an anchor
+// linking to themselves when converting to HTML, it implies heading ids
+func (doc *CommonMark) UseHeadingAnchors(value bool) {
+	doc.headingAnchors = value
+}
+
+// UseTOC will set whether or not a table of contents is put at the top of
+// the document when converting to HTML, it implies heading ids
+func (doc *CommonMark) UseTOC(value bool) {
+	doc.toc = value
+}
+
 // Parse will read a byte slice and populate any FrontMatter found
 // and set the remaining text as the Text element of CommonMark structure.
 func (doc *CommonMark) Parse(src []byte) error {
@@ -334,17 +366,21 @@ func (doc *CommonMark) ToHTML() (string, error) {
 			figure.Figure.WithSkipNoCaption(),
 			extension.CJK,
 			&fences.Extender{},
+			&highlighting{},
 	}
 	if doc.useMathJax {
 			extenders = append(extenders, mathjax.MathJax)
 	}
+	if doc.headingIDs || doc.headingAnchors || doc.toc {
+			extenders = append(extenders, &headings{anchors: doc.headingAnchors, toc: doc.toc})
+	}
 	md := goldmark.New(
 		goldmark.WithExtensions(
 			extenders...
 		),
 	)
 	var buf bytes.Buffer
-	if err := md.Convert([]byte(doc.Text), &buf); err != nil {
+	if err := convertMarkdown(md, []byte(doc.Text), doc.cmFilters, &buf); err != nil {
 		return "", err
 	}
 	return buf.String(), nil
@@ -361,10 +397,14 @@ func (doc *CommonMark) ToUnsafeHTML() (string, error) {
 			figure.Figure.WithSkipNoCaption(),
 			extension.CJK,
 			&fences.Extender{},
+			&highlighting{},
 	}
 	if doc.useMathJax {
 			extenders = append(extenders, mathjax.MathJax)
 	}
+	if doc.headingIDs || doc.headingAnchors || doc.toc {
+			extenders = append(extenders, &headings{anchors: doc.headingAnchors, toc: doc.toc})
+	}
 	md := goldmark.New(
 		goldmark.WithExtensions(
 			extenders...
@@ -374,7 +414,7 @@ func (doc *CommonMark) ToUnsafeHTML() (string, error) {
 		),
 	)
 	var buf bytes.Buffer
-	if err := md.Convert([]byte(doc.Text), &buf); err != nil {
+	if err := convertMarkdown(md, []byte(doc.Text), doc.cmFilters, &buf); err != nil {
 		return "", err
 	}
 	return buf.String(), nil
@@ -461,27 +461,53 @@ func (cfg *AppConfig) GenerateCSS(out io.Writer, cssPath string) error {
 }
 
 /** GenerateCSS implements the "css" action. Writes a default CSS file to the
- * htdocs tree and patches the configured generator YAML.
+ * htdocs tree and patches the configured generator YAML. With --highlight it
+ * writes the syntax highlighting stylesheet instead, --highlight=STYLE picks
+ * the chroma style.
  *
  * Parameters:
  *   out     (io.Writer) — progress messages
  *   cfgName (string)    — path to antenna.yaml
- *   args    ([]string)  — optional: [css-output-path] (default: css/site.css)
+ *   args    ([]string)  — optional: [--highlight[=STYLE]] [css-output-path]
+ *                         (default: css/site.css, css/highlight.css)
  *
  * Returns:
  *   error — non-nil on configuration or I/O failure
  *
  * Example:
  *   err := app.GenerateCSS(os.Stdout, "antenna.yaml", nil)
+ *   err := app.GenerateCSS(os.Stdout, "antenna.yaml", []string{"--highlight"})
  */
 func (app *AntennaApp) GenerateCSS(out io.Writer, cfgName string, args []string) error {
 	cfg := &AppConfig{}
 	if err := cfg.LoadConfig(cfgName); err != nil {
 		return err
 	}
+	highlight, style := false, ""
+	paths := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			paths = append(paths, arg)
+			continue
+		}
+		name, value, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch name {
+		case "highlight":
+			highlight, style = true, value
+		default:
+			return fmt.Errorf("unknown css option %q", arg)
+		}
+	}
+	if highlight {
+		cssPath := "css/highlight.css"
+		if len(paths) > 0 {
+			cssPath = strings.TrimSpace(paths[0])
+		}
+		return cfg.GenerateHighlightCSS(out, cssPath, style)
+	}
 	cssPath := "css/site.css"
-	if len(args) > 0 {
-		cssPath = strings.TrimSpace(args[0])
+	if len(paths) > 0 {
+		cssPath = strings.TrimSpace(paths[0])
 	}
 	return cfg.GenerateCSS(out, cssPath)
 }
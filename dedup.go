@@ -0,0 +1,262 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"net/url"
+	"regexp"
+	"strings"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+)
+
+// trackingParams are query parameters added for click tracking. Any
+// parameter starting with "utm_" is also removed.
+var trackingParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+	"mc_cid": true,
+	"mc_eid": true,
+}
+
+// canonicalURL normalizes an item link so the same story syndicated
+// through several feeds is saved once. The scheme and host are lower
+// cased, default ports dropped and tracking parameters removed. The rest
+// of the link is kept as written, the other parameters in their order and
+// encoding. Links that don't parse as absolute URLs are returned
+// unchanged.
+func canonicalURL(href string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return href
+	}
+	_, rest, ok := strings.Cut(strings.TrimSpace(href), "://")
+	if !ok {
+		return href
+	}
+	authority, path := rest, ""
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	userinfo := ""
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo = authority[:i+1]
+	}
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	if port := u.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return scheme + "://" + userinfo + host + stripTrackingParams(path)
+}
+
+// stripTrackingParams removes the tracking parameters from the query of
+// a link's path, the rest is copied as written.
+func stripTrackingParams(path string) string {
+	base, fragment, hasFragment := strings.Cut(path, "#")
+	base, query, hasQuery := strings.Cut(base, "?")
+	if !hasQuery {
+		return path
+	}
+	kept := []string{}
+	for _, param := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "utm_") || trackingParams[key] {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	if len(kept) > 0 {
+		base += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
+
+// canonicalizeItemLinks saves the harvested items under their canonical
+// link, see canonicalURL. Items saved by older versions of antenna used
+// the link as the feed wrote it. An item whose canonical link is already
+// saved is left as is. Posted items keep the link formed from base_url.
+func canonicalizeItemLinks(db *sql.DB) error {
+	rows, err := db.Query(SQLHarvestedItemLinks)
+	if err != nil {
+		return err
+	}
+	links := []string{}
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			rows.Close()
+			return err
+		}
+		if canonicalURL(link) != link {
+			links = append(links, link)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if _, err := db.Exec(SQLUpdateItemLink, canonicalURL(link), link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemCanonicalLink returns the <link rel="canonical"> of an item when the
+// feed provides one, e.g. an atom:link in an RSS item. An empty string is
+// returned otherwise.
+func itemCanonicalLink(item *gofeed.Item) string {
+	for _, elements := range item.Extensions {
+		for _, link := range elements["link"] {
+			if strings.EqualFold(link.Attrs["rel"], "canonical") && link.Attrs["href"] != "" {
+				return link.Attrs["href"]
+			}
+		}
+	}
+	return ""
+}
+
+// itemLink returns the canonical link for a harvested item. A permalink
+// GUID stands in for a missing link.
+func itemLink(item *gofeed.Item) string {
+	if link := itemCanonicalLink(item); link != "" {
+		return canonicalURL(link)
+	}
+	link := item.Link
+	if link == "" && (strings.HasPrefix(item.GUID, "http://") || strings.HasPrefix(item.GUID, "https://")) {
+		link = item.GUID
+	}
+	return canonicalURL(link)
+}
+
+var (
+	markupRE  = regexp.MustCompile(`<[^>]*>`)
+	nonWordRE = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// textWords returns the lower cased words of a title or description with
+// any markup removed.
+func textWords(s string) []string {
+	s = markupRE.ReplaceAllString(s, " ")
+	return strings.Fields(nonWordRE.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+// titleKey normalizes a title for near-duplicate lookups
+func titleKey(title string) string {
+	return strings.Join(textWords(title), " ")
+}
+
+// nearDuplicateThreshold is the share of words two descriptions must have
+// in common to be treated as the same story.
+const nearDuplicateThreshold = 0.6
+
+// similarText returns the Jaccard similarity of the words in a and b.
+// Two empty texts are treated as the same.
+func similarText(a string, b string) float64 {
+	wordsA, wordsB := map[string]bool{}, map[string]bool{}
+	for _, word := range textWords(a) {
+		wordsA[word] = true
+	}
+	for _, word := range textWords(b) {
+		wordsB[word] = true
+	}
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// mergeLabels joins source labels into the comma separated list saved
+// in an item's label column, leaving out repeats.
+func mergeLabels(labels ...string) string {
+	seen := map[string]bool{}
+	merged := []string{}
+	for _, label := range labels {
+		for _, part := range strings.Split(label, ",") {
+			if part = strings.TrimSpace(part); part != "" && !seen[part] {
+				seen[part] = true
+				merged = append(merged, part)
+			}
+		}
+	}
+	return strings.Join(merged, ", ")
+}
+
+// findNearDuplicate looks for an item saved under another link with the
+// same normalized title and a similar description. It returns the link
+// and label of the match.
+func findNearDuplicate(db *sql.DB, link string, title string, description string) (string, string, bool, error) {
+	key := titleKey(title)
+	if key == "" {
+		return "", "", false, nil
+	}
+	rows, err := db.Query(SQLNearDuplicates, key, link)
+	if err != nil {
+		return "", "", false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var otherLink, otherLabel, otherDescription string
+		if err := rows.Scan(&otherLink, &otherLabel, &otherDescription); err != nil {
+			return "", "", false, err
+		}
+		if similarText(description, otherDescription) >= nearDuplicateThreshold {
+			return otherLink, otherLabel, true, nil
+		}
+	}
+	return "", "", false, rows.Err()
+}
+
+// collapseDuplicate checks if item has already been saved, under its own
+// link or as a near-duplicate under another link. A near-duplicate gets
+// label added to the saved item's labels and true is returned so the
+// item isn't saved again. Otherwise the label to save the item with is
+// returned, merged with any labels already saved for its link.
+func collapseDuplicate(db *sql.DB, label string, item *gofeed.Item) (string, bool, error) {
+	var savedLabel string
+	err := db.QueryRow(SQLItemLabel, item.Link).Scan(&savedLabel)
+	if err == nil {
+		return mergeLabels(savedLabel, label), false, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", false, err
+	}
+	otherLink, otherLabel, found, err := findNearDuplicate(db, item.Link, item.Title, item.Description)
+	if err != nil || !found {
+		return label, false, err
+	}
+	if _, err := db.Exec(SQLUpdateItemLabel, mergeLabels(otherLabel, label), otherLink); err != nil {
+		return "", false, err
+	}
+	return label, true, nil
+}
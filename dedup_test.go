@@ -0,0 +1,195 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	for href, expected := range map[string]string{
+		"https://example.org/story?utm_source=rss&utm_medium=feed": "https://example.org/story",
+		"http://example.org?id=2&fbclid=abc":                       "http://example.org?id=2",
+		"https://example.org/story?b=2&UTM_Term=x&a=1#part-2":      "https://example.org/story?b=2&a=1#part-2",
+		"https://Example.org:443/story?q=a+b&x=%2F":                "https://example.org/story?q=a+b&x=%2F",
+		"https://example.org/story?utm_source=rss#top":             "https://example.org/story#top",
+		"HTTP://Example.COM:80/Story/Index.html":                   "http://example.com/Story/Index.html",
+		"HTTPS://Example.com:443":                                  "https://example.com",
+		"https://example.org:8443/story":                           "https://example.org:8443/story",
+		"http://example.org:443/story":                             "http://example.org:443/story",
+		"https://Reader@Example.org/story#a?utm_source=rss":        "https://Reader@example.org/story#a?utm_source=rss",
+		"feeds/digest.xml":                                         "feeds/digest.xml",
+	} {
+		if got := canonicalURL(href); got != expected {
+			t.Errorf("canonicalURL(%q) expected %q, got %q", href, expected, got)
+		}
+	}
+	item := &gofeed.Item{
+		Link: "https://mirror.example.net/story?utm_campaign=x",
+		Extensions: ext.Extensions{"atom": {"link": []ext.Extension{
+			{Name: "link", Attrs: map[string]string{"rel": "canonical", "href": "https://Example.org/story"}},
+		}}},
+	}
+	if got := itemLink(item); got != "https://example.org/story" {
+		t.Errorf("expected the canonical link to be used, got %q", got)
+	}
+}
+
+func TestHarvestCollapsesNearDuplicates(t *testing.T) {
+	const feedTmpl = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>%s</title>
+    <link>https://example.org/</link>
+    <description>A test feed</description>
+    <item>
+      <title>Big News: Antenna Released!</title>
+      <link>%s</link>
+      <description>%s</description>
+    </item>
+  </channel>
+</rss>`
+	feeds := map[string]string{
+		"/a.xml": fmt.Sprintf(feedTmpl, "Feed A", "https://example.org/news?utm_source=a", "Antenna, the link blog tool, has been released today."),
+		"/b.xml": fmt.Sprintf(feedTmpl, "Feed B", "https://EXAMPLE.org/news?utm_source=b", "Antenna, the link blog tool, has been released today."),
+		"/c.xml": fmt.Sprintf(feedTmpl, "Feed C", "https://planet.example.net/1234", "<p>Antenna the link blog tool has been released today!</p>"),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feeds[r.URL.Path]))
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "planet.md")
+	src := fmt.Sprintf("# Planet\n\n- [Feed A](%s/a.xml)\n- [Feed B](%s/b.xml)\n- [Feed C](%s/c.xml)\n", ts.URL, ts.URL, ts.URL)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{
+		File:           cName,
+		DbName:         filepath.Join(dName, "planet.db"),
+		Concurrency:    1,
+		NearDuplicates: true,
+	}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+			t.Fatalf("Harvest: %s", err)
+		}
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	count := 0
+	if err := db.QueryRow(SQLItemCount).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected the story to be saved once, got %d items", count)
+	}
+	var link, label string
+	if err := db.QueryRow(`SELECT link, label FROM items`).Scan(&link, &label); err != nil {
+		t.Fatal(err)
+	}
+	if link != "https://example.org/news" || label != "Feed A, Feed B, Feed C" {
+		t.Errorf("expected one canonical item listing every source, got %q, %q", link, label)
+	}
+}
+
+func TestHarvestKeepsSavedLinks(t *testing.T) {
+	const link = "HTTPS://Example.org:443/news?b=2&a=1%2F"
+	feed := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Feed A</title>
+    <link>https://example.org/</link>
+    <description>A test feed</description>
+    <item>
+      <title>News</title>
+      <link>%s</link>
+      <description>The news.</description>
+    </item>
+  </channel>
+</rss>`, html.EscapeString(link))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feed))
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "planet.md")
+	if err := os.WriteFile(cName, []byte(fmt.Sprintf("# Planet\n\n- [Feed A](%s/a.xml)\n", ts.URL)), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "planet.db"), Concurrency: 1}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// The item as an earlier harvest saved it, the link as the feed wrote
+	// it, and a post whose link is formed from base_url
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, status, label) VALUES (?, 'News', 'The news.', 'published', 'Feed A')`, link); err != nil {
+		t.Fatal(err)
+	}
+	const post = "https://Example.org/blog/post.html"
+	if _, err := db.Exec(`INSERT INTO items (link, title, postPath, status) VALUES (?, 'Post', 'blog/post.md', 'published')`, post); err != nil {
+		t.Fatal(err)
+	}
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	rows, err := db.Query(`SELECT link FROM items ORDER BY link`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	links := []string{}
+	for rows.Next() {
+		var l string
+		if err := rows.Scan(&l); err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, l)
+	}
+	// The saved item is moved to the normalized link, keeping the query
+	// as written, rather than duplicated
+	if len(links) != 2 || links[0] != post || links[1] != "https://example.org/news?b=2&a=1%2F" {
+		t.Errorf("expected the saved item to be updated, not duplicated, got %q", links)
+	}
+}
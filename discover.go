@@ -0,0 +1,254 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+)
+
+// feedLinkTypes are the <link rel="alternate"> types treated as feeds
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// discoveredFeed is a feed advertised by an HTML page
+type discoveredFeed struct {
+	// URL holds the absolute URL of the feed
+	URL string
+	// Title holds the title attribute of the link element
+	Title string
+}
+
+// isHTMLDocument reports if a response holds an HTML page rather than a
+// feed. Some servers send feeds as text/html so the document is checked
+// for a feed first, then the Content-Type and finally the start of the
+// document for servers that send a generic type.
+func isHTMLDocument(contentType string, src []byte) bool {
+	if gofeed.DetectFeedType(bytes.NewReader(src)) != gofeed.FeedTypeUnknown {
+		return false
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			return true
+		}
+	}
+	start := bytes.ToLower(bytes.TrimSpace(src))
+	if len(start) > 512 {
+		start = start[:512]
+	}
+	return bytes.HasPrefix(start, []byte("<!doctype html")) || bytes.Contains(start, []byte("<html"))
+}
+
+// discoverFeedLinks returns the feeds linked from an HTML page with
+// <link rel="alternate"> elements, in document order. Relative links are
+// resolved against base.
+func discoverFeedLinks(src []byte, base *url.URL) []discoveredFeed {
+	feeds := []discoveredFeed{}
+	seen := map[string]bool{}
+	z := html.NewTokenizer(bytes.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return feeds
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "link" {
+			continue
+		}
+		var rel, linkType, href, title string
+		for _, attr := range tok.Attr {
+			switch strings.ToLower(attr.Key) {
+			case "rel":
+				rel = strings.ToLower(attr.Val)
+			case "type":
+				linkType = strings.ToLower(strings.TrimSpace(attr.Val))
+			case "href":
+				href = strings.TrimSpace(attr.Val)
+			case "title":
+				title = strings.TrimSpace(attr.Val)
+			}
+		}
+		if href == "" || !feedLinkTypes[linkType] || !containsField(rel, "alternate") {
+			continue
+		}
+		u, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		if seen[u.String()] {
+			continue
+		}
+		seen[u.String()] = true
+		feeds = append(feeds, discoveredFeed{URL: u.String(), Title: title})
+	}
+}
+
+// containsField reports if the space separated list s contains val
+func containsField(s string, val string) bool {
+	for _, field := range strings.Fields(s) {
+		if field == val {
+			return true
+		}
+	}
+	return false
+}
+
+// webgetDiscovered tries the feeds discovered in the HTML page at href in
+// order and returns the first one that can be retrieved. The cache
+// headers saved for href are only sent to the feed they came from, the
+// cache's DiscoveredURL. The feed's cache headers are returned along with
+// its URL, the harvest saves them for href.
+func webgetDiscovered(client *http.Client, userAgent string, href string, cache feedCache, feeds []discoveredFeed) (*feedResponse, error) {
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("%q is an HTML page without feed links", href)
+	}
+	errList := []string{}
+	for _, feed := range feeds {
+		validators := feedCache{}
+		if feed.URL == cache.DiscoveredURL {
+			validators = cache
+		}
+		res, err := webgetFeed(client, userAgent, feed.URL, validators, false)
+		if err != nil {
+			errList = append(errList, fmt.Sprintf("%s: %s", feed.URL, err))
+			continue
+		}
+		res.DiscoveredURL = feed.URL
+		return res, nil
+	}
+	return nil, fmt.Errorf("none of the feeds linked from %q could be retrieved, %s", href, strings.Join(errList, "; "))
+}
+
+// markdownLinkLine renders a feed as a Markdown list item in the form
+// ParseMarkdownLinks expects, `- [Label](url "description")`.
+func markdownLinkLine(label string, href string, description string) string {
+	label = strings.NewReplacer("[", "(", "]", ")").Replace(strings.Join(strings.Fields(label), " "))
+	if label == "" {
+		label = href
+	}
+	description = strings.ReplaceAll(strings.Join(strings.Fields(description), " "), `"`, "'")
+	if description == "" {
+		return fmt.Sprintf("- [%s](%s)", label, href)
+	}
+	return fmt.Sprintf(`- [%s](%s "%s")`, label, href, description)
+}
+
+/** Discover writes a Markdown list line for each feed found at a URL.
+ * The URL may be a feed or an HTML page with <link rel="alternate"> feed
+ * links. The lines can be pasted into a collection's Markdown file.
+ *
+ * Parameters:
+ *   out     (io.Writer) — destination for the Markdown list
+ *   eout    (io.Writer) — destination for warnings
+ *   cfgName (string)    — path to antenna.yaml, used for the user_agent if present
+ *   args    ([]string)  — [URL]
+ *
+ * Returns:
+ *   error — non-nil if the URL can't be retrieved or has no feeds
+ *
+ * Example:
+ *   err := app.Discover(os.Stdout, os.Stderr, "antenna.yaml", []string{"https://example.org"})
+ */
+func (app *AntennaApp) Discover(out io.Writer, eout io.Writer, cfgName string, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("expected a URL")
+	}
+	href := strings.TrimSpace(args[0])
+	cfg := &AppConfig{}
+	if _, err := os.Stat(cfgName); err == nil {
+		if err := cfg.LoadConfig(cfgName); err != nil {
+			return err
+		}
+	}
+	timeout := time.Duration(defaultHarvestTimeout) * time.Second
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	client := &http.Client{
+		CheckRedirect: redirectHandler,
+		Timeout:       timeout,
+	}
+	return discoverFeeds(out, eout, client, cfg.UserAgent, href)
+}
+
+// discoverFeeds does the work of Discover using client.
+func discoverFeeds(out io.Writer, eout io.Writer, client *http.Client, userAgent string, href string) error {
+	req, err := newFeedRequest(userAgent, href, "", "")
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &statusError{StatusCode: res.StatusCode, Status: res.Status}
+	}
+	src, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if !isHTMLDocument(res.Header.Get("Content-Type"), src) {
+		// href is the feed, use the response we already have
+		feed, _, err := parseFeedSource(href, src)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, markdownLinkLine(feed.Title, res.Request.URL.String(), feed.Description))
+		return nil
+	}
+	cnt := 0
+	for _, feed := range discoverFeedLinks(src, res.Request.URL) {
+		feedRes, err := webgetFeed(client, userAgent, feed.URL, feedCache{}, false)
+		if err != nil {
+			fmt.Fprintf(eout, "warning (%s): %s\n", feed.URL, err)
+			continue
+		}
+		label := feedRes.Feed.Title
+		if label == "" {
+			label = feed.Title
+		}
+		fmt.Fprintln(out, markdownLinkLine(label, feed.URL, feedRes.Feed.Description))
+		cnt++
+	}
+	if cnt == 0 {
+		return fmt.Errorf("no feeds found at %s", href)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// dublinCoreNamespace is the XML namespace of the Dublin Core elements
+const dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+
+// frontMatterDublinCore maps a post's front matter to the Dublin Core
+// extension. Each Dublin Core element is read from the front matter key
+// of the same name, as a string or a list. Title and description are
+// already part of the item and aren't repeated. creator falls back to the
+// post's authors and rights to its copyright.
+func frontMatterDublinCore(doc *CommonMark, authors []*gofeed.Person) *ext.DublinCoreExtension {
+	dc := &ext.DublinCoreExtension{
+		Creator:     doc.GetAttributeStringSlice("creator"),
+		Subject:     doc.GetAttributeStringSlice("subject"),
+		Publisher:   doc.GetAttributeStringSlice("publisher"),
+		Contributor: doc.GetAttributeStringSlice("contributor"),
+		Date:        doc.GetAttributeStringSlice("date"),
+		Type:        doc.GetAttributeStringSlice("type"),
+		Format:      doc.GetAttributeStringSlice("format"),
+		Identifier:  doc.GetAttributeStringSlice("identifier"),
+		Source:      doc.GetAttributeStringSlice("source"),
+		Language:    doc.GetAttributeStringSlice("language"),
+		Relation:    doc.GetAttributeStringSlice("relation"),
+		Coverage:    doc.GetAttributeStringSlice("coverage"),
+		Rights:      doc.GetAttributeStringSlice("rights"),
+	}
+	if len(dc.Creator) == 0 {
+		for _, author := range authors {
+			if author != nil && author.Name != "" {
+				dc.Creator = append(dc.Creator, author.Name)
+			}
+		}
+	}
+	if len(dc.Rights) == 0 {
+		dc.Rights = doc.GetAttributeStringSlice("copyright")
+	}
+	return dc
+}
+
+// writeDublinCoreRSS writes the Dublin Core extension saved as JSON in
+// the dcExt column as dc: elements of an RSS item.
+func writeDublinCoreRSS(out io.Writer, src string) error {
+	dc := &ext.DublinCoreExtension{}
+	if err := json.Unmarshal([]byte(src), dc); err != nil {
+		return err
+	}
+	for _, element := range []struct {
+		name   string
+		values []string
+	}{
+		{"title", dc.Title},
+		{"creator", dc.Creator},
+		{"subject", dc.Subject},
+		{"description", dc.Description},
+		{"publisher", dc.Publisher},
+		{"contributor", dc.Contributor},
+		{"date", dc.Date},
+		{"type", dc.Type},
+		{"format", dc.Format},
+		{"identifier", dc.Identifier},
+		{"source", dc.Source},
+		{"language", dc.Language},
+		{"relation", dc.Relation},
+		{"coverage", dc.Coverage},
+		{"rights", dc.Rights},
+	} {
+		for _, value := range element.values {
+			if value = strings.TrimSpace(value); value != "" {
+				fmt.Fprintf(out, "      <dc:%s>%s</dc:%s>\n", element.name, toXMLString(value), element.name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+func TestFrontMatterDublinCore(t *testing.T) {
+	doc := &CommonMark{}
+	if err := doc.Parse([]byte(`---
+title: A post
+author: Jane Doe
+subject: [Oberon, programming]
+publisher: Example Press
+coverage: California
+copyright: CC-BY 4.0
+---
+
+Hello.
+`)); err != nil {
+		t.Fatal(err)
+	}
+	authors, err := doc.GetPersons("author", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := frontMatterDublinCore(doc, authors)
+	if strings.Join(dc.Subject, ",") != "Oberon,programming" || strings.Join(dc.Publisher, ",") != "Example Press" ||
+		strings.Join(dc.Coverage, ",") != "California" {
+		t.Errorf("unexpected Dublin Core %+v", dc)
+	}
+	if strings.Join(dc.Creator, ",") != "Jane Doe" || strings.Join(dc.Rights, ",") != "CC-BY 4.0" {
+		t.Errorf("expected creator and rights from author and copyright, got %+v", dc)
+	}
+	if len(dc.Title) != 0 {
+		t.Errorf("expected the title left out, got %v", dc.Title)
+	}
+
+	// An explicit creator and rights take precedence
+	doc.FrontMatter["creator"] = "J. Doe"
+	doc.FrontMatter["rights"] = "All rights reserved"
+	dc = frontMatterDublinCore(doc, authors)
+	if strings.Join(dc.Creator, ",") != "J. Doe" || strings.Join(dc.Rights, ",") != "All rights reserved" {
+		t.Errorf("expected the explicit creator and rights, got %+v", dc)
+	}
+}
+
+func TestPostODTDublinCore(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, src := range map[string]string{
+		"page.yaml": DefaultGeneratorYaml,
+		"posts.md":  "# Posts\n",
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join("htdocs", "blog"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	metaXML := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta
+  xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  office:version="1.3">
+  <office:meta>
+    <dc:title>A Test Article</dc:title>
+    <dc:description>A short description of the article.</dc:description>
+    <dc:creator>Jane Doe</dc:creator>
+    <dc:subject>Software documentation</dc:subject>
+    <dc:language>en-US</dc:language>
+    <dc:rights>Licensed under AGPL-3.0-or-later</dc:rights>
+    <meta:user-defined meta:name="postPath">blog/a-test-article.md</meta:user-defined>
+  </office:meta>
+</office:document-meta>`)
+	if err := makeODTWithMetaAndContent("article.odt", metaXML, collectionContentXML); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: "posts.md", DbName: "posts.db", Generator: "page.yaml"}
+	if err := setupDatabase(col.File, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &AppConfig{BaseURL: "https://example.com", Htdocs: "htdocs", Collections: []*Collection{col}}
+	if err := cfg.Post(col.File, "article.odt"); err != nil {
+		t.Fatalf("Post: %s", err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var src string
+	if err := db.QueryRow(`SELECT dcExt FROM items`).Scan(&src); err != nil {
+		t.Fatal(err)
+	}
+	dc := ext.DublinCoreExtension{}
+	if err := json.Unmarshal([]byte(src), &dc); err != nil {
+		t.Fatal(err)
+	}
+	// The document properties reach Dublin Core through the front matter,
+	// title and description stay with the item like a Markdown post
+	if len(dc.Title) > 0 || len(dc.Description) > 0 {
+		t.Errorf("expected no dc title or description, got %+v", dc)
+	}
+	if strings.Join(dc.Creator, ",") != "Jane Doe" || strings.Join(dc.Subject, ",") != "Software documentation" ||
+		strings.Join(dc.Rights, ",") != "Licensed under AGPL-3.0-or-later" || strings.Join(dc.Language, ",") != "en-US" {
+		t.Errorf("unexpected Dublin Core %+v", dc)
+	}
+}
+
+func TestWriteDublinCoreRSS(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	doc := &CommonMark{FrontMatter: map[string]interface{}{
+		"subject":   []interface{}{"Oberon", "programming"},
+		"publisher": "Example Press & Co",
+		"coverage":  "California",
+		"rights":    "CC-BY 4.0",
+		"creator":   "Jane Doe",
+	}}
+	dcExt, err := json.Marshal(frontMatterDublinCore(doc, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, guid, pubDate, dcExt, status)
+		VALUES ('https://example.com/post', 'Post', 'A post', 'https://example.com/post', '2025-09-01', ?, 'published')`,
+		string(dcExt)); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	gen := &Generator{eout: io.Discard}
+	col := &Collection{Title: "Posts", Description: "Posts with Dublin Core", File: "posts.md"}
+	if err := gen.WriteCustomRSS(buf, db, SQLDisplayItems, "https://example.com/posts.xml", "antenna", col); err != nil {
+		t.Fatal(err)
+	}
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, buf.String())
+	}
+	if len(feed.Items) != 1 || feed.Items[0].DublinCoreExt == nil {
+		t.Fatalf("expected an item with Dublin Core\n%s", buf.String())
+	}
+	dc := feed.Items[0].DublinCoreExt
+	if strings.Join(dc.Subject, ",") != "Oberon,programming" || strings.Join(dc.Publisher, ",") != "Example Press & Co" ||
+		strings.Join(dc.Coverage, ",") != "California" || strings.Join(dc.Rights, ",") != "CC-BY 4.0" ||
+		strings.Join(dc.Creator, ",") != "Jane Doe" {
+		t.Errorf("unexpected Dublin Core %+v\n%s", dc, buf.String())
+	}
+}
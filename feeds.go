@@ -0,0 +1,158 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FeedHealth holds the outcome of the most recent harvests of a feed.
+type FeedHealth struct {
+	// Link holds the feed as listed in the collection
+	Link Link `json:"link,omitempty" yaml:"link,omitempty"`
+	// LastAttempt holds the time the feed was last harvested
+	LastAttempt string `json:"last_attempt,omitempty" yaml:"last_attempt,omitempty"`
+	// LastSuccess holds the time the feed was last harvested without error
+	LastSuccess string `json:"last_success,omitempty" yaml:"last_success,omitempty"`
+	// StatusCode holds the HTTP status of the last attempt
+	StatusCode int `json:"status_code,omitempty" yaml:"status_code,omitempty"`
+	// Error holds the error text of the last attempt, empty on success
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Failures holds the number of consecutive failed attempts
+	Failures int `json:"failures,omitempty" yaml:"failures,omitempty"`
+	// ItemCount holds the number of items saved by the last attempt
+	ItemCount int `json:"item_count,omitempty" yaml:"item_count,omitempty"`
+	// MovedTo holds the URL the feed permanently redirected to, if any
+	MovedTo string `json:"moved_to,omitempty" yaml:"moved_to,omitempty"`
+}
+
+// String renders the feed health as a Markdown list item.
+func (health *FeedHealth) String() string {
+	label := health.Link.Label
+	if label == "" {
+		label = health.Link.URL
+	}
+	if health.LastAttempt == "" {
+		return fmt.Sprintf("- [%s](%s), never harvested", label, health.Link.URL)
+	}
+	parts := []string{
+		fmt.Sprintf("- [%s](%s)", label, health.Link.URL),
+		fmt.Sprintf("status %d", health.StatusCode),
+		fmt.Sprintf("last attempt %s", health.LastAttempt),
+	}
+	if health.LastSuccess != "" {
+		parts = append(parts, fmt.Sprintf("last success %s", health.LastSuccess))
+	} else {
+		parts = append(parts, "never succeeded")
+	}
+	parts = append(parts, fmt.Sprintf("failures %d", health.Failures), fmt.Sprintf("items %d", health.ItemCount))
+	txt := strings.Join(parts, ", ")
+	if health.Error != "" {
+		txt += fmt.Sprintf("\n  - error: %s", strings.ReplaceAll(health.Error, "\n", " "))
+	}
+	if health.MovedTo != "" {
+		txt += fmt.Sprintf("\n  - moved to: %s", health.MovedTo)
+	}
+	return txt
+}
+
+// feedHealthFromDB looks up the harvest health for each link in db.
+func feedHealthFromDB(db *sql.DB, links []Link) ([]*FeedHealth, error) {
+	healthList := []*FeedHealth{}
+	for _, link := range links {
+		health := &FeedHealth{Link: link}
+		err := db.QueryRow(SQLFeedHealth, link.URL).Scan(&health.LastAttempt, &health.LastSuccess,
+			&health.StatusCode, &health.Error, &health.Failures, &health.ItemCount, &health.MovedTo)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		healthList = append(healthList, health)
+	}
+	return healthList, nil
+}
+
+/** Feeds writes the harvest health of each feed listed in a collection to out.
+ *
+ * Parameters:
+ *   out   (io.Writer) — destination for the listing
+ *   cName (string)    — collection filename (e.g. "pacific.md")
+ *
+ * Returns:
+ *   error — non-nil if the collection is unknown or the query fails
+ *
+ * Example:
+ *   err := cfg.Feeds(os.Stdout, "pacific.md")
+ */
+func (cfg *AppConfig) Feeds(out io.Writer, cName string) error {
+	collection, err := cfg.GetCollection(cName)
+	if err != nil {
+		return fmt.Errorf("%s, %s", cName, err)
+	}
+	doc, err := LoadCommonMark(collection.File)
+	if err != nil {
+		return err
+	}
+	links, err := doc.GetLinks()
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return fmt.Errorf("no feeds found in %s", collection.File)
+	}
+	db, err := openCollectionDB(collection.DbName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	healthList, err := feedHealthFromDB(db, links)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "")
+	for _, health := range healthList {
+		fmt.Fprintln(out, health.String())
+	}
+	fmt.Fprintln(out, "")
+	return nil
+}
+
+/** Feeds lists the feeds in a collection along with their harvest health.
+ *
+ * Parameters:
+ *   out     (io.Writer) — destination for the listing
+ *   cfgName (string)    — path to antenna.yaml
+ *   args    ([]string)  — [collection-filename]
+ *
+ * Returns:
+ *   error — non-nil on configuration or query failure
+ *
+ * Example:
+ *   err := app.Feeds(os.Stdout, "antenna.yaml", []string{"pacific.md"})
+ */
+func (app *AntennaApp) Feeds(out io.Writer, cfgName string, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("expected a collection name")
+	}
+	cfg := &AppConfig{}
+	if err := cfg.LoadConfig(cfgName); err != nil {
+		return err
+	}
+	return cfg.Feeds(out, strings.TrimSpace(args[0]))
+}
@@ -0,0 +1,104 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// feedFetcher retrieves and parses the feed at href. Each URL scheme a
+// collection can list has its own fetcher, see feedFetchers.
+type feedFetcher interface {
+	fetchFeed(href string, cache feedCache) (*feedResponse, error)
+}
+
+// feedFetchers maps URL schemes to the fetcher that handles them.
+type feedFetchers map[string]feedFetcher
+
+// newFeedFetchers returns the fetchers used to harvest a collection.
+// Gemini certificates are checked against pins and relative paths are
+// resolved from baseDir.
+func newFeedFetchers(userAgent string, timeout time.Duration, pins *tofuPins, baseDir string) feedFetchers {
+	web := &httpFetcher{
+		client: &http.Client{
+			CheckRedirect: redirectHandler,
+			Timeout:       timeout,
+		},
+		userAgent: userAgent,
+	}
+	return feedFetchers{
+		"http":   web,
+		"https":  web,
+		"gemini": &geminiFetcher{timeout: timeout, pins: pins},
+		"gopher": &gopherFetcher{timeout: timeout},
+		"file":   &localFetcher{baseDir: baseDir},
+	}
+}
+
+// fetchFeed dispatches href to the fetcher for its scheme. Links without
+// a scheme are paths on the local file system.
+func (fetchers feedFetchers) fetchFeed(href string, cache feedCache) (*feedResponse, error) {
+	if _, ok := filePath(href); ok {
+		return fetchers["file"].fetchFeed(href, cache)
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, ok := fetchers[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return fetcher.fetchFeed(href, cache)
+}
+
+// httpFetcher retrieves feeds over HTTP and HTTPS.
+type httpFetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+func (fetcher *httpFetcher) fetchFeed(href string, cache feedCache) (*feedResponse, error) {
+	return webget(fetcher.client, fetcher.userAgent, href, cache)
+}
+
+// datedTitle matches the "YYYY-MM-DD Title" labels used by Gemini
+// subscriptions and phlog menus to mark their entries.
+var datedTitle = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:\s*[-:–—]\s*|\s+)?(.*)$`)
+
+// parseDatedTitle splits a "YYYY-MM-DD Title" label into its date and
+// title. ok is false if the label doesn't start with a date.
+func parseDatedTitle(label string) (published time.Time, title string, ok bool) {
+	m := datedTitle.FindStringSubmatch(strings.TrimSpace(label))
+	if m == nil {
+		return time.Time{}, "", false
+	}
+	published, err := time.Parse("2006-01-02", m[1])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	title = strings.TrimSpace(m[2])
+	if title == "" {
+		title = m[1]
+	}
+	return published, title, true
+}
@@ -0,0 +1,319 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+)
+
+// tofuPin is the certificate a Gemini host presented the first time it
+// was seen.
+type tofuPin struct {
+	Fingerprint string
+	NotAfter    time.Time
+	FirstSeen   time.Time
+}
+
+// tofuPins holds the trust on first use certificate pins of a collection.
+// The pins are loaded before a harvest and the new or replaced ones saved
+// afterwards so the workers don't write to the database.
+type tofuPins struct {
+	mu      sync.Mutex
+	pins    map[string]tofuPin
+	changed map[string]bool
+	// notices holds messages about replaced pins to report after the harvest
+	notices []string
+}
+
+// loadTOFUPins reads the pinned certificates saved in a collection's
+// database.
+func loadTOFUPins(db *sql.DB) (*tofuPins, error) {
+	pins := &tofuPins{
+		pins:    map[string]tofuPin{},
+		changed: map[string]bool{},
+	}
+	rows, err := db.Query(SQLTOFUPins)
+	if err != nil {
+		return nil, fmt.Errorf("%s\nstmt: %s", err, SQLTOFUPins)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var host, fingerprint, notAfter, firstSeen string
+		if err := rows.Scan(&host, &fingerprint, &notAfter, &firstSeen); err != nil {
+			return nil, err
+		}
+		pin := tofuPin{Fingerprint: fingerprint}
+		pin.NotAfter, _ = time.Parse(time.RFC3339, notAfter)
+		pin.FirstSeen, _ = time.Parse(time.RFC3339, firstSeen)
+		pins.pins[host] = pin
+	}
+	return pins, rows.Err()
+}
+
+// check verifies cert against the pin for host. A host seen for the first
+// time is pinned. A different certificate is only accepted once the
+// pinned one has expired.
+func (pins *tofuPins) check(host string, cert *x509.Certificate) error {
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	now := time.Now()
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+	pin, ok := pins.pins[host]
+	switch {
+	case ok && pin.Fingerprint == fingerprint:
+		return nil
+	case ok && now.Before(pin.NotAfter):
+		return fmt.Errorf("certificate for %s does not match the one pinned on %s (sha256 %s)",
+			host, pin.FirstSeen.Format("2006-01-02"), pin.Fingerprint)
+	case ok:
+		pins.notices = append(pins.notices, fmt.Sprintf("pinned certificate for %s expired on %s, pinning the new one",
+			host, pin.NotAfter.Format("2006-01-02")))
+	}
+	pins.pins[host] = tofuPin{Fingerprint: fingerprint, NotAfter: cert.NotAfter, FirstSeen: now}
+	pins.changed[host] = true
+	return nil
+}
+
+// save writes the new and replaced pins to the collection's database.
+func (pins *tofuPins) save(db *sql.DB) error {
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+	for host := range pins.changed {
+		pin := pins.pins[host]
+		if _, err := db.Exec(SQLUpdateTOFUPin, host, pin.Fingerprint,
+			pin.NotAfter.Format(time.RFC3339), pin.FirstSeen.Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("%s\nstmt: %s", err, SQLUpdateTOFUPin)
+		}
+		delete(pins.changed, host)
+	}
+	return nil
+}
+
+// geminiFetcher retrieves feeds over the Gemini protocol, see
+// <https://geminiprotocol.net/docs/protocol-specification.gmi>.
+// Both Gemini subscription pages (gemlogs) and Atom or RSS feeds
+// are supported.
+type geminiFetcher struct {
+	timeout time.Duration
+	pins    *tofuPins
+}
+
+// geminiMaxRedirects matches the limit used for HTTP, see redirectHandler
+const geminiMaxRedirects = 5
+
+func (fetcher *geminiFetcher) fetchFeed(href string, cache feedCache) (*feedResponse, error) {
+	current := href
+	permanent := true
+	for i := 0; i <= geminiMaxRedirects; i++ {
+		u, err := url.Parse(current)
+		if err != nil {
+			return nil, err
+		}
+		status, meta, body, err := fetcher.request(u)
+		if err != nil {
+			return nil, err
+		}
+		switch status / 10 {
+		case 2:
+			feedRes, err := geminiFeedResponse(current, meta, body)
+			if err != nil {
+				return nil, err
+			}
+			feedRes.StatusCode = status
+			if permanent && current != href {
+				feedRes.MovedTo = current
+			}
+			return feedRes, nil
+		case 3:
+			next, err := u.Parse(meta)
+			if err != nil {
+				return nil, fmt.Errorf("bad redirect from %q, %s", current, err)
+			}
+			if status != 31 {
+				permanent = false
+			}
+			current = next.String()
+		default:
+			sErr := &statusError{StatusCode: status, Status: strings.TrimSpace(fmt.Sprintf("%d %s", status, meta))}
+			// 44 SLOW DOWN gives the number of seconds to wait
+			if status == 44 {
+				if seconds, err := strconv.Atoi(meta); err == nil && seconds > 0 {
+					sErr.RetryAfter = time.Duration(seconds) * time.Second
+				}
+			}
+			return nil, sErr
+		}
+	}
+	return nil, fmt.Errorf("stopped after %d redirects: %s", geminiMaxRedirects, href)
+}
+
+// request sends a Gemini request for u and returns the response status,
+// meta and body. The server certificate is checked against the
+// collection's TOFU pins rather than a certificate authority.
+func (fetcher *geminiFetcher) request(u *url.URL) (int, string, []byte, error) {
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "1965")
+	}
+	dialer := &net.Dialer{Timeout: fetcher.timeout}
+	// Gemini servers mostly use self signed certificates, they are
+	// verified with the TOFU pins below.
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         u.Hostname(),
+		MinVersion:         tls.VersionTLS12,
+	})
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer conn.Close()
+	if fetcher.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(fetcher.timeout))
+	}
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return 0, "", nil, fmt.Errorf("%s did not present a certificate", host)
+	}
+	if fetcher.pins != nil {
+		if err := fetcher.pins.check(host, certs[0]); err != nil {
+			return 0, "", nil, err
+		}
+	}
+	if _, err := fmt.Fprintf(conn, "%s\r\n", u.String()); err != nil {
+		return 0, "", nil, err
+	}
+	r := bufio.NewReader(conn)
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("bad response header from %s, %s", host, err)
+	}
+	header = strings.TrimRight(header, "\r\n")
+	code, meta, _ := strings.Cut(header, " ")
+	status, err := strconv.Atoi(code)
+	if err != nil || len(code) != 2 {
+		return 0, "", nil, fmt.Errorf("bad response header from %s, %q", host, header)
+	}
+	if status/10 != 2 {
+		return status, strings.TrimSpace(meta), nil, nil
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	return status, strings.TrimSpace(meta), body, nil
+}
+
+// geminiFeedResponse parses a successful Gemini response. The meta holds
+// the media type, text/gemini is read as a subscription page and anything
+// else as an Atom or RSS feed.
+func geminiFeedResponse(href string, meta string, body []byte) (*feedResponse, error) {
+	mediaType, _, err := mime.ParseMediaType(meta)
+	if err != nil || meta == "" {
+		mediaType = "text/gemini"
+	}
+	if mediaType == "text/gemini" {
+		feed, err := parseGemlog(href, body)
+		if err != nil {
+			return nil, err
+		}
+		return &feedResponse{Feed: feed}, nil
+	}
+	feed, schedule, err := parseFeedSource(href, body)
+	if err != nil {
+		return nil, err
+	}
+	return &feedResponse{Feed: feed, Schedule: schedule}, nil
+}
+
+// parseGemlog turns a Gemini subscription page into a feed following
+// <https://geminiprotocol.net/docs/companion/subscription.gmi>. The first
+// level one heading is the title, an optional level two heading after it
+// is the description and each link line whose label starts with a
+// YYYY-MM-DD date is an entry.
+func parseGemlog(href string, src []byte) (*gofeed.Feed, error) {
+	base, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	feed := &gofeed.Feed{
+		Link:     href,
+		FeedLink: href,
+		FeedType: "gemini",
+		Items:    []*gofeed.Item{},
+	}
+	preformatted := false
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "```") {
+			preformatted = !preformatted
+			continue
+		}
+		if preformatted {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "# ") && feed.Title == "":
+			feed.Title = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "## ") && feed.Description == "" && feed.Title != "" && len(feed.Items) == 0:
+			feed.Description = strings.TrimSpace(line[3:])
+		case strings.HasPrefix(line, "=>"):
+			fields := strings.Fields(line[2:])
+			if len(fields) < 2 {
+				continue
+			}
+			target := fields[0]
+			label := strings.Join(fields[1:], " ")
+			published, title, ok := parseDatedTitle(label)
+			if !ok {
+				continue
+			}
+			u, err := base.Parse(target)
+			if err != nil {
+				continue
+			}
+			feed.Items = append(feed.Items, &gofeed.Item{
+				Title:           title,
+				Link:            u.String(),
+				GUID:            u.String(),
+				Published:       published.Format("2006-01-02"),
+				PublishedParsed: &published,
+			})
+		}
+	}
+	if feed.Title == "" {
+		feed.Title = base.Host
+	}
+	return feed, nil
+}
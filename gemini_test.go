@@ -0,0 +1,183 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGemlog = "# Test Gemlog\n## Notes from the small web\n\n" +
+	"=> 2026-01-02-first.gmi 2026-01-02 First post\n" +
+	"=> /about.gmi About\n" +
+	"```\n=> 2026-01-03-hidden.gmi 2026-01-03 Preformatted\n```\n"
+
+// newGeminiTestServer starts a Gemini server on localhost with a freshly
+// generated self signed certificate. It serves testGemlog at /gemlog/ and
+// a permanent redirect to it from /old.
+func newGeminiTestServer(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				u, err := url.Parse(strings.TrimSpace(line))
+				if err != nil {
+					fmt.Fprintf(conn, "59 bad request\r\n")
+					return
+				}
+				switch u.Path {
+				case "/gemlog/":
+					fmt.Fprintf(conn, "20 text/gemini\r\n%s", testGemlog)
+				case "/old":
+					fmt.Fprintf(conn, "31 /gemlog/\r\n")
+				default:
+					fmt.Fprintf(conn, "51 Not found\r\n")
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestGeminiFetcher(t *testing.T) {
+	host := newGeminiTestServer(t)
+	pins := &tofuPins{pins: map[string]tofuPin{}, changed: map[string]bool{}}
+	fetcher := &geminiFetcher{timeout: 5 * time.Second, pins: pins}
+
+	res, err := fetcher.fetchFeed("gemini://"+host+"/gemlog/", feedCache{})
+	if err != nil {
+		t.Fatalf("fetchFeed: %s", err)
+	}
+	feed := res.Feed
+	if feed.Title != "Test Gemlog" || feed.Description != "Notes from the small web" {
+		t.Errorf("unexpected title and description %q, %q", feed.Title, feed.Description)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Title != "First post" ||
+		feed.Items[0].Link != "gemini://"+host+"/gemlog/2026-01-02-first.gmi" {
+		t.Fatalf("expected one dated entry, got %+v", feed.Items)
+	}
+	if !pins.changed[host] {
+		t.Errorf("expected the certificate to be pinned on first use")
+	}
+
+	res, err = fetcher.fetchFeed("gemini://"+host+"/old", feedCache{})
+	if err != nil {
+		t.Fatalf("fetchFeed (redirect): %s", err)
+	}
+	if res.MovedTo != "gemini://"+host+"/gemlog/" {
+		t.Errorf("expected a permanent redirect to be reported, got %q", res.MovedTo)
+	}
+
+	if _, err := fetcher.fetchFeed("gemini://"+host+"/missing", feedCache{}); err == nil {
+		t.Errorf("expected an error for a 51 response")
+	}
+
+	// A different certificate is refused until the pinned one expires
+	pins.pins[host] = tofuPin{Fingerprint: "not-the-certificate", NotAfter: time.Now().Add(time.Hour)}
+	if _, err := fetcher.fetchFeed("gemini://"+host+"/gemlog/", feedCache{}); err == nil {
+		t.Errorf("expected a pin mismatch to be refused")
+	}
+	pins.pins[host] = tofuPin{Fingerprint: "not-the-certificate", NotAfter: time.Now().Add(-time.Hour)}
+	if _, err := fetcher.fetchFeed("gemini://"+host+"/gemlog/", feedCache{}); err != nil {
+		t.Errorf("expected an expired pin to be replaced, %s", err)
+	}
+	if len(pins.notices) != 1 {
+		t.Errorf("expected a notice about the replaced pin, got %v", pins.notices)
+	}
+}
+
+func TestHarvestGeminiCollection(t *testing.T) {
+	host := newGeminiTestServer(t)
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "gemini.md")
+	src := fmt.Sprintf("# Gemini\n\n- [Gemlog](gemini://%s/gemlog/)\n", host)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "gemini.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, nil); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	count := 0
+	if err := db.QueryRow(SQLItemCount).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 harvested item, got %d", count)
+	}
+	pins, err := loadTOFUPins(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin, ok := pins.pins[host]; !ok || pin.Fingerprint == "" {
+		t.Errorf("expected the certificate pin to be saved in the collection, got %+v", pins.pins)
+	}
+}
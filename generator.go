@@ -20,10 +20,15 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
+	"html/template"
 	"io"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	// 3rd Party Packages
 	"gopkg.in/yaml.v3"
@@ -52,9 +57,14 @@ type Generator struct {
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 
 	// CMarkFilters are Lua filters applied to the CommonMark document when
-	// rendering HTML.
+	// rendering HTML. They use a Pandoc style element API, see luafilter.go.
 	CMarkFilters []string `json:"cm_filters,omitempty" yaml:"cm_filters,omitempty"`
 
+	// HeadingAnchors, when true, ends the headings of posts and pages with
+	// a link to themselves. A page's heading_anchors front matter overrides
+	// it.
+	HeadingAnchors bool `json:"heading_anchors,omitempty" yaml:"heading_anchors,omitempty"`
+
 	/*
 	 * HTML page elements
 	 */
@@ -98,6 +108,12 @@ type Generator struct {
 	// Defaults to "en-US" when not set via YAML.
 	Lang string `json:"lang,omitempty" yaml:"lang,omitempty"`
 
+	// Layout, when set, names the html/template file (or a glob pattern
+	// matching several files) pages are rendered through in place of the
+	// header, nav, top_content, bottom_content and footer slots. See
+	// LayoutData for the data passed to it.
+	Layout string `json:"layout,omitempty" yaml:"layout,omitempty"`
+
 	// Items, when set, controls how harvested feed items render in
 	// aggregate collection pages. Has no effect on local post/page
 	// rendering (DEC-031).
@@ -105,6 +121,39 @@ type Generator struct {
 
 	out  io.Writer
 	eout io.Writer
+
+	// now returns the time feeds are built at, tests set it so the
+	// output is reproducible.
+	now func() time.Time
+
+	// pagination, when set, is written as a Pagination nav at the end of
+	// the main element by WriteCustomHTML.
+	pagination *pageLinks
+
+	// collection is the collection being rendered, passed to the layout
+	collection *Collection
+
+	// layout holds the parsed Layout templates
+	layout *template.Template
+
+	// siteNav is the generated site navigation used when nav is "auto"
+	// or holds {{nav}}
+	siteNav []*navEntry
+
+	// htdocs is the directory the site is written to
+	htdocs string
+
+	// pagePath is the web path of the page being written, "/blog.html",
+	// its link in the site navigation is marked aria-current="page"
+	pagePath string
+}
+
+// buildTime returns the time the feeds are being built at
+func (gen *Generator) buildTime() time.Time {
+	if gen.now != nil {
+		return gen.now()
+	}
+	return time.Now()
 }
 
 // ItemsConfig controls how harvested feed items are rendered into
@@ -133,6 +182,10 @@ type ItemsConfig struct {
 
 	// HTML is one of "strip" (default), "escape", "unsafe" — DEC-024.
 	HTML string `json:"html,omitempty" yaml:"html,omitempty"`
+
+	// PageSize splits an aggregate page into pages of this many items,
+	// NAME.html, NAME-2.html and so on. Zero means a single page (default).
+	PageSize int `json:"page_size,omitempty" yaml:"page_size,omitempty"`
 }
 
 // LinkConfig controls the anchor generated for each feed item.
@@ -202,6 +255,9 @@ func (cfg *ItemsConfig) validate() error {
 	default:
 		return fmt.Errorf("items.link.missing: invalid value %q (want unlinked, omit, or source_link)", cfg.Link.Missing)
 	}
+	if cfg.PageSize < 0 {
+		return fmt.Errorf("items.page_size: invalid value %d (want zero or more)", cfg.PageSize)
+	}
 	return nil
 }
 
@@ -308,6 +364,8 @@ func (gen *Generator) LoadConfig(cfgName string) error {
 	if obj.AllowedMetaFields != nil {
 		gen.AllowedMetaFields = obj.AllowedMetaFields[:]
 	}
+	gen.Layout = obj.Layout
+	gen.layout = nil
 	gen.Items = obj.Items
 	return nil
 }
@@ -316,25 +374,48 @@ func (gen *Generator) LoadConfig(cfgName string) error {
  * When called with no args it processes every collection; otherwise only the named
  * collections are processed.  For each collection it regenerates: the aggregation
  * page (HTML + RSS + OPML), all individual post HTML pages, and all pages tracked
- * in the pages table.
+ * in the pages table.  Outputs whose inputs haven't changed since the last run,
+ * according to the build manifest next to antenna.yaml, are left alone unless
+ * --full is given.
  *
  * Parameters:
  *   out    (io.Writer) — progress messages
  *   eout   (io.Writer) — warning and error messages
  *   cfgName (string)   — path to antenna.yaml
- *   args   ([]string)  — optional list of collection filenames to restrict regeneration
+ *   args   ([]string)  — [--full] optional list of collection filenames to restrict regeneration
  *
  * Returns:
  *   error — first fatal error encountered, or nil on success
  *
  * Example:
- *   err := app.Generate(os.Stdout, os.Stderr, "antenna.yaml", nil)
+ *   err := app.Generate(os.Stdout, os.Stderr, "antenna.yaml", []string{"--full"})
  */
 func (app AntennaApp) Generate(out io.Writer, eout io.Writer, cfgName string, args []string) error {
+	full := false
+	names := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			names = append(names, arg)
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "full":
+			full = true
+		default:
+			return fmt.Errorf("unknown generate option %q", arg)
+		}
+	}
+	args = names
 	cfg := &AppConfig{}
 	if err := cfg.LoadConfig(cfgName); err != nil {
 		return err
 	}
+	manifest, err := LoadBuildManifest(manifestName(cfgName))
+	if err != nil {
+		return err
+	}
+	manifest.full = full
+	cfg.manifest = manifest
 	if len(args) == 0 {
 		for _, col := range cfg.Collections {
 			args = append(args, col.File)
@@ -362,7 +443,7 @@ func (app AntennaApp) Generate(out io.Writer, eout io.Writer, cfgName string, ar
 	if err := cfg.GeneratePages(eout); err != nil {
 		fmt.Fprintf(eout, "warning generating pages: %s\n", err)
 	}
-	return nil
+	return manifest.Save()
 }
 
 /** GeneratePosts re-renders the HTML file for every post (item with postPath set)
@@ -384,7 +465,7 @@ func (collection *Collection) GeneratePosts(eout io.Writer, appName string, cfg
 	if collection.DbName == "" {
 		return nil
 	}
-	db, err := sql.Open("sqlite", collection.DbName)
+	db, err := openCollectionDB(collection.DbName)
 	if err != nil {
 		return err
 	}
@@ -397,19 +478,18 @@ func (collection *Collection) GeneratePosts(eout io.Writer, appName string, cfg
 	if collection.Generator == "" {
 		collection.Generator = cfg.Generator
 	}
+	genSrc := []byte(DefaultGeneratorYaml)
 	if _, err := os.Stat(collection.Generator); err == nil {
-		src, err := os.ReadFile(collection.Generator)
+		genSrc, err = os.ReadFile(collection.Generator)
 		if err != nil {
 			return err
 		}
-		if err := yaml.Unmarshal(src, &gen); err != nil {
-			return err
-		}
-	} else {
-		if err := yaml.Unmarshal([]byte(DefaultGeneratorYaml), &gen); err != nil {
-			return err
-		}
 	}
+	if err := yaml.Unmarshal(genSrc, &gen); err != nil {
+		return err
+	}
+	gen.collection = collection
+	gen.useSite(cfg)
 
 	rows, err := db.Query(SQLGeneratePosts)
 	if err != nil {
@@ -442,12 +522,18 @@ func (collection *Collection) GeneratePosts(eout io.Writer, appName string, cfg
 		if strings.Contains(doc.Text, "@include-code-block") {
 			doc.Text = IncludeCodeBlock(doc.Text)
 		}
+		htmlName := normalizeToHTMLExt(filepath.Join(cfg.Htdocs, postPath))
+		sum := inputHash(string(genSrc), gen.layoutSource(), gen.navSource(), gen.filterSource(), cfg.BaseURL, link, postPath, pubDate, sourceMarkdown, doc.Text)
+		if !cfg.manifest.Changed(htmlName, sum) {
+			continue
+		}
+		doc.UseFilters(gen.CMarkFilters)
+		gen.useHeadings(doc)
 		innerHTML, err := doc.ToUnsafeHTML()
 		if err != nil {
 			fmt.Fprintf(eout, "warning rendering markdown for %q: %s\n", postPath, err)
 			continue
 		}
-		htmlName := normalizeToHTMLExt(filepath.Join(cfg.Htdocs, postPath))
 		dName := filepath.Dir(htmlName)
 		if _, err := os.Stat(dName); err != nil {
 			if err := os.MkdirAll(dName, 0775); err != nil {
@@ -457,7 +543,9 @@ func (collection *Collection) GeneratePosts(eout io.Writer, appName string, cfg
 		}
 		if err := gen.WriteHtmlPage(htmlName, link, postPath, pubDate, innerHTML, doc.FrontMatter); err != nil {
 			fmt.Fprintf(eout, "warning writing HTML for %q: %s\n", postPath, err)
+			continue
 		}
+		cfg.manifest.Record(htmlName, sum)
 	}
 	return rows.Err()
 }
@@ -488,9 +576,21 @@ func (cfg *AppConfig) GeneratePages(eout io.Writer) error {
 		if inputPath == "" {
 			continue
 		}
+		htmlName, sum := "", ""
+		if cfg.manifest != nil {
+			if htmlName, sum, err = cfg.pageHash(inputPath, outputPath); err != nil {
+				fmt.Fprintf(eout, "warning generating page %q: %s\n", inputPath, err)
+				continue
+			}
+			if !cfg.manifest.Changed(htmlName, sum) {
+				continue
+			}
+		}
 		if err := cfg.Page(inputPath, outputPath); err != nil {
 			fmt.Fprintf(eout, "warning generating page %q: %s\n", inputPath, err)
+			continue
 		}
+		cfg.manifest.Record(htmlName, sum)
 	}
 	return nil
 }
@@ -510,6 +610,33 @@ func (collection *Collection) ApplyFilters(db *sql.DB) error {
 	return nil
 }
 
+// baseName returns the name the collection's pages and feeds are written
+// under in htdocs, its file's base name without the extension, e.g.
+// "links" for "feeds/links.odt".
+func (collection *Collection) baseName() string {
+	bName := filepath.Base(collection.File)
+	return strings.TrimSuffix(bName, filepath.Ext(bName))
+}
+
+// feedLink returns the public URL of the collection's feed with the file
+// extension ext, e.g. ".atom". The collection's link names its RSS feed,
+// see WriteRSS, the other formats are linked next to it. Without a link
+// the feed is named for the collection's base name.
+func (collection *Collection) feedLink(baseURL string, ext string) string {
+	if collection.Link == "" {
+		return fmt.Sprintf("%s/%s%s", baseURL, collection.baseName(), ext)
+	}
+	u, err := url.Parse(collection.Link)
+	if err != nil {
+		return fmt.Sprintf("%s/%s%s", baseURL, collection.baseName(), ext)
+	}
+	u.Path = strings.TrimSuffix(u.Path, path.Ext(u.Path)) + ext
+	if strings.Contains(collection.Link, "://") {
+		return u.String()
+	}
+	return fmt.Sprintf("%s/%s", baseURL, u.String())
+}
+
 func (collection *Collection) Generate(out io.Writer, eout io.Writer, appName string, cfg *AppConfig) error {
 	gen, err := NewGenerator(appName, cfg.BaseURL)
 	if err != nil {
@@ -518,24 +645,39 @@ func (collection *Collection) Generate(out io.Writer, eout io.Writer, appName st
 	if collection.Generator == "" {
 		collection.Generator = cfg.Generator
 	}
+	genSrc := []byte(DefaultGeneratorYaml)
 	if _, err := os.Stat(collection.Generator); err == nil {
-		src, err := os.ReadFile(collection.Generator)
+		genSrc, err = os.ReadFile(collection.Generator)
 		if err != nil {
 			return err
 		}
-		if err := yaml.Unmarshal(src, &gen); err != nil {
+	}
+	if err := yaml.Unmarshal(genSrc, &gen); err != nil {
+		return err
+	}
+	gen.useSite(cfg)
+	// Skip the collection when nothing it is written from has changed
+	bName := filepath.Base(collection.File)
+	htmlName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, filepath.Ext(bName))+".html")
+	gen.pagePath = webPath(cfg.Htdocs, htmlName)
+	if cfg.manifest != nil {
+		sum, err := collectionHash(genSrc, gen.layoutSource()+gen.navSource()+gen.filterSource(), collection, cfg.BaseURL)
+		if err != nil {
 			return err
 		}
-	} else {
-		if err := yaml.Unmarshal([]byte(DefaultGeneratorYaml), &gen); err != nil {
-			return err
+		if !cfg.manifest.Changed(htmlName, sum) {
+			return nil
 		}
 	}
 	// Use collection title as the HTML page title when the generator yaml has none
 	if gen.Title == "" && collection.Title != "" {
 		gen.Title = collection.Title
 	}
-	if collection.Link != "" {
+	formats, err := collection.SyndicationFormats()
+	if err != nil {
+		return err
+	}
+	if collection.Link != "" && slices.Contains(formats, "rss") {
 		m := map[string]string{
 			"rel":  "alternate",
 			"type": "application/rss+xml",
@@ -543,13 +685,41 @@ func (collection *Collection) Generate(out io.Writer, eout io.Writer, appName st
 		}
 		gen.Link = append(gen.Link, m)
 	}
-	return gen.Generate(eout, appName, cfg, collection)
+	// The Atom feed and JSON Feed are written next to the HTML page
+	baseName := strings.TrimSuffix(filepath.Base(collection.File), filepath.Ext(collection.File))
+	if slices.Contains(formats, "atom") {
+		gen.Link = append(gen.Link, map[string]string{
+			"rel":  "alternate",
+			"type": "application/atom+xml",
+			"href": baseName + ".atom",
+		})
+	}
+	if slices.Contains(formats, "json") {
+		gen.Link = append(gen.Link, map[string]string{
+			"rel":  "alternate",
+			"type": "application/feed+json",
+			"href": baseName + ".json",
+		})
+	}
+	gen.collection = collection
+	if err := gen.Generate(eout, appName, cfg, collection); err != nil {
+		return err
+	}
+	if cfg.manifest != nil {
+		// Record the items as the collection's filters left them
+		sum, err := collectionHash(genSrc, gen.layoutSource()+gen.navSource()+gen.filterSource(), collection, cfg.BaseURL)
+		if err != nil {
+			return err
+		}
+		cfg.manifest.Record(htmlName, sum)
+	}
+	return nil
 }
 
 func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, collection *Collection) error {
 	// Open DB so we have a place to write data.
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
@@ -566,6 +736,8 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 	htmlName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, xName)+".html")
 	rssName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, xName)+".xml")
 	opmlName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, xName)+".opml")
+	atomName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, xName)+".atom")
+	jsonName := filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, xName)+".json")
 
 	// clear existing page
 	if _, err := os.Stat(htmlName); err == nil {
@@ -582,7 +754,13 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 
 	// Write out HTML page — mode: page-index renders a simple link list from
 	// the pages table; the default "aggregate" mode renders feed item cards.
-	if collection.Mode == "page-index" {
+	if collection.Mode == "page-index" && gen.Layout != "" {
+		// The layout renders the whole page
+		if err := gen.WritePageIndex(out, db); err != nil {
+			out.Close()
+			return err
+		}
+	} else if collection.Mode == "page-index" {
 		// Wrap the link list in a full HTML shell using the same header/nav/footer
 		// as the aggregate mode, but replace <main> content with WritePageIndex.
 		fmt.Fprintf(out, "<!doctype html>\n<html lang=%q>\n", gen.Lang)
@@ -592,8 +770,8 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 		if gen.Header != "" {
 			fmt.Fprintf(out, "  <header>\n    %s\n  </header>\n", indentText(strings.TrimSpace(gen.Header), 4))
 		}
-		if gen.Nav != "" {
-			fmt.Fprintf(out, "  <nav aria-label=\"Site navigation\">\n    %s\n  </nav>\n", indentText(strings.TrimSpace(gen.Nav), 4))
+		if nav := gen.navHTML(); nav != "" {
+			fmt.Fprintf(out, "  <nav aria-label=\"Site navigation\">\n    %s\n  </nav>\n", indentText(strings.TrimSpace(nav), 4))
 		}
 		if gen.TopContent != "" {
 			fmt.Fprintf(out, "\n    %s\n", indentText(strings.TrimSpace(gen.TopContent), 2))
@@ -610,6 +788,11 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 		}
 		fmt.Fprintln(out, "</body>")
 		fmt.Fprintln(out, "</html>")
+	} else if gen.Items.PageSize > 0 {
+		if err := gen.WritePagedHTML(out, db, htmlName); err != nil {
+			out.Close()
+			return err
+		}
 	} else {
 		if err := gen.WriteHTML(out, db, appName, collection); err != nil {
 			out.Close()
@@ -618,24 +801,57 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 	}
 	out.Close()
 
-	// clear existing page
-	if _, err := os.Stat(rssName); err == nil {
-		if err := os.Remove(rssName); err != nil {
-			return nil
+	// Write out the syndication formats the collection asks for
+	formats, err := collection.SyndicationFormats()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(formats, "rss") {
+		// clear existing page
+		if _, err := os.Stat(rssName); err == nil {
+			if err := os.Remove(rssName); err != nil {
+				return nil
+			}
+		}
+
+		// Create the RSS file
+		out, err = os.Create(rssName)
+		if err != nil {
+			return err
+		}
+
+		// Write out RSS page
+		if err := gen.WriteRSS(out, db, appName, collection); err != nil {
+			return err
 		}
+		out.Close()
 	}
 
-	// Create the RSS file
-	out, err = os.Create(rssName)
-	if err != nil {
-		return err
+	// Write out the Atom feed from the same items
+	if slices.Contains(formats, "atom") {
+		out, err = os.Create(atomName)
+		if err != nil {
+			return err
+		}
+		if err := gen.WriteAtom(out, db, appName, collection); err != nil {
+			out.Close()
+			return err
+		}
+		out.Close()
 	}
 
-	// Write out RSS page
-	if err := gen.WriteRSS(out, db, appName, collection); err != nil {
-		return err
+	// Write out the JSON Feed from the same items
+	if slices.Contains(formats, "json") {
+		out, err = os.Create(jsonName)
+		if err != nil {
+			return err
+		}
+		if err := gen.WriteJSONFeed(out, db, collection); err != nil {
+			out.Close()
+			return err
+		}
+		out.Close()
 	}
-	out.Close()
 
 	// Write OPML to a buffer first — only create the file if there are feeds
 	var opmlBuf bytes.Buffer
@@ -658,5 +874,22 @@ func (gen *Generator) Generate(eout io.Writer, appName string, cfg *AppConfig, c
 		}
 		out.Close()
 	}
+
+	// Write out the tag and author pages
+	if collection.TagPages {
+		if err := gen.GenerateTags(db, appName, cfg, collection); err != nil {
+			return err
+		}
+	}
+	if collection.AuthorPages {
+		if err := gen.GenerateAuthors(db, cfg, collection); err != nil {
+			return err
+		}
+	}
+	if collection.ArchivePages {
+		if err := gen.GenerateArchive(db, htmlName, collection); err != nil {
+			return err
+		}
+	}
 	return nil
 }
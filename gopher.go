@@ -0,0 +1,156 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+)
+
+// gopherFetcher retrieves phlog feeds over the Gopher protocol, see
+// RFC 1436. A gopher URL's path starts with the item type followed by the
+// selector (RFC 4266). Menus (type 1) are read as a phlog where each entry
+// whose display string starts with a YYYY-MM-DD date is an item, any other
+// type is expected to hold an Atom or RSS feed.
+type gopherFetcher struct {
+	timeout time.Duration
+}
+
+func (fetcher *gopherFetcher) fetchFeed(href string, cache feedCache) (*feedResponse, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	itemType, selector := byte('1'), ""
+	if path := u.Path; len(path) > 1 {
+		itemType, selector = path[1], path[2:]
+	}
+	src, err := fetcher.request(u, selector)
+	if err != nil {
+		return nil, err
+	}
+	if itemType == '1' {
+		feed, err := parseGophermap(href, src)
+		if err != nil {
+			return nil, err
+		}
+		return &feedResponse{Feed: feed}, nil
+	}
+	feed, schedule, err := parseFeedSource(href, trimGopherText(src))
+	if err != nil {
+		return nil, err
+	}
+	return &feedResponse{Feed: feed, Schedule: schedule}, nil
+}
+
+// request sends selector to the gopher server in u and returns the reply.
+func (fetcher *gopherFetcher) request(u *url.URL, selector string) ([]byte, error) {
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "70")
+	}
+	conn, err := net.DialTimeout("tcp", host, fetcher.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if fetcher.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(fetcher.timeout))
+	}
+	if _, err := fmt.Fprintf(conn, "%s\r\n", selector); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(conn)
+}
+
+// trimGopherText removes the lone "." line that ends a gopher text reply.
+func trimGopherText(src []byte) []byte {
+	src = bytes.TrimRight(src, "\r\n")
+	if bytes.HasSuffix(src, []byte("\n.")) {
+		src = src[:len(src)-1]
+	}
+	return src
+}
+
+// parseGophermap turns a gopher menu into a feed. The first info line is
+// used as the title and each entry whose display string starts with a
+// YYYY-MM-DD date becomes an item.
+func parseGophermap(href string, src []byte) (*gofeed.Feed, error) {
+	base, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	feed := &gofeed.Feed{
+		Link:     href,
+		FeedLink: href,
+		FeedType: "gopher",
+		Items:    []*gofeed.Item{},
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "." {
+			break
+		}
+		if line == "" {
+			continue
+		}
+		itemType := line[0]
+		fields := strings.Split(line[1:], "\t")
+		display := strings.TrimSpace(fields[0])
+		if itemType == 'i' {
+			if feed.Title == "" && display != "" {
+				feed.Title = display
+			}
+			continue
+		}
+		if itemType == '3' || len(fields) < 4 {
+			continue
+		}
+		published, title, ok := parseDatedTitle(display)
+		if !ok {
+			continue
+		}
+		selector, host, port := fields[1], fields[2], strings.TrimSpace(fields[3])
+		link := fmt.Sprintf("gopher://%s/%c%s", net.JoinHostPort(host, port), itemType, selector)
+		if port == "70" {
+			link = fmt.Sprintf("gopher://%s/%c%s", host, itemType, selector)
+		}
+		// Links to the web are given as "URL:" selectors
+		if itemType == 'h' && strings.HasPrefix(selector, "URL:") {
+			link = strings.TrimPrefix(selector, "URL:")
+		}
+		feed.Items = append(feed.Items, &gofeed.Item{
+			Title:           title,
+			Link:            link,
+			GUID:            link,
+			Published:       published.Format("2006-01-02"),
+			PublishedParsed: &published,
+		})
+	}
+	if feed.Title == "" {
+		feed.Title = base.Host
+	}
+	return feed, nil
+}
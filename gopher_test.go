@@ -0,0 +1,81 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGopherFetcher(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				selector, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				switch strings.TrimSpace(selector) {
+				case "/phlog":
+					fmt.Fprintf(conn, "iMy Phlog\tfake\t(NULL)\t0\r\n")
+					fmt.Fprintf(conn, "i\tfake\t(NULL)\t0\r\n")
+					fmt.Fprintf(conn, "02026-01-02 First entry\t/phlog/first.txt\t%s\t%s\r\n", host, port)
+					fmt.Fprintf(conn, "1About\t/about\t%s\t%s\r\n", host, port)
+					fmt.Fprintf(conn, ".\r\n")
+				case "/feed.xml":
+					fmt.Fprintf(conn, "%s\r\n.\r\n", testFeedRSS)
+				}
+			}(conn)
+		}
+	}()
+
+	fetcher := &gopherFetcher{timeout: 5 * time.Second}
+	res, err := fetcher.fetchFeed(fmt.Sprintf("gopher://%s/1/phlog", ln.Addr()), feedCache{})
+	if err != nil {
+		t.Fatalf("fetchFeed (menu): %s", err)
+	}
+	if res.Feed.Title != "My Phlog" {
+		t.Errorf("expected the first info line as title, got %q", res.Feed.Title)
+	}
+	expected := fmt.Sprintf("gopher://%s/0/phlog/first.txt", ln.Addr())
+	if len(res.Feed.Items) != 1 || res.Feed.Items[0].Link != expected || res.Feed.Items[0].Title != "First entry" {
+		t.Fatalf("expected one dated entry linking to %s, got %+v", expected, res.Feed.Items)
+	}
+
+	res, err = fetcher.fetchFeed(fmt.Sprintf("gopher://%s/0/feed.xml", ln.Addr()), feedCache{})
+	if err != nil {
+		t.Fatalf("fetchFeed (RSS): %s", err)
+	}
+	if res.Feed.Title != "Test Feed" || len(res.Feed.Items) != 1 {
+		t.Errorf("expected the RSS feed to be parsed, got %+v", res.Feed)
+	}
+}
@@ -20,12 +20,15 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	// 3rd Party pacakges
@@ -33,11 +36,46 @@ import (
 	"github.com/mmcdole/gofeed"
 )
 
+// HarvestOptions holds the options of the harvest action
+type HarvestOptions struct {
+	// Force harvests every feed even when it isn't due yet
+	Force bool
+	// RewriteMoved updates the collection's links to feeds that have
+	// permanently moved
+	RewriteMoved bool
+}
+
+// parseHarvestOptions separates the harvest options from the collection
+// names in args.
+func parseHarvestOptions(args []string) (*HarvestOptions, []string, error) {
+	opts := &HarvestOptions{}
+	names := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			names = append(names, arg)
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "force":
+			opts.Force = true
+		case "rewrite-moved":
+			opts.RewriteMoved = true
+		default:
+			return nil, nil, fmt.Errorf("unknown harvest option %q", arg)
+		}
+	}
+	return opts, names, nil
+}
+
 func (app AntennaApp) Harvest(out io.Writer, eout io.Writer, cfgName string, args []string) error {
 	cfg := &AppConfig{}
 	if err := cfg.LoadConfig(cfgName); err != nil {
 		return err
 	}
+	opts, args, err := parseHarvestOptions(args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		for _, col := range cfg.Collections {
 			args = append(args, col.File)
@@ -48,14 +86,157 @@ func (app AntennaApp) Harvest(out io.Writer, eout io.Writer, cfgName string, arg
 		if err != nil {
 			return err
 		}
-		if err := col.Harvest(out, eout, cfg.UserAgent); err != nil {
+		if err := col.Harvest(out, eout, cfg, opts); err != nil {
 			fmt.Fprintf(eout, "warning %s: %s\n", col.File, err)
 		}
 	}
 	return nil
 }
 
-func (collection *Collection) Harvest(out io.Writer, eout io.Writer, userAgent string) error {
+const (
+	// defaultHarvestConcurrency is the number of feeds fetched at the same
+	// time when antenna.yaml doesn't set concurrency.
+	defaultHarvestConcurrency = 4
+
+	// defaultHostConcurrency is the number of feeds fetched from a single
+	// host at the same time when antenna.yaml doesn't set host_concurrency.
+	defaultHostConcurrency = 2
+
+	// defaultHarvestTimeout is the number of seconds to wait on a feed
+	// request when antenna.yaml doesn't set timeout.
+	defaultHarvestTimeout = 30
+)
+
+// harvestLimits returns the concurrency, per host concurrency and request
+// timeout to use when harvesting the collection. Collection settings take
+// precedence over the ones in antenna.yaml, then the defaults are used.
+func (collection *Collection) harvestLimits(cfg *AppConfig) (int, int, time.Duration) {
+	concurrency, hostConcurrency, timeout := defaultHarvestConcurrency, defaultHostConcurrency, defaultHarvestTimeout
+	if cfg != nil {
+		if cfg.Concurrency > 0 {
+			concurrency = cfg.Concurrency
+		}
+		if cfg.HostConcurrency > 0 {
+			hostConcurrency = cfg.HostConcurrency
+		}
+		if cfg.Timeout > 0 {
+			timeout = cfg.Timeout
+		}
+	}
+	if collection.Concurrency > 0 {
+		concurrency = collection.Concurrency
+	}
+	if collection.HostConcurrency > 0 {
+		hostConcurrency = collection.HostConcurrency
+	}
+	if collection.Timeout > 0 {
+		timeout = collection.Timeout
+	}
+	return concurrency, hostConcurrency, time.Duration(timeout) * time.Second
+}
+
+// linkHost returns the host name of a feed URL, it is used to group
+// requests when applying the per host limit.
+func linkHost(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return strings.ToLower(u.Host)
+}
+
+// hostLimiter limits the number of requests made at once to each host.
+// Feed and article requests share it so a host sees no more than the
+// host_concurrency limit from a harvest.
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	hosts map[string]chan struct{}
+}
+
+// newHostLimiter returns a hostLimiter allowing limit requests at once
+// to each host.
+func newHostLimiter(limit int) *hostLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &hostLimiter{limit: limit, hosts: map[string]chan struct{}{}}
+}
+
+// acquire waits until a request can be made to the host of href and
+// returns the function releasing it.
+func (limiter *hostLimiter) acquire(href string) func() {
+	host := linkHost(href)
+	limiter.mu.Lock()
+	sem, ok := limiter.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, limiter.limit)
+		limiter.hosts[host] = sem
+	}
+	limiter.mu.Unlock()
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// harvestJob describes a feed for a harvest worker to retrieve.
+type harvestJob struct {
+	link  Link
+	cache feedCache
+	// schedule holds the feed hints saved by the last harvest
+	schedule feedSchedule
+}
+
+// harvestResult is what a harvest worker hands back after retrieving a feed.
+type harvestResult struct {
+	job harvestJob
+	res *feedResponse
+	err error
+	// articles holds the full articles retrieved for the feed's items,
+	// keyed by item link
+	articles map[string]string
+	// articleErrs holds the errors for the articles that couldn't be
+	// retrieved
+	articleErrs []error
+}
+
+// harvestItemLink returns the link an item is saved under. Links relative
+// to the site are resolved against the feed's link before the canonical
+// link is worked out.
+func harvestItemLink(feed *gofeed.Feed, item *gofeed.Item) string {
+	if strings.HasPrefix(item.Link, "/") {
+		item = &gofeed.Item{
+			Link:       fmt.Sprintf("%s%s", strings.TrimSuffix(feed.Link, "/"), item.Link),
+			GUID:       item.GUID,
+			Extensions: item.Extensions,
+		}
+	}
+	return itemLink(item)
+}
+
+// saveFeedSchedule works out when the feed should next be harvested and
+// saves it along with the feed's own scheduling hints.
+func saveFeedSchedule(db *sql.DB, link Link, schedule feedSchedule, collectionTTL time.Duration) error {
+	next := schedule.nextHarvest(time.Now(), collectionTTL)
+	_, err := db.Exec(SQLUpdateFeedSchedule, next.Format(time.RFC3339), int(schedule.TTL.Seconds()),
+		encodeSkipHours(schedule.SkipHours), encodeSkipDays(schedule.SkipDays), link.URL)
+	return err
+}
+
+// Harvest retrieves the feeds listed in the collection and saves their
+// channel and items in the collection's database. Feeds are fetched by a
+// bounded pool of workers while the database writes happen one at a time
+// as results come back. Feeds that are not due yet, based on the
+// collection's TTL and the hints from the feed and its server, are
+// skipped unless opts.Force is set.
+func (collection *Collection) Harvest(out io.Writer, eout io.Writer, cfg *AppConfig, opts *HarvestOptions) error {
+	userAgent := ""
+	if cfg != nil {
+		userAgent = cfg.UserAgent
+	}
+	if opts == nil {
+		opts = &HarvestOptions{}
+	}
+	collectionTTL := time.Duration(collection.TTL) * time.Second
 	// LoadCommonMark accepts Markdown (.md) or ODT/OTT collection files.
 	doc, err := LoadCommonMark(collection.File)
 	if err != nil {
@@ -67,37 +248,162 @@ func (collection *Collection) Harvest(out io.Writer, eout io.Writer, userAgent s
 	}
 	// Open DB so we have a place to write data.
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
-	// Retrieve is feed for links and save channel and items for feed
+	// Setup the jobs, sending any cache headers saved from the last harvest
+	// and leaving out the feeds that aren't due yet.
+	jobs := []harvestJob{}
+	notDue := 0
+	now := time.Now()
 	for _, link := range links {
-		// Retrieve feed data
-		feed, err := webget(userAgent, link.URL)
-		if err != nil {
+		job := harvestJob{link: link}
+		if err := db.QueryRow(SQLChannelCacheHeaders, link.URL).Scan(&job.cache.ETag, &job.cache.LastModified, &job.cache.DiscoveredURL); err != nil && err != sql.ErrNoRows {
+			fmt.Fprintf(eout, "warning (%s %s): %s\n", link.Label, link.URL, err)
+		}
+		var (
+			nextHarvest string
+			ttl         int
+			skipHours   string
+			skipDays    string
+		)
+		if err := db.QueryRow(SQLFeedSchedule, link.URL).Scan(&nextHarvest, &ttl, &skipHours, &skipDays); err != nil && err != sql.ErrNoRows {
 			fmt.Fprintf(eout, "warning (%s %s): %s\n", link.Label, link.URL, err)
+		}
+		job.schedule = decodeFeedSchedule(ttl, skipHours, skipDays)
+		if !opts.Force && nextHarvest != "" {
+			if next, err := time.Parse(time.RFC3339, nextHarvest); err == nil && next.After(now) {
+				fmt.Fprintf(out, "not due %s %s until %s\n", link.Label, link.URL, next.Format(time.RFC3339))
+				notDue++
+				continue
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	concurrency, hostConcurrency, timeout := collection.harvestLimits(cfg)
+	// Gemini certificates are pinned per collection on first use
+	pins, err := loadTOFUPins(db)
+	if err != nil {
+		return err
+	}
+	fetchers := newFeedFetchers(userAgent, timeout, pins, filepath.Dir(collection.File))
+	// Each host gets a semaphore so we don't hammer a single server
+	hostLimits := newHostLimiter(hostConcurrency)
+	var articles *articleFetcher
+	if collection.FullArticle || len(collection.FullArticleFeeds) > 0 {
+		if articles, err = newArticleFetcher(db, userAgent, timeout, hostLimits); err != nil {
+			return err
+		}
+	}
+	queue := make(chan harvestJob)
+	results := make(chan harvestResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				release := hostLimits.acquire(job.link.URL)
+				res, err := fetchers.fetchFeed(job.link.URL, job.cache)
+				release()
+				result := harvestResult{job: job, res: res, err: err}
+				// Articles are retrieved one at a time by the worker, so
+				// they share the concurrency limit of the feeds, and
+				// through hostLimits the limit of each host
+				if err == nil && res.Feed != nil && articles != nil && collection.fullArticle(job.link) {
+					result.articles, result.articleErrs = articles.fetchArticles(res.Feed)
+				}
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		for _, job := range jobs {
+			queue <- job
+		}
+		close(queue)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Setup a progress output for the collection
+	t0 := time.Now()
+	rptTime := time.Now()
+	reportProgress := false
+	tot := len(jobs)
+	done, unchanged := 0, 0
+	// moved maps the feeds that permanently redirected to their new URL
+	moved := map[string]string{}
+	var harvestErr error
+	for result := range results {
+		done++
+		if harvestErr != nil {
+			// Drain the remaining results so the workers can finish
+			continue
+		}
+		if rptTime, reportProgress = CheckWaitInterval(rptTime, (20 * time.Second)); reportProgress {
+			fmt.Fprintf(out, "(%d/%d feeds) %s\n", done, tot, ProgressETA(t0, done, tot))
+		}
+		link, res := result.job.link, result.res
+		// Carry the feed's hints forward for responses without a feed body
+		schedule := result.job.schedule
+		if result.err != nil {
+			fmt.Fprintf(eout, "warning (%s %s): %s\n", link.Label, link.URL, result.err)
+			if err := recordFeedHealth(db, link, 0, 0, result.err); err != nil {
+				fmt.Fprintf(eout, "failed to record health for %q, %s\n", link.URL, err)
+			}
+			var sErr *statusError
+			if errors.As(result.err, &sErr) {
+				schedule.RetryAfter = sErr.RetryAfter
+			}
+			if err := saveFeedSchedule(db, link, schedule, 0); err != nil {
+				fmt.Fprintf(eout, "failed to save schedule for %q, %s\n", link.URL, err)
+			}
+			continue
+		}
+		if res.MovedTo != "" {
+			fmt.Fprintf(out, "moved %s %s to %s\n", link.Label, link.URL, res.MovedTo)
+			moved[link.URL] = res.MovedTo
+		}
+		if res.DiscoveredURL != "" {
+			fmt.Fprintf(out, "discovered %s for %s %s\n", res.DiscoveredURL, link.Label, link.URL)
+		}
+		if res.NotModified {
+			fmt.Fprintf(out, "unchanged %s %s\n", link.Label, link.URL)
+			if err := recordFeedHealth(db, link, res.StatusCode, 0, nil); err != nil {
+				fmt.Fprintf(eout, "failed to record health for %q, %s\n", link.URL, err)
+			}
+			if _, err := db.Exec(SQLUpdateFeedMovedTo, res.MovedTo, link.URL); err != nil {
+				fmt.Fprintf(eout, "failed to record move for %q, %s\n", link.URL, err)
+			}
+			schedule.MaxAge, schedule.RetryAfter = res.Schedule.MaxAge, res.Schedule.RetryAfter
+			if err := saveFeedSchedule(db, link, schedule, collectionTTL); err != nil {
+				fmt.Fprintf(eout, "failed to save schedule for %q, %s\n", link.URL, err)
+			}
+			unchanged++
 			continue
 		}
+		feed := res.Feed
 
 		// Save the Channel data for the feed
 		if err := saveChannel(db, link.URL, link.Label, feed); err != nil {
 			fmt.Fprintf(eout, "failed to save chanel %q, %s\n", link.URL, err)
+			if err := recordFeedHealth(db, link, res.StatusCode, 0, err); err != nil {
+				fmt.Fprintf(eout, "failed to record health for %q, %s\n", link.URL, err)
+			}
 			continue
 		}
-		// Setup a progress output
-		t0 := time.Now()
-		rptTime := time.Now()
-		reportProgress := false
-		tot := feed.Len()
-		fmt.Fprintf(out, "processing %d items from %s %s\n", tot, link.Label, userAgent)
+		if _, err := db.Exec(SQLUpdateChannelCacheHeaders, res.ETag, res.LastModified, res.DiscoveredURL, link.URL); err != nil {
+			fmt.Fprintf(eout, "failed to save cache headers for %q, %s\n", link.URL, err)
+		}
 		i := 0
 		// Save the item data for the feed
+		for _, err := range result.articleErrs {
+			fmt.Fprintf(eout, "warning (%s %s): %s\n", link.Label, link.URL, err)
+		}
 		for _, item := range feed.Items {
-			if strings.HasPrefix(item.Link, "/") {
-				item.Link = fmt.Sprintf("%s%s", strings.TrimSuffix(feed.Link, "/"), item.Link)
-			}
 			// Default to the feed dates if not set at item level
 			if item.Updated == "" && item.UpdatedParsed == nil {
 				item.Updated = feed.Updated
@@ -107,40 +413,170 @@ func (collection *Collection) Harvest(out io.Writer, eout io.Writer, userAgent s
 				item.Published = feed.Published
 				item.PublishedParsed = feed.PublishedParsed
 			}
+			item.Link = harvestItemLink(feed, item)
+			label := link.Label
+			if collection.NearDuplicates {
+				var collapsed bool
+				if label, collapsed, harvestErr = collapseDuplicate(db, link.Label, item); harvestErr != nil {
+					break
+				}
+				if collapsed {
+					i++
+					continue
+				}
+			}
 			// Add items from feed to database table
-			if err := saveItem(db, link.Label, link.URL, "", item); err != nil {
-				return err
+			if err := saveItem(db, label, link.URL, "", item); err != nil {
+				harvestErr = err
+				break
+			}
+			if collection.NearDuplicates {
+				if _, err := db.Exec(SQLUpdateItemTitleKey, titleKey(item.Title), item.Link); err != nil {
+					harvestErr = err
+					break
+				}
 			}
-			if rptTime, reportProgress = CheckWaitInterval(rptTime, (20 * time.Second)); reportProgress {
-				fmt.Fprintf(out, "(%d/%d) %s", i, tot, ProgressETA(t0, i, tot))
+			if article, ok := result.articles[item.Link]; ok {
+				if _, err := db.Exec(SQLUpdateItemFullMarkdown, article, item.Link); err != nil {
+					harvestErr = err
+					break
+				}
 			}
 			i++
 		}
-		fmt.Fprintf(out, "processed %d/%d from %s %s\n", i, tot, link.Label, userAgent)
+		if err := recordFeedHealth(db, link, res.StatusCode, i, harvestErr); err != nil {
+			fmt.Fprintf(eout, "failed to record health for %q, %s\n", link.URL, err)
+		}
+		if _, err := db.Exec(SQLUpdateFeedMovedTo, res.MovedTo, link.URL); err != nil {
+			fmt.Fprintf(eout, "failed to record move for %q, %s\n", link.URL, err)
+		}
+		if err := saveFeedSchedule(db, link, res.Schedule, collectionTTL); err != nil {
+			fmt.Fprintf(eout, "failed to save schedule for %q, %s\n", link.URL, err)
+		}
+		fmt.Fprintf(out, "processed %d/%d from %s %s\n", i, feed.Len(), link.Label, userAgent)
 	}
+	if err := pins.save(db); err != nil {
+		fmt.Fprintf(eout, "failed to save certificate pins, %s\n", err)
+	}
+	for _, notice := range pins.notices {
+		fmt.Fprintf(eout, "warning (%s): %s\n", collection.File, notice)
+	}
+	if harvestErr != nil {
+		return harvestErr
+	}
+	if cnt, err := collection.prune(out, db, false, false); err != nil {
+		fmt.Fprintf(eout, "warning (%s): failed to prune items, %s\n", collection.File, err)
+	} else if cnt > 0 {
+		fmt.Fprintf(out, "pruned %d items from %s\n", cnt, collection.File)
+	}
+	if len(moved) > 0 {
+		if opts.RewriteMoved {
+			if err := collection.rewriteMoved(out, eout, db, moved); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(out, "%d feeds moved, use harvest --rewrite-moved to update %s\n", len(moved), collection.File)
+		}
+	}
+	fmt.Fprintf(out, "%d of %d feeds unchanged, %d not due in %s\n", unchanged, tot, notDue, collection.File)
 	return nil
 }
 
+// redirectHandler follows up to five redirects. Whether the feed has
+// moved permanently is worked out by movedPermanently once the final
+// response arrives.
 func redirectHandler(req *http.Request, via []*http.Request) error {
 	if len(via) >= 5 {
 		urlList := []string{}
 		for _, redirect := range via {
 			urlList = append(urlList, redirect.URL.String())
 		}
-		return fmt.Errorf("stopped after 5 redirectos: %s", strings.Join(urlList, ", "))
+		return fmt.Errorf("stopped after 5 redirects: %s", strings.Join(urlList, ", "))
 	}
-	fmt.Fprintf(os.Stderr, "redirecting to %s because %s\n", req.URL.String(), http.ErrUseLastResponse)
-	return http.ErrUseLastResponse
+	return nil
 }
 
-// webget retrieves a feed and parses it.
-// Uses mmcdole's gofeed, see docs at https://pkg.go.dev/github.com/mmcdole/gofeed
-func webget(userAgent string, href string) (*gofeed.Feed, error) {
-	// NOTE: I'm assuming only http, https at this point, later this will
-	// need to be split up so I can handle Gopher, Gemini and sftp.
-	client := &http.Client{
-		CheckRedirect: redirectHandler,
+// feedCache holds the cache headers saved from the last harvest of a feed
+// so the next request can be conditional.
+type feedCache struct {
+	// ETag holds the ETag header returned with the feed
+	ETag string
+	// LastModified holds the Last-Modified header returned with the feed
+	LastModified string
+	// DiscoveredURL holds the feed the headers came from when the link is
+	// an HTML page, see webgetDiscovered
+	DiscoveredURL string
+}
+
+// feedResponse holds the result of retrieving a feed. When the server
+// reports the feed has not changed since the last harvest NotModified is
+// true and Feed is nil.
+type feedResponse struct {
+	// Feed holds the parsed feed
+	Feed *gofeed.Feed
+	// NotModified is true when the server responded with 304 Not Modified
+	NotModified bool
+	// ETag holds the ETag header returned by the server
+	ETag string
+	// LastModified holds the Last-Modified header returned by the server
+	LastModified string
+	// StatusCode holds the HTTP status code of the response
+	StatusCode int
+	// Schedule holds the hints from the feed and server about when to
+	// harvest the feed again
+	Schedule feedSchedule
+	// MovedTo holds the feed's new URL when every redirect followed
+	// was permanent (301 or 308)
+	MovedTo string
+	// DiscoveredURL holds the feed URL found in an HTML page when the
+	// harvested link pointed at a web page instead of a feed
+	DiscoveredURL string
+}
+
+// statusError is returned by webget when the server responds with a
+// status other than 200 OK or 304 Not Modified.
+type statusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter holds the wait requested by the server, e.g. with a 429
+	// or 503 response
+	RetryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("http error: %s", e.Status)
+}
+
+// recordFeedHealth saves the outcome of harvesting a feed in the
+// feed_health table. A nil harvestErr records a success along with
+// the number of items saved.
+func recordFeedHealth(db *sql.DB, link Link, statusCode int, itemCount int, harvestErr error) error {
+	attempted := time.Now().Format("2006-01-02 15:04:05")
+	if harvestErr == nil {
+		_, err := db.Exec(SQLRecordFeedSuccess, link.URL, link.Label, attempted, statusCode, itemCount)
+		return err
 	}
+	var sErr *statusError
+	if errors.As(harvestErr, &sErr) {
+		statusCode = sErr.StatusCode
+	}
+	_, err := db.Exec(SQLRecordFeedFailure, link.URL, link.Label, attempted, statusCode, harvestErr.Error())
+	return err
+}
+
+// webget retrieves a feed using client and parses it. If the cache's ETag
+// or LastModified are provided they are sent as If-None-Match and
+// If-Modified-Since so the server can skip sending an unchanged feed. When
+// href is an HTML page the feeds it links to are tried in order, see
+// discoverFeedLinks. The page itself is always retrieved, the cache
+// headers are only sent to the feed they came from.
+// Uses mmcdole's gofeed, see docs at https://pkg.go.dev/github.com/mmcdole/gofeed
+func webget(client *http.Client, userAgent string, href string, cache feedCache) (*feedResponse, error) {
+	return webgetFeed(client, userAgent, href, cache, true)
+}
+
+// newFeedRequest builds the GET request used to retrieve a feed.
+func newFeedRequest(userAgent string, href string, etag string, lastModified string) (*http.Request, error) {
 	req, err := http.NewRequest("GET", href, nil)
 	if err != nil {
 		return nil, err
@@ -152,36 +588,104 @@ func webget(userAgent string, href string) (*gofeed.Feed, error) {
 	}
 	// Set the accepted content types.
 	req.Header.Set("accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml, application/json;q=0.9, */*;q=0.8")
+	// Make this a conditional request if we've seen the feed before
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return req, nil
+}
+
+// webgetFeed does the work of webget. Feed discovery is only attempted
+// when discover is true so a discovered link is never followed further.
+func webgetFeed(client *http.Client, userAgent string, href string, cache feedCache, discover bool) (*feedResponse, error) {
+	etag, lastModified := cache.ETag, cache.LastModified
+	if discover && cache.DiscoveredURL != "" {
+		// The cache headers belong to the feed discovered in the page at
+		// href, the page is retrieved without them
+		etag, lastModified = "", ""
+	}
+	req, err := newFeedRequest(userAgent, href, etag, lastModified)
+	if err != nil {
+		return nil, err
+	}
 	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+	maxAge, retryAfter := parseCacheHeaders(res.Header, time.Now())
+	movedTo := movedPermanently(res)
+	if res.StatusCode == http.StatusNotModified {
+		return &feedResponse{
+			NotModified:  true,
+			ETag:         etag,
+			LastModified: lastModified,
+			StatusCode:   res.StatusCode,
+			Schedule:     feedSchedule{MaxAge: maxAge, RetryAfter: retryAfter},
+			MovedTo:      movedTo,
+		}, nil
+	}
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %s", res.Status)
+		return nil, &statusError{StatusCode: res.StatusCode, Status: res.Status, RetryAfter: retryAfter}
 	}
 	// See if we can clean up some stuff that'll break feed parsing
 	src, err := io.ReadAll(res.Body)
-	if err != err {
+	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-	src = bytes.ReplaceAll(src, []byte(``), []byte(``))
+	if discover && isHTMLDocument(res.Header.Get("Content-Type"), src) {
+		feedRes, err := webgetDiscovered(client, userAgent, href, cache, discoverFeedLinks(src, res.Request.URL))
+		if err != nil {
+			return nil, err
+		}
+		feedRes.MovedTo = movedTo
+		return feedRes, nil
+	}
+	feed, schedule, err := parseFeedSource(href, src)
+	if err != nil {
+		return nil, err
+	}
+	schedule.MaxAge, schedule.RetryAfter = maxAge, retryAfter
+	return &feedResponse{
+		Feed:         feed,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		StatusCode:   res.StatusCode,
+		Schedule:     schedule,
+		MovedTo:      movedTo,
+	}, nil
+}
+
+// parseFeedSource parses the RSS, Atom or JSON feed in src retrieved from
+// href. The scheduling hints found in the feed are returned along with it.
+func parseFeedSource(href string, src []byte) (*gofeed.Feed, feedSchedule, error) {
+	// Vertical tabs aren't allowed in XML, drop them so the feed parses
+	src = bytes.ReplaceAll(src, []byte("\v"), []byte(""))
 	buf := bytes.NewBuffer(src)
 
 	fp := gofeed.NewParser()
 	feed, err := fp.Parse(buf)
 	if err != nil {
-		return nil, fmt.Errorf("feed error for %q, %s", href, err)
+		return nil, feedSchedule{}, fmt.Errorf("feed error for %q, %s", href, err)
 	}
 	if feed.Link == "" || feed.Link == "/" {
-		u, err := url.Parse(href)
-		if err != nil {
-			return nil, err
+		if fName, ok := filePath(href); ok {
+			// A local feed without a link is its own link
+			feed.Link = fileURL(fName)
+		} else {
+			u, err := url.Parse(href)
+			if err != nil {
+				return nil, feedSchedule{}, err
+			}
+			u.Path = "/"
+			feed.Link = u.String()
 		}
-		u.Path = "/"
-		feed.Link = u.String()
 	}
-	return feed, nil
+	parseSourceNamespace(src, feed)
+	return feed, feedScheduleFromSource(src, feed), nil
 }
 
 // saveChannel will write the Channel information to a skimmer channel table.
@@ -236,6 +740,14 @@ func saveChannel(db *sql.DB, link string, feedLabel string, channel *gofeed.Feed
 	if err != nil {
 		return fmt.Errorf("%s\nstmt: %s", err, stmt)
 	}
+	accounts, likes, blogroll, err := sourceChannel(channel)
+	if err != nil {
+		return err
+	}
+	stmt = SQLUpdateChannelSource
+	if _, err := db.Exec(stmt, accounts, likes, blogroll, link); err != nil {
+		return fmt.Errorf("%s\nstmt: %s", err, stmt)
+	}
 	return nil
 }
 
@@ -288,20 +800,15 @@ func saveItem(db *sql.DB, feedLabel string, channel string, status string, item
 			return fmt.Errorf("failed to marshal item.Categories, %s", err)
 		}
 	}
-	// FIXME: Need to find a feed that uses the source:markdown name space to verify this is how gofeed
-	// handled it!
+	// parseFeedSource saves the source namespace elements under the
+	// "source" extensions with source:markdown as published.
 	sourceMarkdown := ""
-	// NOTE: Need to look for source:markdown and get it populated.
-	if item.Extensions != nil {
-		if source, ok := item.Extensions["source"]; ok && len(source) > 0 {
-			if data, ok := source["markdown"]; ok {
-				for _, val := range data {
-					if val.Name == "markdown" {
-						sourceMarkdown = val.Value
-					}
-				}
-			}
-		}
+	if e, ok := sourceValue(item.Extensions, "markdown"); ok {
+		sourceMarkdown = e.Value
+	}
+	outline, err := sourceOutline(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal source:outline, %s", err)
 	}
 	// Check to see if we have sourceMarkdown set or if I want to try converting the description field.
 	converter := html2md.NewConverter("", true, nil)
@@ -324,5 +831,9 @@ func saveItem(db *sql.DB, feedLabel string, channel string, status string, item
 		string(categories)); err != nil {
 		return fmt.Errorf("%s\nstmt: %s", err, stmt)
 	}
+	stmt = SQLUpdateItemSourceOutline
+	if _, err := db.Exec(stmt, outline, item.Link); err != nil {
+		return fmt.Errorf("%s\nstmt: %s", err, stmt)
+	}
 	return nil
 }
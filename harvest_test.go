@@ -0,0 +1,536 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testFeedRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <link>https://example.org/</link>
+    <description>A test feed</description>
+    <item>
+      <title>First post</title>
+      <link>https://example.org/first</link>
+      <description>Hello World</description>
+      <pubDate>Mon, 02 Jan 2026 15:04:05 -0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+func TestWebgetConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2026 15:04:05 GMT"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testFeedRSS))
+	}))
+	defer ts.Close()
+
+	res, err := webget(ts.Client(), "", ts.URL, feedCache{})
+	if err != nil {
+		t.Fatalf("webget: %s", err)
+	}
+	if res.NotModified {
+		t.Fatalf("expected a full response on the first request")
+	}
+	if res.Feed == nil || len(res.Feed.Items) != 1 {
+		t.Fatalf("expected one parsed item, got %+v", res.Feed)
+	}
+	if res.ETag != etag || res.LastModified != lastModified {
+		t.Errorf("expected cache headers %q, %q, got %q, %q", etag, lastModified, res.ETag, res.LastModified)
+	}
+
+	res, err = webget(ts.Client(), "", ts.URL, feedCache{ETag: res.ETag, LastModified: res.LastModified})
+	if err != nil {
+		t.Fatalf("webget (conditional): %s", err)
+	}
+	if !res.NotModified {
+		t.Errorf("expected NotModified on the conditional request")
+	}
+	if res.Feed != nil {
+		t.Errorf("expected no feed to be parsed for a 304 response")
+	}
+}
+
+func TestParseFeedSourceVerticalTab(t *testing.T) {
+	src := strings.Replace(testFeedRSS, "Hello World", "Hello\vWorld", 1)
+	feed, _, err := parseFeedSource("https://example.org/feed.xml", []byte(src))
+	if err != nil {
+		t.Fatalf("expected a feed with a vertical tab to parse, %s", err)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Description != "HelloWorld" {
+		t.Errorf("expected the vertical tab dropped, got %+v", feed.Items)
+	}
+}
+
+func TestUpgradeDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory db: %s", err)
+	}
+	defer db.Close()
+	// An older channels table without the cache header columns
+	if _, err := db.Exec(`CREATE TABLE channels (link PRIMARY KEY, title TEXT)`); err != nil {
+		t.Fatalf("create channels table: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := upgradeDatabase(db); err != nil {
+			t.Fatalf("upgradeDatabase (pass %d): %s", i+1, err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO channels (link, title) VALUES ('https://example.org/feed.xml', 'Example')`); err != nil {
+		t.Fatalf("insert channel: %s", err)
+	}
+	if _, err := db.Exec(SQLUpdateChannelCacheHeaders, `"v1"`, "yesterday", "https://example.org/rss.xml", "https://example.org/feed.xml"); err != nil {
+		t.Fatalf("update cache headers: %s", err)
+	}
+	var etag, lastModified, discoveredURL string
+	if err := db.QueryRow(SQLChannelCacheHeaders, "https://example.org/feed.xml").Scan(&etag, &lastModified, &discoveredURL); err != nil {
+		t.Fatalf("read cache headers: %s", err)
+	}
+	if etag != `"v1"` || lastModified != "yesterday" || discoveredURL != "https://example.org/rss.xml" {
+		t.Errorf("expected saved cache headers, got %q, %q, %q", etag, lastModified, discoveredURL)
+	}
+}
+
+// baselineSchema is the collection schema of antenna before the columns
+// in schemaColumns were added.
+const baselineSchema = `CREATE TABLE channels (
+	link PRIMARY KEY, title TEXT, description TEXT, feed_link TEXT, links JSON,
+	updated DATETIME, published DATETIME, authors JSON, language TEXT,
+	copyright TEXT, generator TEXT, categories JSON, feed_type TEXT,
+	feed_version TEXT
+);
+CREATE TABLE items (
+	link PRIMARY KEY, postPath TEXT DEFAULT '', title TEXT, description TEXT,
+	authors JSON, enclosures JSON DEFAULT '', guid TEXT, pubDate DATETIME,
+	dcExt JSON, channel TEXT, sourceMarkdown TEXT DEFUALT '',
+	status TEXT DEFAULT '', label TEXT DEFAULT '', updated DATETIME,
+	categories JSON DEFAULT ''
+);
+CREATE TABLE pages (
+	inputPath PRIMARY KEY, outputPath TEXT DEFAULT '', updated DATETIME
+);`
+
+func TestBaselineDatabase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, src := range map[string]string{
+		"page.yaml": DefaultGeneratorYaml,
+		"blog.md":   "# Blog\n",
+		"hello.md":  "---\ntitle: Hello\npubDate: \"2025-09-01\"\n---\n\n# Hello\n",
+		"antenna.yaml": `base_url: https://example.com
+htdocs: htdocs
+generator: page.yaml
+collections:
+  - file: blog.md
+    dbName: blog.db
+    generator: page.yaml
+`,
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll("htdocs", 0755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", "blog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, authors, enclosures, guid, pubDate, dcExt,
+		channel, status, updated, label, postPath, sourceMarkdown)
+		VALUES ('https://example.com/first.html', 'First', 'First', '', '', 'https://example.com/first.html',
+		'2025-08-01', '', '', 'published', '2025-08-01', '', 'first.md', '# First')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	app := &AntennaApp{appName: "antenna"}
+	eout := new(strings.Builder)
+	if err := app.Generate(io.Discard, eout, "antenna.yaml", nil); err != nil {
+		t.Fatalf("generate with a baseline database: %s", err)
+	}
+	if strings.Contains(eout.String(), "no such column") {
+		t.Errorf("generate with a baseline database:\n%s", eout)
+	}
+	cfg := &AppConfig{}
+	if err := cfg.LoadConfig("antenna.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Post("blog.md", "hello.md"); err != nil {
+		t.Fatalf("post with a baseline database: %s", err)
+	}
+	for _, fName := range []string{"blog.html", "first.html", "hello.html"} {
+		if _, err := os.Stat(filepath.Join("htdocs", fName)); err != nil {
+			t.Errorf("expected %s, %s", fName, err)
+		}
+	}
+}
+
+func TestHarvestLimits(t *testing.T) {
+	col := &Collection{}
+	concurrency, hostConcurrency, timeout := col.harvestLimits(nil)
+	if concurrency != defaultHarvestConcurrency || hostConcurrency != defaultHostConcurrency || timeout != defaultHarvestTimeout*time.Second {
+		t.Errorf("expected defaults, got %d, %d, %s", concurrency, hostConcurrency, timeout)
+	}
+	cfg := &AppConfig{Concurrency: 8, HostConcurrency: 3, Timeout: 10}
+	col.Concurrency = 2
+	concurrency, hostConcurrency, timeout = col.harvestLimits(cfg)
+	if concurrency != 2 || hostConcurrency != 3 || timeout != 10*time.Second {
+		t.Errorf("expected collection to override antenna.yaml, got %d, %d, %s", concurrency, hostConcurrency, timeout)
+	}
+}
+
+func TestCollectionHarvestConcurrent(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(strings.ReplaceAll(testFeedRSS, "https://example.org/first", "https://example.org"+r.URL.Path)))
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "concurrent.md")
+	src := []string{"# Concurrent", ""}
+	for i := 0; i < 6; i++ {
+		src = append(src, fmt.Sprintf("- [Feed %d](%s/feed%d.xml)", i, ts.URL, i))
+	}
+	if err := os.WriteFile(cName, []byte(strings.Join(src, "\n")), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{
+		File:            cName,
+		DbName:          filepath.Join(dName, "concurrent.db"),
+		Concurrency:     4,
+		HostConcurrency: 2,
+	}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, nil); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests in flight for one host, got %d", maxInFlight)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	count := 0
+	if err := db.QueryRow(SQLItemCount).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 6 {
+		t.Errorf("expected 6 harvested items, got %d", count)
+	}
+}
+
+func TestHarvestRecordsFeedHealth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testFeedRSS))
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "health.md")
+	src := fmt.Sprintf("# Health\n\n- [Good](%s/feed.xml)\n- [Missing](%s/missing.xml)\n", ts.URL, ts.URL)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "health.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, nil); err != nil {
+			t.Fatalf("Harvest: %s", err)
+		}
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	healthList, err := feedHealthFromDB(db, []Link{
+		{Label: "Good", URL: ts.URL + "/feed.xml"},
+		{Label: "Missing", URL: ts.URL + "/missing.xml"},
+		{Label: "Unknown", URL: ts.URL + "/unknown.xml"},
+	})
+	if err != nil {
+		t.Fatalf("feedHealthFromDB: %s", err)
+	}
+	good, missing, unknown := healthList[0], healthList[1], healthList[2]
+	if good.StatusCode != 200 || good.Failures != 0 || good.ItemCount != 1 || good.LastSuccess == "" {
+		t.Errorf("unexpected health for good feed: %+v", good)
+	}
+	if missing.StatusCode != 404 || missing.Failures != 2 || missing.LastSuccess != "" || missing.Error == "" {
+		t.Errorf("unexpected health for missing feed: %+v", missing)
+	}
+	if unknown.LastAttempt != "" || !strings.Contains(unknown.String(), "never harvested") {
+		t.Errorf("unexpected health for unknown feed: %+v", unknown)
+	}
+}
+
+func TestHarvestRewriteMoved(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old.xml":
+			http.Redirect(w, r, "/new.xml", http.StatusMovedPermanently)
+		case "/temp.xml":
+			http.Redirect(w, r, "/new.xml", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(testFeedRSS))
+		}
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "moved.md")
+	src := fmt.Sprintf("# Moved\n\n- [Old](%s/old.xml \"moved feed\")\n- [Temp](%s/temp.xml)\n", ts.URL, ts.URL)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "moved.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	// Without --rewrite-moved the move is only recorded
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	healthList, err := feedHealthFromDB(db, []Link{{URL: ts.URL + "/old.xml"}, {URL: ts.URL + "/temp.xml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healthList[0].MovedTo != ts.URL+"/new.xml" {
+		t.Errorf("expected old.xml to be recorded as moved, got %q", healthList[0].MovedTo)
+	}
+	if healthList[1].MovedTo != "" {
+		t.Errorf("expected a temporary redirect not to be recorded as moved, got %q", healthList[1].MovedTo)
+	}
+
+	if err := col.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true, RewriteMoved: true}); err != nil {
+		t.Fatalf("Harvest (rewrite moved): %s", err)
+	}
+	updated, err := os.ReadFile(cName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("# Moved\n\n- [Old](%s/new.xml \"moved feed\")\n- [Temp](%s/temp.xml)\n", ts.URL, ts.URL)
+	if string(updated) != expected {
+		t.Errorf("expected rewritten collection\n%s\ngot\n%s", expected, updated)
+	}
+	if _, err := os.Stat(cName + ".bak"); err != nil {
+		t.Errorf("expected a backup of the collection, %s", err)
+	}
+	var channel string
+	if err := db.QueryRow(`SELECT channel FROM items LIMIT 1`).Scan(&channel); err != nil {
+		t.Fatal(err)
+	}
+	if channel == ts.URL+"/old.xml" {
+		t.Errorf("expected items to move to the new feed URL")
+	}
+}
+
+func TestWebgetDiscoversFeed(t *testing.T) {
+	feedRequests := 0
+	// conditional lists the requests sent with cache headers
+	conditional := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditional = append(conditional, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/":
+			if r.Header.Get("If-Modified-Since") != "" {
+				// The page hasn't changed since the feed was
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<!DOCTYPE html>
+<html><head>
+<link rel="stylesheet" href="/site.css">
+<link rel="alternate" type="application/atom+xml" href="/missing.xml" title="Gone">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml" title="Site RSS">
+</head><body><p>Home page</p></body></html>`))
+		case "/feed.xml":
+			feedRequests++
+			if r.Header.Get("If-None-Match") == `"feed-v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Header().Set("ETag", `"feed-v1"`)
+			w.Header().Set("Last-Modified", "Mon, 01 Sep 2025 00:00:00 GMT")
+			w.Write([]byte(testFeedRSS))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	res, err := webget(ts.Client(), "", ts.URL+"/", feedCache{})
+	if err != nil {
+		t.Fatalf("webget: %s", err)
+	}
+	if res.DiscoveredURL != ts.URL+"/feed.xml" {
+		t.Errorf("expected the RSS link to be used, got %q", res.DiscoveredURL)
+	}
+	if res.Feed == nil || res.Feed.Title != "Test Feed" {
+		t.Errorf("expected the discovered feed to be parsed, got %+v", res.Feed)
+	}
+	if res.ETag != `"feed-v1"` {
+		t.Errorf("expected the discovered feed's ETag, got %q", res.ETag)
+	}
+	// The saved validators make only the discovered feed's request
+	// conditional
+	res, err = webget(ts.Client(), "", ts.URL+"/", feedCache{ETag: res.ETag, LastModified: res.LastModified, DiscoveredURL: res.DiscoveredURL})
+	if err != nil {
+		t.Fatalf("webget: %s", err)
+	}
+	if !res.NotModified || res.ETag != `"feed-v1"` || res.DiscoveredURL != ts.URL+"/feed.xml" {
+		t.Errorf("expected the discovered feed to be unchanged, got %+v", res)
+	}
+	if strings.Join(conditional, ",") != "/feed.xml" {
+		t.Errorf("expected only the discovered feed to get the cache headers, got %q", conditional)
+	}
+
+	var out, eout strings.Builder
+	if err := discoverFeeds(&out, &eout, ts.Client(), "", ts.URL+"/"); err != nil {
+		t.Fatalf("discoverFeeds: %s", err)
+	}
+	links, _ := ParseMarkdownLinks(out.String())
+	if len(links) != 1 || links[0].Label != "Test Feed" || links[0].URL != ts.URL+"/feed.xml" || links[0].Description != "A test feed" {
+		t.Errorf("expected a Markdown link to the discovered feed, got %q", out.String())
+	}
+	if !strings.Contains(eout.String(), "missing.xml") {
+		t.Errorf("expected a warning for the missing feed, got %q", eout.String())
+	}
+
+	// A feed URL is described from the first response
+	feedRequests = 0
+	out.Reset()
+	if err := discoverFeeds(&out, &eout, ts.Client(), "", ts.URL+"/feed.xml"); err != nil {
+		t.Fatalf("discoverFeeds: %s", err)
+	}
+	links, _ = ParseMarkdownLinks(out.String())
+	if len(links) != 1 || links[0].Label != "Test Feed" || links[0].URL != ts.URL+"/feed.xml" {
+		t.Errorf("expected a Markdown link to the feed, got %q", out.String())
+	}
+	if feedRequests != 1 {
+		t.Errorf("expected the feed to be retrieved once, got %d requests", feedRequests)
+	}
+}
+
+func TestHarvestDiscoveredFeedCache(t *testing.T) {
+	pageConditional := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+				pageConditional = true
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="/feed.xml"></head></html>`))
+		case "/feed.xml":
+			if r.Header.Get("If-None-Match") == `"feed-v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Header().Set("ETag", `"feed-v1"`)
+			w.Write([]byte(testFeedRSS))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "site.md")
+	if err := os.WriteFile(cName, []byte(fmt.Sprintf("# Site\n\n- [Site](%s/)\n", ts.URL)), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "site.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		var out, eout strings.Builder
+		if err := col.Harvest(&out, &eout, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+			t.Fatalf("Harvest (pass %d): %s", i+1, err)
+		}
+		if i == 1 && !strings.Contains(out.String(), "unchanged Site") {
+			t.Errorf("expected the discovered feed to be unchanged, got %s%s", out.String(), eout.String())
+		}
+	}
+	if pageConditional {
+		t.Errorf("expected the page to be retrieved without the feed's cache headers")
+	}
+}
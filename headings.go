@@ -0,0 +1,338 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	// 3rd Party Packages
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+ * Headings of posts and pages get an id made from their text so they can
+ * be linked to, "## What's new?" becomes <h2 id="whats-new">. An id used
+ * earlier in the document gets a number added, "whats-new-1". The front
+ * matter option `toc: true` puts a table of contents of the headings that
+ * follow it at the top of the document, after a leading h1. A paragraph
+ * holding only `[[toc]]` puts it there instead.
+ */
+
+const (
+	// tocMarker is the paragraph replaced by the table of contents
+	tocMarker = "[[toc]]"
+	// tocDepth is the number of heading levels the table of contents lists
+	tocDepth = 3
+)
+
+var (
+	// kindTOC is the kind of the table of contents node
+	kindTOC = ast.NewNodeKind("TOC")
+
+	// tagRegExp matches the tags of raw HTML in a heading
+	tagRegExp = regexp.MustCompile(`<[^>]*>`)
+)
+
+// tocBlock is where the table of contents is written
+type tocBlock struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node
+func (n *tocBlock) Kind() ast.NodeKind {
+	return kindTOC
+}
+
+// Dump implements ast.Node
+func (n *tocBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// headingSlug returns the id for a heading's text. Letters and digits are
+// lower cased, the runs of anything else between them become a dash.
+func headingSlug(s string) string {
+	slug := new(strings.Builder)
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && slug.Len() > 0 {
+				slug.WriteRune('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		case r == '\'' || r == '’':
+			// apostrophes don't split words, "What's" is "whats"
+		default:
+			dash = true
+		}
+	}
+	if slug.Len() == 0 {
+		return "section"
+	}
+	return slug.String()
+}
+
+// headingText returns the plain text of a heading
+func headingText(n ast.Node, source []byte) string {
+	buf := new(strings.Builder)
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			buf.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(c.Value)
+		case *ast.RawHTML:
+			// tags written in the heading aren't part of its text
+		case *luaRawInline:
+			buf.WriteString(html.UnescapeString(tagRegExp.ReplaceAllString(c.HTML, "")))
+		default:
+			buf.WriteString(headingText(c, source))
+		}
+	}
+	return buf.String()
+}
+
+// attributeString returns the value of n's attribute name, "" when it
+// isn't set.
+func attributeString(n ast.Node, name string) string {
+	v, ok := n.AttributeString(name)
+	if !ok {
+		return ""
+	}
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// setHeadingIDs gives the headings of doc without an id one made from
+// their text, unique among the ids already in doc.
+func setHeadingIDs(doc ast.Node, source []byte) {
+	used := map[string]bool{}
+	headings := []*ast.Heading{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if id := attributeString(n, "id"); id != "" {
+			used[id] = true
+		} else if h, ok := n.(*ast.Heading); ok {
+			headings = append(headings, h)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, h := range headings {
+		slug := headingSlug(headingText(h, source))
+		id := slug
+		for i := 1; used[id]; i++ {
+			id = fmt.Sprintf("%s-%d", slug, i)
+		}
+		used[id] = true
+		h.SetAttributeString("id", []byte(id))
+	}
+}
+
+// isTOCMarker reports if n is a paragraph holding only the [[toc]] marker
+func isTOCMarker(n ast.Node, source []byte) bool {
+	p, ok := n.(*ast.Paragraph)
+	if !ok || p.Lines().Len() != 1 {
+		return false
+	}
+	line := p.Lines().At(0)
+	return strings.TrimSpace(string(line.Value(source))) == tocMarker
+}
+
+// headingTransformer sets the heading ids when the document is parsed, so
+// cm_filters see them as the identifier of a Header, and puts the table of
+// contents in place.
+type headingTransformer struct {
+	toc bool
+}
+
+// Transform implements parser.ASTTransformer
+func (t *headingTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	setHeadingIDs(doc, source)
+	markers := []ast.Node{}
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if isTOCMarker(c, source) {
+			markers = append(markers, c)
+		}
+	}
+	for _, marker := range markers {
+		doc.ReplaceChild(doc, marker, &tocBlock{})
+	}
+	if t.toc && len(markers) == 0 {
+		first := doc.FirstChild()
+		if h, ok := first.(*ast.Heading); ok && h.Level == 1 {
+			doc.InsertAfter(doc, h, &tocBlock{})
+		} else {
+			doc.InsertBefore(doc, first, &tocBlock{})
+		}
+	}
+}
+
+// headingRenderer writes headings with their self link anchors and the
+// table of contents.
+type headingRenderer struct {
+	anchors bool
+	// root is the document the heading ids were last checked in
+	root ast.Node
+}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *headingRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(kindTOC, r.renderTOC)
+}
+
+// checkIDs gives an id to the headings a cm_filter added without one
+func (r *headingRenderer) checkIDs(n ast.Node, source []byte) {
+	root := n
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	if root != r.root {
+		r.root = root
+		setHeadingIDs(root, source)
+	}
+}
+
+func (r *headingRenderer) renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	r.checkIDs(node, source)
+	n := node.(*ast.Heading)
+	if entering {
+		fmt.Fprintf(w, "<h%d", n.Level)
+		if n.Attributes() != nil {
+			gmhtml.RenderAttributes(w, node, gmhtml.HeadingAttributeFilter)
+		}
+		w.WriteByte('>')
+		return ast.WalkContinue, nil
+	}
+	if id := attributeString(n, "id"); r.anchors && id != "" {
+		fmt.Fprintf(w, ` <a class="heading-anchor" href="#%s" aria-label="Link to this section: %s">#</a>`,
+			html.EscapeString(id), html.EscapeString(strings.TrimSpace(headingText(n, source))))
+	}
+	fmt.Fprintf(w, "</h%d>\n", n.Level)
+	return ast.WalkContinue, nil
+}
+
+// tocEntry is a heading listed in the table of contents
+type tocEntry struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// tocEntries returns the headings following the table of contents node n
+// that are within tocDepth levels of the highest of them.
+func tocEntries(n ast.Node, source []byte) []*tocEntry {
+	entries := []*tocEntry{}
+	top := 0
+	for c := n.NextSibling(); c != nil; c = c.NextSibling() {
+		h, ok := c.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		entries = append(entries, &tocEntry{
+			Level: h.Level,
+			ID:    attributeString(h, "id"),
+			Text:  strings.TrimSpace(headingText(h, source)),
+		})
+		if top == 0 || h.Level < top {
+			top = h.Level
+		}
+	}
+	listed := []*tocEntry{}
+	for _, entry := range entries {
+		if entry.Level < top+tocDepth && entry.ID != "" {
+			listed = append(listed, entry)
+		}
+	}
+	return listed
+}
+
+func (r *headingRenderer) renderTOC(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	r.checkIDs(node, source)
+	entries := tocEntries(node, source)
+	if len(entries) == 0 {
+		return ast.WalkSkipChildren, nil
+	}
+	w.WriteString("<nav aria-label=\"Table of contents\" class=\"toc\">\n")
+	// levels holds the heading level of each open list
+	levels := []int{}
+	for _, entry := range entries {
+		if len(levels) == 0 || entry.Level > levels[len(levels)-1] {
+			w.WriteString("<ul>\n")
+			levels = append(levels, entry.Level)
+		} else {
+			for len(levels) > 1 && entry.Level < levels[len(levels)-1] && entry.Level <= levels[len(levels)-2] {
+				w.WriteString("</li>\n</ul>\n")
+				levels = levels[:len(levels)-1]
+			}
+			w.WriteString("</li>\n")
+		}
+		fmt.Fprintf(w, "<li><a href=\"#%s\">%s</a>", html.EscapeString(entry.ID), html.EscapeString(entry.Text))
+	}
+	for range levels {
+		w.WriteString("</li>\n</ul>\n")
+	}
+	w.WriteString("</nav>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// headings is the goldmark extension giving headings ids, self link
+// anchors and a table of contents.
+type headings struct {
+	anchors bool
+	toc     bool
+}
+
+// Extend implements goldmark.Extender
+func (e *headings) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(&headingTransformer{toc: e.toc}, 100)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&headingRenderer{anchors: e.anchors}, 100)))
+}
+
+// useHeadings sets the heading options of a post or page, heading ids
+// always, the anchors from heading_anchors, the table of contents from
+// the front matter's toc.
+func (gen *Generator) useHeadings(doc *CommonMark) {
+	doc.UseHeadingIDs(true)
+	doc.UseHeadingAnchors(doc.GetAttributeBool("heading_anchors", gen.HeadingAnchors))
+	doc.UseTOC(doc.GetAttributeBool("toc", false))
+}
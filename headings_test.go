@@ -0,0 +1,146 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadingSlug(t *testing.T) {
+	for text, expected := range map[string]string{
+		"Hello World":        "hello-world",
+		"What’s new, today?": "whats-new-today",
+		"  C++ & Go  ":       "c-go",
+		"Année 2025":         "année-2025",
+		"???":                "section",
+	} {
+		if got := headingSlug(text); got != expected {
+			t.Errorf("headingSlug(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
+
+func TestHeadingIDs(t *testing.T) {
+	doc := &CommonMark{Text: "# Notes\n\n## Setup *today*\n\n## Setup today\n\n### Notes\n"}
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(src, "id=") {
+		t.Errorf("expected no heading ids unless asked for\n%s", src)
+	}
+	doc.UseHeadingIDs(true)
+	if src, err = doc.ToHTML(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<h1 id="notes">Notes</h1>`,
+		`<h2 id="setup-today">Setup <em>today</em></h2>`,
+		`<h2 id="setup-today-1">Setup today</h2>`,
+		`<h3 id="notes-1">Notes</h3>`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+	doc.UseHeadingAnchors(true)
+	if src, err = doc.ToHTML(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<h1 id="notes">Notes <a class="heading-anchor" href="#notes" aria-label="Link to this section: Notes">#</a></h1>`
+	if !strings.Contains(src, expected) {
+		t.Errorf("expected %q in\n%s", expected, src)
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	text := "# Guide\n\nIntro.\n\n## Install\n\n### Linux\n\n### macOS\n\n## Use & enjoy\n\n##### Too deep\n"
+	doc := &CommonMark{Text: text}
+	doc.UseHeadingIDs(true)
+	doc.UseTOC(true)
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<h1 id="guide">Guide</h1>
+<nav aria-label="Table of contents" class="toc">
+<ul>
+<li><a href="#install">Install</a><ul>
+<li><a href="#linux">Linux</a></li>
+<li><a href="#macos">macOS</a></li>
+</ul>
+</li>
+<li><a href="#use-enjoy">Use &amp; enjoy</a></li>
+</ul>
+</nav>
+<p>Intro.</p>`
+	if !strings.Contains(src, expected) {
+		t.Errorf("expected the table of contents after the title\n%s\ngot\n%s", expected, src)
+	}
+
+	// The [[toc]] marker places it, toc: true isn't needed
+	doc = &CommonMark{Text: "Intro.\n\n[[toc]]\n\n## One\n\n## Two\n"}
+	doc.UseHeadingIDs(true)
+	if src, err = doc.ToHTML(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "<p>Intro.</p>\n<nav aria-label=\"Table of contents\"") || strings.Contains(src, tocMarker) {
+		t.Errorf("expected the table of contents in place of the marker\n%s", src)
+	}
+
+	// Without heading ids the marker is left as written
+	doc = &CommonMark{Text: "[[toc]]\n\n## One\n"}
+	if src, err = doc.ToHTML(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(src, "<p>[[toc]]</p>") {
+		t.Errorf("expected the marker as text\n%s", src)
+	}
+}
+
+func TestHeadingsWithFilters(t *testing.T) {
+	fName := writeTestFilter(t, `
+function Header(h)
+  if h.level == 2 then
+    h.content = pandoc.utils.stringify(h) .. " (" .. h.identifier .. ")"
+    return h
+  end
+  if h.level == 3 then
+    return {h, pandoc.Header(3, "Added")}
+  end
+end
+`)
+	doc := &CommonMark{Text: "[[toc]]\n\n## First\n\n### Added\n"}
+	doc.UseFilters([]string{fName})
+	doc.UseHeadingIDs(true)
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<h2 id="first">First (first)</h2>`,
+		`<h3 id="added">Added</h3>`,
+		`<h3 id="added-1">Added</h3>`,
+		`<li><a href="#added-1">Added</a>`,
+		`<li><a href="#first">First (first)</a>`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+}
@@ -41,6 +41,8 @@ Commands:
   blogit       Add a post using an automatic date-based directory path
   css          Generate a default CSS stylesheet and patch page.yaml
   del          Remove a collection from the configuration
+  discover     Find the feeds linked from a web page
+  feeds        List the feeds in a collection with their harvest health
   generate     Render HTML pages and RSS feeds for all (or one) collection
   harvest      Fetch content from remote feeds into collection databases
   init         Initialize antenna configuration files
@@ -52,6 +54,7 @@ Commands:
   post         Add or update a blog post in a collection
   posts        List posts in a collection (with optional count or date range)
   preview      Serve the site on localhost for browser review
+  prune        Remove harvested items per the collection's retention policy
   quote        Convert a text-fragment URL into a Markdown excerpt
   rss          Generate an RSS feed file from posts in a collection
   sitemap      Generate sitemap XML index files
@@ -105,6 +108,12 @@ func PrintHelpTopic(w io.Writer, topic, appName, version, releaseDate, releaseHa
 		text = CssHelpText
 	case "del":
 		text = DelHelpText
+	case "discover":
+		text = DiscoverHelpText
+	case "feeds":
+		text = FeedsHelpText
+	case "prune":
+		text = PruneHelpText
 	case "generate", "build":
 		text = GenerateHelpText
 	case "harvest", "fetch":
@@ -29,7 +29,12 @@ var (
 
 {app_name} [OPTIONS] ACTION [PARAMETERS]
 
-{app_name} help [TOPIC]
+{app_name} prune [--dry-run] COLLECTION_NAME
+: Remove the harvested items the collection's retention policy in antenna.yaml
+no longer keeps. Posts (items with a postPath) are never removed. With --dry-run
+the items are listed but not removed. Harvest applies the policy too.
+
+help [TOPIC]
 
 # DESCRIPTION
 
@@ -170,14 +175,27 @@ the "quote" action is to simply quoting another site for use in a post.
 
 The following commands are related to producing a link blog static website.
 
-harvest [COLLECTION_NAME]
+harvest [--force] [--rewrite-moved] [COLLECTION_NAME]
 : The harvest retrieves feed content. If COLLECTION_NAME is provided then only the 
 the single collection will be harvested otherwise all collections defined in your
-Antenna YAML configuration are harvested.
+Antenna YAML configuration are harvested. Feeds that are not due, based on their
+TTL, skip hours, skip days and the server's cache headers, are skipped unless
+--force is given. With --rewrite-moved the links to feeds that have permanently
+moved are updated in the collection's Markdown.
+
+discover URL
+: Find the feeds at URL, either the feed itself or the feeds an HTML page links to
+with <link rel="alternate">, and print a Markdown list line for each one ready to
+paste into a collection.
 
-generate [COLLECTION_NAME]
+feeds COLLECTION_NAME
+: List the feeds in a collection along with their harvest health, the last attempt,
+last success, HTTP status, error text, consecutive failures and item count.
+
+generate [--full] [COLLECTION_NAME]
 : This process the collections rendering HTML pages and RSS 2.0 feeds for each collection.
-If the collection name is provided then only that HTML page will be generated.
+If the collection name is provided then only that HTML page will be generated. Only the
+outputs whose inputs changed since the last run are written unless --full is given.
 
 sitemap
 : This will generate a set of sitemap files for pages and posts found through the
@@ -211,14 +229,15 @@ OUTPUT_PATH. OUTPUT_PATH defaults to "theme/style.css" when omitted. The directo
 is created if it does not exist. This makes it easy to seed a theme stylesheet
 directly from a styled LibreOffice Writer document.
 
-css [CSS_PATH]
+css [--highlight[=STYLE]] [CSS_PATH]
 : Write a comprehensive default stylesheet to CSS_PATH within the htdocs
 directory (default: css/site.css). The stylesheet includes CSS custom properties,
 dark-mode support, a skip-navigation link (WCAG 2.4.1), and styles for all HTML
 structures generated by {app_name}. An existing file is backed up to CSS_PATH.bak.
 After writing the CSS the configured page generator YAML (page.yaml) is patched to
-add a link: entry referencing the new stylesheet. Use 'antenna help css' for full
-details.
+add a link: entry referencing the new stylesheet. With --highlight the syntax
+highlighting stylesheet for fenced code blocks is written instead (default:
+css/highlight.css). Use 'antenna help css' for full details.
 
 items [COLLECTION_NAME]
 : List all items stored in the named collection's SQLite3 database as a Markdown
@@ -594,6 +613,7 @@ css
 # SYNOPSIS
 
 {app_name} css [CSS_PATH]
+{app_name} css --highlight[=STYLE] [CSS_PATH]
 
 # DESCRIPTION
 
@@ -612,10 +632,43 @@ The generated stylesheet includes CSS custom properties for colors, fonts,
 and layout, dark-mode support, skip-navigation link, navigation bar, article
 cards, standalone pages, site footer, and typography.
 
+With --highlight, {app_name} writes the stylesheet for syntax highlighted
+code instead, to css/highlight.css unless CSS_PATH is given, and patches
+page.yaml the same way. It uses the github style, with github-dark for
+readers who prefer a dark color scheme. --highlight=STYLE writes the named
+chroma style alone, e.g. --highlight=monokai.
+
+# CODE HIGHLIGHTING
+
+Fenced code blocks in posts, pages and harvested items are highlighted
+when they are rendered. The language comes from the fence's info string,
+or from the LANG of "@include-code-block FILE LANG", which defaults to the
+language of FILE's name. Tokens are marked with CSS classes inside
+<pre class="chroma">, no inline styles are written. Settings in braces
+after the language add line numbers and highlight lines:
+
+  ~~~go {linenos=true hl_lines=[2,"4-6"] linenostart=10}
+
+linenos
+: true writes a line number before each line
+
+hl_lines
+: lines to highlight, numbers and ranges, [2,"4-6"] or "2 4-6"
+
+linenostart
+: the number of the first line, it turns on line numbers
+
+A code block in a language that isn't known is written as plain
+<pre><code> unless it asks for line numbers or highlighted lines.
+
 # PARAMETERS
 
 CSS_PATH
-: (optional) path relative to htdocs (default: css/site.css)
+: (optional) path relative to htdocs (default: css/site.css, or
+  css/highlight.css with --highlight)
+
+--highlight[=STYLE]
+: write the syntax highlighting stylesheet, optionally in chroma's STYLE
 
 # SEE ALSO
 
@@ -650,6 +703,76 @@ COLLECTION_FILE
 
 {app_name} del feeds/tech.md
 
+`
+
+	DiscoverHelpText = `%{app_name}(7) user manual | version {version} {release_hash}
+% R. S. Doiel
+% {release_date}
+
+# NAME
+
+discover
+
+# SYNOPSIS
+
+{app_name} discover URL
+
+# DESCRIPTION
+
+Retrieves URL and prints a Markdown list line for each feed found there.
+If URL is a feed it is listed itself. If URL is an HTML page the
+<link rel="alternate"> elements with a type of application/rss+xml,
+application/atom+xml or application/feed+json are listed in the order
+they appear. Each feed is retrieved to find its title and description.
+
+The lines use the - [Label](url "description") form read from collection
+files so they can be pasted directly into a collection's Markdown.
+
+# PARAMETERS
+
+URL
+: a web page or feed URL
+
+# EXAMPLES
+
+{app_name} discover https://example.org
+{app_name} discover https://example.org >>feeds/tech.md
+
+`
+
+	FeedsHelpText = `%{app_name}(7) user manual | version {version} {release_hash}
+% R. S. Doiel
+% {release_date}
+
+# NAME
+
+feeds
+
+# SYNOPSIS
+
+{app_name} feeds COLLECTION_NAME
+
+# DESCRIPTION
+
+Prints a Markdown list of the feeds defined in COLLECTION_NAME along with
+the health recorded the last time each feed was harvested. Use it to find
+feeds that are dead, have moved or are broken without reading harvest logs.
+
+Each line shows: - [Label](url), status, last attempt, last success,
+consecutive failures and the number of items saved by the last harvest.
+When the last attempt failed the error text follows on an indented line.
+Feeds that permanently moved show their new URL on an indented line.
+Feeds that have never been harvested are listed as such.
+
+# PARAMETERS
+
+COLLECTION_NAME
+: collection Markdown file
+
+# EXAMPLES
+
+{app_name} feeds pacific.md
+
 `
 
 	GenerateHelpText = `%{app_name}(7) user manual | version {version} {release_hash}
@@ -662,23 +785,67 @@ generate
 
 # SYNOPSIS
 
-{app_name} generate [COLLECTION_NAME]
+{app_name} generate [--full] [COLLECTION_NAME]
 
 # DESCRIPTION
 
 Processes all collections (or only COLLECTION_NAME if provided), rendering
-an HTML page and RSS 2.0 feed for each. Output files are written to the
-htdocs directory configured in antenna.yaml.
+an HTML page and syndication feeds for each. Output files are written to
+the htdocs directory configured in antenna.yaml.
+
+The feeds written are chosen by the collection's formats list in
+antenna.yaml: "rss" for RSS 2.0 (NAME.xml), "atom" for Atom 1.0
+(NAME.atom) and "json" for JSON Feed 1.1 (NAME.json). RSS and JSON Feed
+are written when no formats are listed. The page's head links to the Atom
+feed and JSON Feed with rel="alternate" links of type application/atom+xml
+and application/feed+json. When the collection sets a link for its RSS feed
+the JSON Feed's feed_url and the Atom feed's self link are next to it,
+e.g. a link of https://feeds.example.org/planet.xml gives
+https://feeds.example.org/planet.json and .../planet.atom. RSS guids are no
+longer written as cid://GUID, see 'antenna help rss'.
+
+When a collection sets tag_pages in antenna.yaml, a page and RSS feed are
+written for each tag (category) of its published items, NAME/tags/TAG.html
+and NAME/tags/TAG.xml, along with a tag index, NAME/tags/index.html. Setting
+author_pages writes a page for each author, NAME/authors/AUTHOR.html. NAME
+is the collection's base name so each collection has its own pages. Tags
+and authors are matched without regard to case and TAG and AUTHOR are lower
+cased with spaces and punctuation replaced by dashes. The pages of tags and
+authors no longer found are removed. These pages use the collection's page
+generator YAML.
+
+Setting items.page_size in the page generator YAML splits the aggregate
+page into pages of that many items, NAME.html, NAME-2.html and so on. Each
+page ends with a <nav aria-label="Pagination"> holding the previous and
+next page links and its head has rel="prev" and rel="next" links. Pages
+left over from a run with more items are removed. Setting archive_pages in
+antenna.yaml writes a page for each year and month of the collection's
+items, NAME-archive-YYYY.html and NAME-archive-YYYY-MM.html, with an index,
+NAME-archive.html. Pages of years and months no longer having published items
+are removed.
 
 The HTML structure is controlled by the page generator YAML (page.yaml or
 a per-collection override). Front matter from each item is emitted as
 meta elements in the generated HTML.
 
+Generate keeps a build manifest next to antenna.yaml, antenna.manifest.json,
+recording a hash of the inputs each output was written from: the generator
+YAML, the collection's settings, the source Markdown and the database rows.
+A collection's page and feeds, a post or a page is only written again when
+its inputs changed or the output is missing, so unchanged files keep their
+modification times. Upgrading {app_name} writes everything again. Use --full
+to write every output regardless.
+
 # PARAMETERS
 
 COLLECTION_NAME
 : (optional) process only this collection
 
+# OPTIONS
+
+--full
+: write every output, ignoring the build manifest
+
 # ALIASES
 
 build
@@ -687,6 +854,7 @@ build
 
 {app_name} generate
 {app_name} generate index.md
+{app_name} generate --full
 
 `
 
@@ -700,7 +868,7 @@ harvest
 
 # SYNOPSIS
 
-{app_name} harvest [COLLECTION_NAME]
+{app_name} harvest [--force] [--rewrite-moved] [COLLECTION_NAME]
 
 # DESCRIPTION
 
@@ -708,11 +876,91 @@ Retrieves RSS/Atom feed content for all collections (or only
 COLLECTION_NAME) and stores harvested items in each collection's SQLite3
 database. Run generate afterwards to rebuild the HTML pages.
 
+Collections may list http, https, gemini and gopher URLs. Gemini links can
+point at a subscription page (a gemlog whose link lines start with a
+YYYY-MM-DD date) or at an Atom or RSS feed. The certificate a Gemini host
+presents the first time it is harvested is pinned in the collection's
+tofu_pins table, a different certificate is refused until the pinned one
+expires. Gopher links can point at a phlog menu (entries starting with a
+YYYY-MM-DD date become items) or at an Atom or RSS file.
+
+Feeds written to disk by other tools can be listed with a file:// URL or a
+plain path, relative paths are read from the collection file's directory.
+Both file://feeds/digest.xml and file:feeds/digest.xml are relative paths,
+file:///C:/feeds/digest.xml and C:/feeds/digest.xml are on a Windows drive.
+A path to a directory is read as a feed where each Markdown file with front
+matter (title, link, description, pubDate, dateModified, author, categories)
+is an item. A local source is skipped as unchanged when its modification
+time hasn't changed since the last harvest.
+
+The ETag and Last-Modified headers returned for each feed are saved in the
+channels table. The next harvest sends them back as If-None-Match and
+If-Modified-Since. Feeds the server reports as unchanged (304 Not Modified)
+are skipped and counted in the summary printed for each collection.
+
+The outcome of each feed is recorded in the collection's feed_health table,
+see 'antenna help feeds'.
+
+When a link in the collection points at an HTML page instead of a feed, the
+page's <link rel="alternate"> elements with an RSS, Atom or JSON Feed type
+are tried in order. The discovered feed URL that was used is reported and
+saved with the feed's ETag and Last-Modified headers. The page is retrieved
+on each harvest, the headers are only sent with the request for that feed.
+Use 'antenna discover URL' to put the feed URL in the collection instead.
+
+Redirects are followed. When every redirect for a feed is permanent (301 or
+308) the new URL is reported and recorded in feed_health. With --rewrite-moved
+the link is also updated in the collection's Markdown list, keeping the old
+file as a .bak, and the feed's saved channel and items move to the new URL.
+ODT collections are not rewritten, the moved feeds are reported so the
+document can be edited by hand. Temporary redirects are followed without
+any rewrite.
+
+Each feed is scheduled so running harvest often (e.g. from cron every 15
+minutes) stays polite to publishers. A feed is not fetched again until the
+longest of these waits has passed: the collection's ttl (seconds, set in
+the collection's front matter), the feed's <ttl> or <sy:updatePeriod>,
+the Cache-Control max-age and the Retry-After response headers. The feed's
+<skipHours> and <skipDays> push the next harvest past those times. Feeds
+that are not due are reported and skipped.
+
+Item links are canonicalized before they are saved: the scheme and host
+are lower cased, default ports dropped and tracking parameters (utm_*,
+fbclid, gclid, mc_cid, mc_eid) removed, the rest of the link is kept as
+written. Items saved by older versions are moved to their canonical link
+when the collection database is opened. When an item carries a
+<link rel="canonical"> (e.g. an atom:link in an RSS item) that link is
+used instead. With near_duplicates set on the collection, items with the
+same title and similar content are saved once with each source's label.
+
+For feeds that only publish a summary, set full_article: true on the
+collection (or list the feeds by URL or label in full_article_feeds) to
+retrieve the web page each new item links to. The page's main content is
+extracted, converted to Markdown and rendered in place of the summary.
+
+Elements in the source namespace (http://source.scripting.com) are saved
+when harvesting: source:markdown and source:outline for items, and
+source:account, source:likes and source:blogroll for the channel. The
+Markdown is kept exactly as published.
+
+Feeds are fetched by a pool of workers. The concurrency, host_concurrency
+and timeout settings in antenna.yaml (or on a collection) control how many
+feeds are fetched at once, how many at once from a single host, and how many
+seconds to wait on each request. The defaults are 4, 2 and 30.
+
 # PARAMETERS
 
 COLLECTION_NAME
 : (optional) harvest only this collection
 
+# OPTIONS
+
+--force
+: harvest every feed even if it is not due yet
+
+--rewrite-moved
+: update the links to permanently moved feeds in the collection's Markdown
+
 # ALIASES
 
 fetch
@@ -721,6 +969,8 @@ fetch
 
 {app_name} harvest
 {app_name} harvest feeds/tech.md
+{app_name} harvest --force feeds/tech.md
+{app_name} harvest --rewrite-moved feeds/tech.md
 
 `
 
@@ -786,6 +1036,64 @@ tui
 {app_name} interactive
 {app_name} interactive post
 
+`
+
+	PruneHelpText = `%{app_name}(7) user manual | version {version} {release_hash}
+% R. S. Doiel
+% {release_date}
+
+# NAME
+
+prune
+
+# SYNOPSIS
+
+{app_name} prune [--dry-run] COLLECTION_NAME
+
+# DESCRIPTION
+
+Removes the harvested items that the collection's retention policy no
+longer keeps. The policy is set with a retention object on the collection
+in antenna.yaml and is also applied at the end of each harvest, except for
+keep_only_published which only prune applies so newly harvested items can
+be curated first.
+
+max_age
+: remove items published (or updated) more than this many days ago,
+  items without a readable date are kept
+
+max_items_per_feed
+: keep only this many of the newest items from each feed
+
+keep_only_published
+: remove the items whose status is not "published"
+
+Items that have a postPath are posts and are never removed.
+
+# PARAMETERS
+
+COLLECTION_NAME
+: collection Markdown file
+
+# OPTIONS
+
+--dry-run
+: list the items that would be removed, and the rule selecting them,
+  without removing them
+
+# EXAMPLES
+
+Example antenna.yaml collection:
+
+  collections:
+    - file: planet.md
+      retention:
+        max_age: 90
+        max_items_per_feed: 50
+
+{app_name} prune --dry-run planet.md
+{app_name} prune planet.md
+
 `
 
 	ItemsHelpText = `%{app_name}(7) user manual | version {version} {release_hash}
@@ -944,6 +1252,27 @@ Recommended additional fields:
   description summary for RSS and search engines
   keywords    list of tags
 
+Dublin Core fields, a string or a list, written to the RSS feed as dc:
+elements and to the page as PageFind filters:
+  creator, subject, publisher, contributor, date, type, format,
+  identifier, source, language, relation, coverage and rights. creator
+  defaults to the author names and rights to copyright. ODT documents
+  read them from the front matter made from their document properties.
+
+Podcast episodes and other media:
+  enclosure   an audio or video file, either a URL, a local path relative
+              to the site or the post, or a map of url, type and length.
+              Local files are copied into htdocs with their size and MIME
+              type computed.
+  transcript  podcast:transcript file(s), a path, URL or a map of url,
+              type, language and rel
+  chapters    podcast:chapters file, a path or URL
+  duration, explicit, episode, season, episodeType, image
+              iTunes episode details
+
+The episode details are written to the RSS feed of collections with
+podcast: true in antenna.yaml.
+
 After posting, run generate to rebuild the collection HTML and RSS feed.
 
 WARNING: HTML in the Markdown source passes through unchanged (unsafe mode).
@@ -961,6 +1290,19 @@ FILEPATH
 
 {app_name} post index.md blog/2026/04/12/my-post.md
 
+A podcast episode's front matter:
+
+~~~yaml
+title: Episode 1
+pubDate: "2026-04-12"
+enclosure: episode-1.mp3
+transcript:
+  url: episode-1.vtt
+  language: en
+duration: "00:32:10"
+episode: 1
+~~~
+
 # SEE ALSO
 
 {app_name} help metadata
@@ -1090,13 +1432,22 @@ Writes an RSS 2.0 feed for COLLECTION_NAME to RSS_FILENAME. The optional
 parameters work the same way as for posts: a COUNT or a FROM_DATE/TO_DATE
 range to limit the items included.
 
+When RSS_FILENAME ends in ".atom" an Atom 1.0 feed is written instead,
+and when it ends in ".json" a JSON Feed 1.1 document, with the same items.
+
+Each item's guid is written as it was saved, with isPermaLink="true"
+when it is an http or https URL and "false" otherwise. Older versions of
+antenna wrote every guid as cid://GUID. Feed readers subscribed to a feed
+written by an older version show its items as new once after upgrading.
+
 # PARAMETERS
 
 COLLECTION_NAME
 : collection Markdown file
 
 RSS_FILENAME
-: output path for the RSS file
+: output path for the RSS file, or the Atom or JSON Feed file if it ends
+  in ".atom" or ".json"
 
 COUNT
 : (optional) maximum number of items
@@ -1111,6 +1462,7 @@ TO_DATE
 
 {app_name} rss index.md index.xml
 {app_name} rss index.md archive.xml 2026-01-01 2026-06-30
+{app_name} rss index.md recent.json 10
 
 `
 
@@ -1331,6 +1683,16 @@ htdocs
 generator
 : (optional, default: page.yaml) default page generator YAML
 
+concurrency
+: (optional, default: 4) number of feeds harvested at the same time
+
+host_concurrency
+: (optional, default: 2) number of feeds harvested at the same time from
+  a single host
+
+timeout
+: (optional, default: 30) seconds to wait for each feed request when harvesting
+
 collections
 : (required) list of collection objects
 
@@ -1344,6 +1706,58 @@ Each collection object:
   generator
   : (optional) per-collection page generator YAML override
 
+  concurrency, host_concurrency, timeout
+  : (optional) override the harvest settings for this collection
+
+  retention
+  : (optional) which harvested items to keep, with max_age (days),
+    max_items_per_feed and keep_only_published, see 'antenna help prune'
+
+  near_duplicates
+  : (optional, default: false) collapse items with the same title and
+    similar content from different feeds into one item listing each
+    source label
+
+  full_article
+  : (optional, default: false) retrieve the article each item links to and
+    render it in place of the feed's summary
+
+  full_article_feeds
+  : (optional) list of feed URLs or labels to retrieve full articles for
+    when full_article isn't set
+
+  source_accounts, source_likes, source_blogroll
+  : (optional) publisher accounts (a list of service and name), likes
+    server and blogroll OPML URL written to the collection's RSS feed as
+    source namespace elements
+
+  podcast
+  : (optional, default: false) write the iTunes and Podcasting 2.0
+    elements of each post's episode to the RSS feed, see 'antenna help post'
+
+  podcast_author, podcast_image, podcast_categories, podcast_explicit
+  : (optional) the podcast's itunes:author, artwork URL, iTunes categories
+    (a subcategory is written as "Parent/Child") and explicit flag
+
+  tag_pages
+  : (optional, default: false) write a page and RSS feed for each of the
+    collection's tags, NAME/tags/TAG.html and NAME/tags/TAG.xml, and a tag
+    index, NAME/tags/index.html, in htdocs
+
+  author_pages
+  : (optional, default: false) write a page for each of the collection's
+    authors, NAME/authors/AUTHOR.html, in htdocs
+
+  archive_pages
+  : (optional, default: false) write a page for each year and month of the
+    collection's items, NAME-archive-YYYY.html and NAME-archive-YYYY-MM.html,
+    and an archive index, NAME-archive.html, next to the collection's page
+
+  formats
+  : (optional, default: [rss, json]) syndication formats generate writes
+    for the collection: "rss" (NAME.xml), "atom" (NAME.atom) and "json"
+    (NAME.json)
+
   mode
   : (optional) rendering mode: "aggregate" (default) or "page-index"
      "aggregate"  feed-item cards from the items table (default)
@@ -1357,6 +1771,8 @@ Example antenna.yaml:
     - file: index.md                 # aggregate (default)
     - file: links.md
       generator: links-page.yaml
+      formats: [ rss, atom, json ]   # also write links.atom
+      tag_pages: true                # also write links/tags/TAG.html and .xml
     - file: pages.md
       mode: page-index               # renders a simple link list
 
@@ -1384,7 +1800,9 @@ header
 : (optional) innerHTML of <header>
 
 nav
-: (optional) innerHTML of <nav aria-label="Site navigation">
+: (optional) innerHTML of <nav aria-label="Site navigation">. "auto" builds
+  the navigation from the collections and pages, a {{nav}} placeholder
+  inserts it into your own markup. See SITE NAVIGATION below.
 
 top_content
 : (optional) content between <nav> and <main>
@@ -1398,6 +1816,27 @@ footer
 allowed_meta_fields
 : (optional) allowlist of front matter keys to emit as <meta>
 
+items.page_size
+: (optional, default: 0) split the aggregate page into pages of this many
+  items, NAME.html, NAME-2.html and so on, linked by a Pagination nav and
+  rel="prev" and rel="next" head links. Zero writes a single page.
+
+cm_filters
+: (optional) list of Lua filter files run, in order, over the Markdown of
+  posts, pages and harvested items before it is rendered as HTML. See LUA
+  FILTERS below.
+
+heading_anchors
+: (optional, default: false) end each heading of posts and pages with a
+  link to itself. See HEADINGS below.
+
+layout
+: (optional) an html/template file, or a glob pattern matching several, that
+  collection pages, posts and pages are rendered through in place of the
+  header, nav, top_content, bottom_content and footer slots. The template
+  named "layout" is executed when one of the files defines it, otherwise the
+  first file is. See LAYOUTS below.
+
 Example page.yaml:
 
   lang: en-US
@@ -1420,6 +1859,164 @@ Example page.yaml:
     - description
     - keywords
 
+# LAYOUTS
+
+A layout is executed with the following data:
+
+.Page.Title, .Page.Lang, .Page.Description
+: the page title (front matter title first), lang and description
+
+.Page.Head
+: the complete <head> element the slot model writes
+
+.Page.Heading
+: the heading of a tag, author or archive page
+
+.Page.Content
+: what the slot model writes inside <main>: the rendered items, page list
+  or post
+
+.Page.Link, .Page.PostPath, .Page.PubDate
+: set for posts
+
+.Page.Pagination
+: .Number, .Count, .Prev and .Next of a paginated aggregate page
+
+.Items
+: the items of an aggregate page, each with .Link, .Title, .Description,
+  .Authors, .Enclosures, .GUID, .PubDate, .Updated, .Channel, .Label,
+  .PostPath, .Categories and .HTML (the item as the slot model renders it)
+
+.Pages
+: the links of a page-index page, each with .Href, .Name, .InputPath and
+  .OutputPath
+
+.Collection
+: the collection's settings from antenna.yaml (.Title, .File …), empty for
+  pages
+
+.Site
+: .AppName, .Version, .BaseURL, .Built and the slots .Header, .Nav,
+  .TopContent, .BottomContent and .Footer
+
+.FrontMatter
+: the front matter of a post or page
+
+Example layout.html:
+
+  <!doctype html>
+  <html lang="{{.Page.Lang}}">
+  {{.Page.Head}}
+  <body>
+    <header>{{.Site.Header}}</header>
+    <main id="main-content">{{.Page.Content}}</main>
+    <aside>
+      <ul>{{range .Items}}<li><a href="{{.Link}}">{{.Title}}</a></li>{{end}}</ul>
+    </aside>
+    <footer>{{.Site.Footer}}</footer>
+  </body>
+  </html>
+
+# SITE NAVIGATION
+
+When nav is "auto", or holds a {{nav}} placeholder, a list of links is
+built from the collections in antenna.yaml and written in the nav element.
+The link to the page being written is marked aria-current="page". The links
+are relative to the page, so a site published under a base_url with a path,
+e.g. https://example.org/blog, links to its own pages.
+
+Each collection is listed, linking to its HTML page, unless its Markdown
+file's front matter sets nav_exclude. A page in the pages collection is
+listed only when its front matter sets nav_order. These front matter keys
+control the navigation:
+
+nav_order
+: position in the navigation, lowest first. Entries without one follow in
+  the order of antenna.yaml
+
+nav_exclude
+: true leaves the collection or page out
+
+nav_title
+: the link text, defaults to the title, then the collection title
+
+Example page.yaml:
+
+  nav: |
+    <a href="/">Home</a>
+    {{nav}}
+
+# LUA FILTERS
+
+cm_filters work like Pandoc's Lua filters, most Pandoc filters run
+unchanged. A filter defines functions named for the elements it changes.
+Each is called with the element and returns nil to leave it alone, the
+element, a new element or a list of elements. An empty list removes the
+element. Inline elements are filtered before blocks. A filter may instead
+return a list of tables of functions which are run in turn.
+
+Inline elements
+: Str (text), Space, SoftBreak, LineBreak, Emph, Strong, Strikeout
+  (content), Code (text), Link (content, target, title), Image (caption,
+  src, title) and RawInline (format, text)
+
+Block elements
+: Header (level, content), Para and Plain (content), CodeBlock (text),
+  RawBlock (format, text), HorizontalRule, BlockQuote (content),
+  BulletList and OrderedList (content, a list of items, and start)
+
+Inline and Block
+: called for any inline or block element without a function of its own
+
+Link, Image, Header, Code and CodeBlock have identifier, classes and
+attributes. The pandoc table has a constructor for each element,
+pandoc.Attr and pandoc.utils.stringify. Raw elements in a format other
+than "html" are dropped. FORMAT is "html".
+
+Example links-to-html.lua:
+
+  function Link(el)
+    el.target = string.gsub(el.target, "%.md", ".html")
+    return el
+  end
+
+Example page.yaml:
+
+  cm_filters:
+    - links-to-html.lua
+
+# HEADINGS
+
+The headings of posts and pages get an id made from their text so they can
+be linked to, "## What's new?" becomes <h2 id="whats-new">. When the same id
+comes up again a number is added, "whats-new-1". cm_filters see the id as
+the identifier of a Header. These front matter keys control the headings
+of a post or page:
+
+toc
+: true puts a table of contents, a <nav aria-label="Table of contents">
+  listing the headings that follow it, at the top of the document or after
+  its leading h1
+
+heading_anchors
+: true or false, overrides the generator's heading_anchors
+
+A paragraph holding only [[toc]] puts the table of contents in its place,
+toc isn't needed. The self link anchor is written inside the heading as
+<a class="heading-anchor" href="#ID">#</a> with an aria-label naming the
+section.
+
+Example post:
+
+  ---
+  title: A long guide
+  toc: true
+  ---
+
+  # A long guide
+
+  ## Getting started
+
 # SEE ALSO
 
 {app_name} help metadata
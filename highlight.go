@@ -0,0 +1,303 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	// 3rd Party Packages
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// DefaultHighlightStyle is the chroma style "antenna css --highlight"
+	// writes, DefaultHighlightDarkStyle is used when the reader prefers a
+	// dark color scheme.
+	DefaultHighlightStyle     = "github"
+	DefaultHighlightDarkStyle = "github-dark"
+)
+
+// codeOptionsRegExp matches the key=value pairs of a fence's {…} options
+var codeOptionsRegExp = regexp.MustCompile(`(\w+)\s*=\s*(\[[^\]]*\]|"[^"]*"|'[^']*'|[^\s,}]+)`)
+
+// codeOptions are the settings of a fenced code block, taken from its info
+// string.
+//
+//	```go {linenos=true hl_lines=[2,"4-6"] linenostart=10}
+type codeOptions struct {
+	Language    string
+	LineNumbers bool
+	LineStart   int
+	Highlight   [][2]int
+}
+
+// parseCodeInfo returns the options of a fence info string, the language
+// followed by optional {key=value …} settings: linenos, hl_lines and
+// linenostart.
+func parseCodeInfo(info string) *codeOptions {
+	opts := &codeOptions{LineStart: 1}
+	info = strings.TrimSpace(info)
+	settings := ""
+	if i := strings.Index(info, "{"); i > -1 {
+		info, settings = strings.TrimSpace(info[:i]), info[i:]
+	}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		opts.Language = fields[0]
+	}
+	for _, m := range codeOptionsRegExp.FindAllStringSubmatch(settings, -1) {
+		value := strings.Trim(m[2], `"'`)
+		switch strings.ToLower(m[1]) {
+		case "linenos":
+			opts.LineNumbers = value != "false" && value != ""
+		case "linenostart":
+			if n, err := strconv.Atoi(value); err == nil {
+				opts.LineStart = n
+				opts.LineNumbers = true
+			}
+		case "hl_lines":
+			opts.Highlight = parseLineRanges(value)
+		}
+	}
+	return opts
+}
+
+// parseLineRanges returns the line ranges of an hl_lines value such as
+// [2,"4-6"] or "2 4-6".
+func parseLineRanges(value string) [][2]int {
+	ranges := [][2]int{}
+	value = strings.Trim(value, "[]")
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		part = strings.Trim(part, `"'`)
+		start, end, found := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(start))
+		if err != nil {
+			continue
+		}
+		to := from
+		if found {
+			if to, err = strconv.Atoi(strings.TrimSpace(end)); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges
+}
+
+// codeLexer returns the chroma lexer for a language, nil when it isn't
+// known.
+func codeLexer(language string) chroma.Lexer {
+	if language == "" {
+		return nil
+	}
+	if lexer := lexers.Get(language); lexer != nil {
+		return lexer
+	}
+	return nil
+}
+
+// codeLanguage returns the language name of a file for a fence info
+// string, "" when it isn't known.
+func codeLanguage(fName string) string {
+	lexer := lexers.Match(filepath.Base(fName))
+	if lexer == nil {
+		return ""
+	}
+	config := lexer.Config()
+	if len(config.Aliases) > 0 {
+		return config.Aliases[0]
+	}
+	return strings.ToLower(config.Name)
+}
+
+// codeWrapper writes the pre and code elements around highlighted code.
+// The pre element can be scrolled with the keyboard and the code element
+// names the language like goldmark's.
+type codeWrapper struct {
+	language string
+}
+
+// Start implements chromahtml.PreWrapper
+func (p codeWrapper) Start(code bool, styleAttr string) string {
+	if p.language == "" {
+		return fmt.Sprintf("<pre tabindex=\"0\"%s><code>", styleAttr)
+	}
+	return fmt.Sprintf("<pre tabindex=\"0\"%s><code class=\"language-%s\">", styleAttr, util.EscapeHTML([]byte(p.language)))
+}
+
+// End implements chromahtml.PreWrapper
+func (p codeWrapper) End(code bool) string {
+	return "</code></pre>"
+}
+
+// highlightCode writes code as HTML with CSS classes for its syntax. A
+// code block in a language chroma doesn't know, without line numbers or
+// highlighted lines, is written the way goldmark writes it.
+func highlightCode(w io.Writer, code string, opts *codeOptions) error {
+	lexer := codeLexer(opts.Language)
+	if lexer == nil {
+		if !opts.LineNumbers && len(opts.Highlight) == 0 {
+			class := ""
+			if opts.Language != "" {
+				class = fmt.Sprintf(" class=\"language-%s\"", util.EscapeHTML([]byte(opts.Language)))
+			}
+			_, err := fmt.Fprintf(w, "<pre><code%s>%s</code></pre>\n", class, util.EscapeHTML([]byte(code)))
+			return err
+		}
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return err
+	}
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(opts.LineNumbers),
+		chromahtml.BaseLineNumber(opts.LineStart),
+		chromahtml.HighlightLines(opts.Highlight),
+		chromahtml.WithPreWrapper(codeWrapper{language: opts.Language}),
+	)
+	if err := formatter.Format(w, styles.Fallback, iterator); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// highlightRenderer renders fenced code blocks with highlightCode
+type highlightRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *highlightRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *highlightRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+	info := ""
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+	code := new(bytes.Buffer)
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		code.Write(line.Value(source))
+	}
+	if err := highlightCode(w, code.String(), parseCodeInfo(info)); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// highlighting is the goldmark extension highlighting fenced code blocks
+type highlighting struct{}
+
+// Extend implements goldmark.Extender
+func (e *highlighting) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&highlightRenderer{}, 200)))
+}
+
+// WriteHighlightCSS writes the CSS for the classes of highlighted code in
+// style. When style is "" the default style is written with the dark
+// style for readers who prefer a dark color scheme.
+func WriteHighlightCSS(out io.Writer, style string) error {
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if style != "" {
+		s, ok := styles.Registry[strings.ToLower(style)]
+		if !ok {
+			return fmt.Errorf("unknown highlight style %q", style)
+		}
+		fmt.Fprintf(out, "/* Syntax highlighting, %s style */\n", s.Name)
+		return formatter.WriteCSS(out, s)
+	}
+	fmt.Fprintf(out, "/* Syntax highlighting, %s style */\n", DefaultHighlightStyle)
+	if err := formatter.WriteCSS(out, styles.Get(DefaultHighlightStyle)); err != nil {
+		return err
+	}
+	dark := new(bytes.Buffer)
+	if err := formatter.WriteCSS(dark, styles.Get(DefaultHighlightDarkStyle)); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n/* %s style when a dark color scheme is preferred */\n", DefaultHighlightDarkStyle)
+	fmt.Fprintln(out, "@media (prefers-color-scheme: dark) {")
+	fmt.Fprintf(out, "  %s\n", indentText(strings.TrimSpace(dark.String()), 2))
+	fmt.Fprintln(out, "}")
+	return nil
+}
+
+/** GenerateHighlightCSS writes the syntax highlighting stylesheet and
+ * patches the generator YAML to reference it.
+ *
+ * Parameters:
+ *   out     (io.Writer) — progress messages
+ *   cssPath (string)    — relative path within Htdocs (e.g. "css/highlight.css")
+ *   style   (string)    — chroma style name, "" for github with github-dark
+ *
+ * Returns:
+ *   error — non-nil on an unknown style or I/O failure
+ *
+ * Example:
+ *   err := cfg.GenerateHighlightCSS(os.Stdout, "css/highlight.css", "")
+ */
+func (cfg *AppConfig) GenerateHighlightCSS(out io.Writer, cssPath string, style string) error {
+	src := new(bytes.Buffer)
+	if err := WriteHighlightCSS(src, style); err != nil {
+		return err
+	}
+	absCSS := cssPath
+	if cfg.Htdocs != "" {
+		absCSS = filepath.Join(cfg.Htdocs, cssPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(absCSS), 0775); err != nil {
+		return fmt.Errorf("cannot create %s: %s", filepath.Dir(absCSS), err)
+	}
+	if err := os.WriteFile(absCSS, src.Bytes(), 0664); err != nil {
+		return fmt.Errorf("cannot write %s: %s", absCSS, err)
+	}
+	fmt.Fprintf(out, "wrote %s\n", absCSS)
+	if cfg.Generator != "" {
+		href := "/" + strings.TrimLeft(filepath.ToSlash(cssPath), "/")
+		patched, msg, err := patchGeneratorYAML(cfg.Generator, href)
+		if err != nil {
+			fmt.Fprintf(out, "warning: could not patch %s: %s\n", cfg.Generator, err)
+		} else if patched {
+			fmt.Fprintf(out, "updated %s with stylesheet link → %s\n", cfg.Generator, href)
+		} else {
+			fmt.Fprintf(out, "%s\n", msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCodeInfo(t *testing.T) {
+	for info, expected := range map[string]*codeOptions{
+		"":   {LineStart: 1},
+		"go": {Language: "go", LineStart: 1},
+		`python {linenos=true, hl_lines=[2,"4-6"]}`: {Language: "python", LineNumbers: true, LineStart: 1,
+			Highlight: [][2]int{{2, 2}, {4, 6}}},
+		`js {hl_lines="1 3-4" linenostart=10}`: {Language: "js", LineNumbers: true, LineStart: 10,
+			Highlight: [][2]int{{1, 1}, {3, 4}}},
+		"{linenos=false}": {LineStart: 1},
+	} {
+		got := parseCodeInfo(info)
+		if got.Highlight != nil && len(got.Highlight) == 0 {
+			got.Highlight = nil
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("parseCodeInfo(%q) = %+v, expected %+v", info, got, expected)
+		}
+	}
+}
+
+func TestHighlightCodeBlocks(t *testing.T) {
+	doc := &CommonMark{Text: "```go {linenos=true hl_lines=[2]}\npackage main\nfunc main() {}\n```\n\n" +
+		"```\na < b\n```\n\n```unknown-lang\nx & y\n```\n"}
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<pre tabindex="0" class="chroma"><code class="language-go">`,
+		`<span class="kn">package</span>`,
+		`<span class="ln">1</span>`,
+		`<span class="line hl"><span class="ln">2</span>`,
+		"<pre><code>a &lt; b\n</code></pre>",
+		"<pre><code class=\"language-unknown-lang\">x &amp; y\n</code></pre>",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+	if strings.Contains(src, "style=") {
+		t.Errorf("expected CSS classes, not inline styles\n%s", src)
+	}
+}
+
+func TestIncludeCodeBlockLanguage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("hello.py", []byte("print('hello')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text := IncludeCodeBlock("Example\n\n @include-code-block hello.py\n")
+	if !strings.Contains(text, "~~~python\nprint('hello')\n~~~") {
+		t.Errorf("expected the file's language in the fence\n%s", text)
+	}
+	text = IncludeCodeBlock("Example\n\n @include-code-block hello.py text\n")
+	if !strings.Contains(text, "~~~text\n") {
+		t.Errorf("expected the language argument in the fence\n%s", text)
+	}
+}
+
+func TestGenerateHighlightCSS(t *testing.T) {
+	dName := t.TempDir()
+	cfg := &AppConfig{Htdocs: dName}
+	if err := cfg.GenerateHighlightCSS(io.Discard, "css/highlight.css", ""); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(filepath.Join(dName, "css", "highlight.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{".chroma .kn {", ".chroma .hl {", "@media (prefers-color-scheme: dark) {"} {
+		if !bytes.Contains(src, []byte(expected)) {
+			t.Errorf("expected %q in the stylesheet", expected)
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := WriteHighlightCSS(buf, "monokai"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "@media") {
+		t.Errorf("expected a named style without the dark style")
+	}
+	if err := WriteHighlightCSS(io.Discard, "no-such-style"); err == nil {
+		t.Errorf("expected an error for an unknown style")
+	}
+}
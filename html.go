@@ -39,7 +39,7 @@ import (
 // is true when the item was omitted entirely (items.link.missing: omit —
 // DEC-027) and no output was written for it.
 func (gen *Generator) WriteItem(out io.Writer, link string, title string, description string, authors []*gofeed.Person,
-	sourceMarkdown string, enclosures []*Enclosure, guid string, pubDate string, dcExtSrc string,
+	sourceMarkdown string, fullMarkdown string, enclosures []*Enclosure, guid string, pubDate string, dcExtSrc string,
 	channel string, status string, updated string, label string, categories string, cfg ItemsConfig) (bool, error) {
 	cfg.applyDefaults()
 
@@ -170,7 +170,7 @@ func (gen *Generator) WriteItem(out io.Writer, link string, title string, descri
 	var content string
 	var contentIsBlockHTML bool
 	if showField("content") {
-		content, contentIsBlockHTML, err = resolveItemContent(description, sourceMarkdown, cfg)
+		content, contentIsBlockHTML, err = resolveItemContent(description, sourceMarkdown, fullMarkdown, cfg, gen.CMarkFilters)
 		if err != nil {
 			return false, err
 		}
@@ -300,23 +300,28 @@ func truncateWords(s string, maxLen int) string {
 }
 
 // resolveItemContent resolves a feed item's rendered body content following
-// the content-source precedence declared in DEC-023: sourceMarkdown is
-// preferred when non-empty (rendered via CommonMark, safe or unsafe per
+// the content-source precedence declared in DEC-023: fullMarkdown, the
+// article retrieved from the item's link when full_article is set for the
+// feed, is preferred when non-empty, then sourceMarkdown (rendered via CommonMark, safe or unsafe per
 // cfg.HTML — DEC-024), falling back to the raw feed description (tag-
 // stripped, escaped, or passed through unchanged per cfg.HTML) only when
-// sourceMarkdown is empty. cfg.ContentMaxLength, if set, truncates the
+// both are empty. cfg.ContentMaxLength, if set, truncates the
 // resolved pre-render source text on a word boundary before conversion
-// (DEC-029), never the rendered HTML.
+// (DEC-029), never the rendered HTML. The Markdown is rendered with the
+// generator's cm_filters, filters.
 //
 // The isBlockHTML return reports whether content is already rendered,
-// block-level HTML (sourceMarkdown is always rendered via CommonMark; raw
+// block-level HTML (Markdown is always rendered via CommonMark; raw
 // description is block HTML only in cfg.HTML == "unsafe" passthrough).
 // Callers must not wrap block HTML in another <p>: CommonMark/unsafe
 // passthrough content already contains its own <p>/<ul>/<blockquote>
 // elements, and re-wrapping it produces invalid nested markup that
 // browsers silently mangle by auto-closing the outer <p>.
-func resolveItemContent(description, sourceMarkdown string, cfg ItemsConfig) (content string, isBlockHTML bool, err error) {
-	source := sourceMarkdown
+func resolveItemContent(description, sourceMarkdown, fullMarkdown string, cfg ItemsConfig, filters []string) (content string, isBlockHTML bool, err error) {
+	source := fullMarkdown
+	if source == "" {
+		source = sourceMarkdown
+	}
 	usedMarkdown := true
 	if source == "" {
 		source = description
@@ -327,6 +332,7 @@ func resolveItemContent(description, sourceMarkdown string, cfg ItemsConfig) (co
 	}
 	if usedMarkdown {
 		doc := &CommonMark{Text: source}
+		doc.UseFilters(filters)
 		if cfg.HTML == "unsafe" {
 			rendered, err := doc.ToUnsafeHTML()
 			return rendered, true, err
@@ -442,6 +448,22 @@ func formatItemDate(raw string, layout string) string {
 	return raw
 }
 
+// parseItemDate parses a stored pubDate/updated value against each of
+// storedDateLayouts and then as a plain date, the form posts are saved
+// with. The feed writers use it to reformat dates for their formats.
+func parseItemDate(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	for _, l := range storedDateLayouts {
+		if t, err := time.Parse(l, raw); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
 // elementFromMap, generate an HTML element from a map[string]string
 func elementFromMap(element string, m map[string]string) string {
 	parts := []string{}
@@ -562,6 +584,74 @@ func indentText(src string, spaces int) string {
 
 // WriteHTML writes aggregated items into an HTML page from the contents of the database
 func (gen *Generator) WriteHTML(out io.Writer, db *sql.DB, cfgName string, collection *Collection) error {
+	return gen.WriteCustomHTML(out, db, "", SQLDisplayItems)
+}
+
+// displayItem holds a row of SQLDisplayItems, or one of the statements
+// returning the same columns.
+type displayItem struct {
+	link           string
+	title          string
+	description    string
+	authors        []*gofeed.Person
+	enclosures     []*Enclosure
+	guid           string
+	pubDate        string
+	dcExt          string
+	channel        string
+	status         string
+	updated        string
+	label          string
+	postPath       string
+	sourceMarkdown string
+	categories     string
+	fullMarkdown   string
+	sourceOutline  string
+	podcastExt     string
+}
+
+// scanDisplayItem reads the current row into a displayItem. Authors and
+// enclosures that fail to decode are reported to eout and left out.
+func (gen *Generator) scanDisplayItem(rows *sql.Rows) (*displayItem, error) {
+	var (
+		item          = &displayItem{}
+		authorsSrc    string
+		enclosuresSrc string
+	)
+	if err := rows.Scan(&item.link, &item.title, &item.description, &authorsSrc,
+		&enclosuresSrc, &item.guid, &item.pubDate, &item.dcExt,
+		&item.channel, &item.status, &item.updated, &item.label, &item.postPath, &item.sourceMarkdown,
+		&item.categories, &item.fullMarkdown, &item.sourceOutline, &item.podcastExt); err != nil {
+		return nil, err
+	}
+	if authorsSrc != "" {
+		item.authors = []*gofeed.Person{}
+		if err := json.Unmarshal([]byte(authorsSrc), &item.authors); err != nil {
+			fmt.Fprintf(gen.eout, "error (authors: %s): %s\n", authorsSrc, err)
+			item.authors = nil
+		}
+	}
+	if enclosuresSrc != "" {
+		item.enclosures = []*Enclosure{}
+		if err := json.Unmarshal([]byte(enclosuresSrc), &item.enclosures); err != nil {
+			fmt.Fprintf(gen.eout, "error (enclosures: %s): %s\n", err, enclosuresSrc)
+			item.enclosures = nil
+		}
+	}
+	return item, nil
+}
+
+// writeDisplayItem writes an item with WriteItem using gen.Items
+func (gen *Generator) writeDisplayItem(out io.Writer, item *displayItem) (bool, error) {
+	return gen.WriteItem(out, item.link, item.title, item.description, item.authors,
+		item.sourceMarkdown, item.fullMarkdown, item.enclosures, item.guid, item.pubDate, item.dcExt,
+		item.channel, item.status, item.updated, item.label, item.categories, gen.Items)
+}
+
+// WriteCustomHTML writes an aggregate page of the items returned by one of
+// the SQL statements used by WriteCustomRSS. A heading, when not empty,
+// is written at the top of the main element.
+func (gen *Generator) WriteCustomHTML(out io.Writer, db *sql.DB, heading string, sqlStmt string, args ...any) error {
 	// Apply items: config defaults and validate enum values once, up
 	// front, so a typo aborts generation instead of silently misrendering
 	// every item (DEC-022–031).
@@ -569,6 +659,9 @@ func (gen *Generator) WriteHTML(out io.Writer, db *sql.DB, cfgName string, colle
 	if err := gen.Items.validate(); err != nil {
 		return err
 	}
+	if gen.Layout != "" {
+		return gen.writeLayoutItems(out, db, heading, sqlStmt, args...)
+	}
 	// Create the outer elements of a page.
 	fmt.Fprintf(out, "<!doctype html>\n<html lang=%q>\n", gen.Lang)
 	defer fmt.Fprintln(out, "</html>")
@@ -586,11 +679,11 @@ func (gen *Generator) WriteHTML(out io.Writer, db *sql.DB, cfgName string, colle
 		fmt.Fprintln(gen.eout, "warning: aggregate page has no <h1>; set a 'header' value in the generator YAML")
 	}
 	// Setup nav element
-	if gen.Nav != "" {
+	if nav := gen.navHTML(); nav != "" {
 		fmt.Fprintf(out, `  <nav aria-label="Site navigation">
     %s
   </nav>
-`, indentText(strings.TrimSpace(gen.Nav), 4))
+`, indentText(strings.TrimSpace(nav), 4))
 	}
 	if gen.TopContent != "" {
 		fmt.Fprintf(out, `
@@ -599,63 +692,32 @@ func (gen *Generator) WriteHTML(out io.Writer, db *sql.DB, cfgName string, colle
 	}
 	// main landmark wraps the primary feed content
 	fmt.Fprintln(out, `  <main id="main-content">`)
-	stmt := SQLDisplayItems
-	rows, err := db.Query(stmt)
+	if heading != "" {
+		fmt.Fprintf(out, "    <h2>%s</h2>\n", html.EscapeString(heading))
+	}
+	stmt := sqlStmt
+	rows, err := db.Query(stmt, args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 	// Setup and write out the body
 	for rows.Next() {
-		var (
-			link           string
-			title          string
-			description    string
-			authorsSrc     string
-			authors        []*gofeed.Person
-			enclosuresSrc  string
-			enclosures     []*Enclosure
-			guid           string
-			pubDate        string
-			dcExt          string
-			channel        string
-			status         string
-			updated        string
-			label          string
-			postPath       string
-			sourceMarkdown string
-			categories     string
-		)
-		if err := rows.Scan(&link, &title, &description, &authorsSrc,
-			&enclosuresSrc, &guid, &pubDate, &dcExt,
-			&channel, &status, &updated, &label, &postPath, &sourceMarkdown,
-			&categories); err != nil {
+		item, err := gen.scanDisplayItem(rows)
+		if err != nil {
 			fmt.Fprintf(gen.eout, "error (%s): %s\n", stmt, err)
 			continue
 		}
-		if authorsSrc != "" {
-			authors = []*gofeed.Person{}
-			if err := json.Unmarshal([]byte(authorsSrc), &authors); err != nil {
-				fmt.Fprintf(gen.eout, "error (authors: %s): %s\n", authorsSrc, err)
-				authors = nil
-			}
-		}
-		if enclosuresSrc != "" {
-			enclosures = []*Enclosure{}
-			if err := json.Unmarshal([]byte(enclosuresSrc), &enclosures); err != nil {
-				fmt.Fprintf(gen.eout, "error (enclosures: %s): %s\n", err, enclosuresSrc)
-				enclosures = nil
-			}
-		}
-		if _, err := gen.WriteItem(out, link, title, description, authors,
-			sourceMarkdown, enclosures, guid, pubDate, dcExt,
-			channel, status, updated, label, categories, gen.Items); err != nil {
+		if _, err := gen.writeDisplayItem(out, item); err != nil {
 			return err
 		}
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
+	if gen.pagination != nil {
+		gen.pagination.write(out)
+	}
 	fmt.Fprintln(out, "  </main>")
 	if gen.BottomContent != "" {
 		fmt.Fprintf(out, `
@@ -685,6 +747,7 @@ func pageDisplayName(inputPath string) string {
 // WritePageIndex renders a simple `<ul>` link list from the pages table of db.
 // It is used when a collection has mode: page-index. Each row from the pages
 // table becomes one `<li><a href="outputPath">displayName</a></li>` entry.
+// When a layout is set the whole page is rendered through it instead.
 func (gen *Generator) WritePageIndex(out io.Writer, db *sql.DB) error {
 	rows, err := db.Query(SQLPageIndexItems)
 	if err != nil {
@@ -692,27 +755,42 @@ func (gen *Generator) WritePageIndex(out io.Writer, db *sql.DB) error {
 	}
 	defer rows.Close()
 
-	fmt.Fprintln(out, `  <main id="main-content">`)
-	fmt.Fprintln(out, "    <ul>")
+	pages := []*LayoutPageLink{}
 	for rows.Next() {
 		var inputPath, outputPath string
 		if err := rows.Scan(&inputPath, &outputPath); err != nil {
 			fmt.Fprintf(gen.eout, "error (page-index row): %s\n", err)
 			continue
 		}
-		// Normalise outputPath: ensure it starts with / for web-root linking
-		href := "/" + strings.TrimLeft(filepath.ToSlash(outputPath), "/")
-		label := pageDisplayName(inputPath)
-		fmt.Fprintf(out, "      <li><a href=%q>%s</a></li>\n", href, label)
+		pages = append(pages, &LayoutPageLink{
+			// Normalise outputPath: ensure it starts with / for web-root linking
+			Href:       "/" + strings.TrimLeft(filepath.ToSlash(outputPath), "/"),
+			Name:       pageDisplayName(inputPath),
+			InputPath:  inputPath,
+			OutputPath: outputPath,
+		})
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
-	fmt.Fprintln(out, "    </ul>")
+	if gen.Layout != "" {
+		return gen.writeLayoutPageIndex(out, pages)
+	}
+	fmt.Fprintln(out, `  <main id="main-content">`)
+	writePageList(out, pages)
 	fmt.Fprintln(out, "  </main>")
 	return nil
 }
 
+// writePageList writes the page index's <ul> link list
+func writePageList(out io.Writer, pages []*LayoutPageLink) {
+	fmt.Fprintln(out, "    <ul>")
+	for _, page := range pages {
+		fmt.Fprintf(out, "      <li><a href=%q>%s</a></li>\n", page.Href, page.Name)
+	}
+	fmt.Fprintln(out, "    </ul>")
+}
+
 // WriteHtmlPage renders a post as an HTML Page using HTML connent and wrapping it based on the
 // generator configuration.
 func (gen *Generator) WriteHtmlPage(htmlName string, link string, postPath, pubDate string, innerHTML string, frontMatter map[string]interface{}) error {
@@ -728,6 +806,13 @@ func (gen *Generator) WriteHtmlPage(htmlName string, link string, postPath, pubD
 		return err
 	}
 	defer out.Close()
+	if gen.htdocs != "" {
+		// Mark the page's own link in the site navigation
+		gen.pagePath = webPath(gen.htdocs, htmlName)
+	}
+	if gen.Layout != "" {
+		return gen.writeLayoutPage(out, link, postPath, pubDate, innerHTML, frontMatter)
+	}
 
 	// Create the outer elements of a page.
 	fmt.Fprintf(out, "<!doctype html>\n<html lang=%q>\n", gen.Lang)
@@ -744,11 +829,11 @@ func (gen *Generator) WriteHtmlPage(htmlName string, link string, postPath, pubD
 		fmt.Fprintf(out, "  <header>\n    %s\n  </header>\n", indentText(strings.TrimSpace(gen.Header), 4))
 	}
 	// Setup nav element
-	if gen.Nav != "" {
+	if nav := gen.navHTML(); nav != "" {
 		fmt.Fprintf(out, `  <nav aria-label="Site navigation">
     %s
   </nav>
-`, indentText(strings.TrimSpace(gen.Nav), 4))
+`, indentText(strings.TrimSpace(nav), 4))
 	}
 
 	if gen.TopContent != "" {
@@ -50,7 +50,10 @@ func newTestItemsDB(t *testing.T) *sql.DB {
 		label TEXT DEFAULT '',
 		postPath TEXT DEFAULT '',
 		sourceMarkdown TEXT DEFAULT '',
-		categories TEXT DEFAULT ''
+		categories TEXT DEFAULT '',
+		fullMarkdown TEXT DEFAULT '',
+		sourceOutline TEXT DEFAULT '',
+		podcastExt TEXT DEFAULT ''
 	)`)
 	if err != nil {
 		t.Fatalf("create items table: %s", err)
@@ -173,7 +176,7 @@ func TestWriteItem_NoMetadata(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "", "", "", "", "", "", "", ItemsConfig{})
+		nil, "", "", nil, "guid1", "", "", "", "", "", "", "", ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -187,7 +190,7 @@ func TestWriteItem_SingleCategory(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "", "", "", "", "", "", `["Oberon"]`, ItemsConfig{})
+		nil, "", "", nil, "guid1", "", "", "", "", "", "", `["Oberon"]`, ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -201,7 +204,7 @@ func TestWriteItem_MultipleCategories(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "", "", "", "", "", "", `["a","b"]`, ItemsConfig{})
+		nil, "", "", nil, "guid1", "", "", "", "", "", "", `["a","b"]`, ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -216,7 +219,7 @@ func TestWriteItem_DCSubjectAndCreator(t *testing.T) {
 	var buf bytes.Buffer
 	dcExt := `{"subject":["Languages"],"creator":["Alice"]}`
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "", dcExt, "", "", "", "", "", ItemsConfig{})
+		nil, "", "", nil, "guid1", "", dcExt, "", "", "", "", "", ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -234,7 +237,7 @@ func TestWriteItem_Author(t *testing.T) {
 	authors := []*gofeed.Person{{Name: "R. S. Doiel"}}
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		authors, "", nil, "guid1", "", "", "", "", "", "", "", ItemsConfig{})
+		authors, "", "", nil, "guid1", "", "", "", "", "", "", "", ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -248,7 +251,7 @@ func TestWriteItem_LabelAndChannel(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "", "", "https://example.com/feed.xml", "", "", "My Feed", "", ItemsConfig{})
+		nil, "", "", nil, "guid1", "", "", "https://example.com/feed.xml", "", "", "My Feed", "", ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -265,7 +268,7 @@ func TestWriteItem_PubDate(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{})
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -281,7 +284,7 @@ func TestWriteItem_AllCombined(t *testing.T) {
 	dcExt := `{"subject":["Science"]}`
 	var buf bytes.Buffer
 	_, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		authors, "", nil, "guid1", "2020-06-01", dcExt,
+		authors, "", "", nil, "guid1", "2020-06-01", dcExt,
 		"https://example.com/feed.xml", "", "", "Feed Label", `["tech"]`, ItemsConfig{})
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
@@ -429,7 +432,7 @@ func TestWriteItem_NoAddress(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -442,7 +445,7 @@ func TestWriteItem_HasFooter(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -455,7 +458,7 @@ func TestWriteItem_TimeElement(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-04-11", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-04-11", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -468,7 +471,7 @@ func TestWriteItem_DateNotInHeading(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "My Post", "desc",
-		nil, "", nil, "guid1", "2020-04-11", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-04-11", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -488,7 +491,7 @@ func TestWriteItem_UpdatedTimeElement(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-04-11", "", "", "", "2020-05-01", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-04-11", "", "", "", "2020-05-01", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -509,7 +512,7 @@ func TestWriteItem_FieldsRestrictsSections(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := ItemsConfig{Fields: []string{"title", "content"}}
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "My Feed", "", cfg); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "My Feed", "", cfg); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -533,7 +536,7 @@ func TestWriteItem_ShowSourceFalse(t *testing.T) {
 	f := false
 	cfg := ItemsConfig{ShowSource: &f}
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "My Feed", "", cfg); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "My Feed", "", cfg); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -547,7 +550,7 @@ func TestWriteItem_OmittedItemWritesNothing(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := ItemsConfig{Link: LinkConfig{Missing: "omit"}}
 	omitted, err := gen.WriteItem(&buf, "", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg)
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg)
 	if err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
@@ -563,7 +566,7 @@ func TestWriteItem_DefaultLabelIsAccessible(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -577,7 +580,7 @@ func TestWriteItem_LabelFieldLinkOptOut(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := ItemsConfig{Link: LinkConfig{LabelField: "link"}}
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -591,7 +594,7 @@ func TestWriteItem_CustomStaticLabel(t *testing.T) {
 	var buf bytes.Buffer
 	cfg := ItemsConfig{Link: LinkConfig{LabelFallback: "read me"}}
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", cfg); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -610,7 +613,7 @@ func TestWriteItem_MultiParagraphContentNotNested(t *testing.T) {
 	var buf bytes.Buffer
 	sourceMarkdown := "First paragraph.\n\nSecond paragraph."
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, sourceMarkdown, nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, sourceMarkdown, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -637,7 +640,7 @@ func TestWriteItem_ContentWrappedInSingleBodyContainer(t *testing.T) {
 	var buf bytes.Buffer
 	sourceMarkdown := "## First bookmark\n\nShort note one.\n\n## Second bookmark\n\nShort note two."
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Title", "desc",
-		nil, sourceMarkdown, nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, sourceMarkdown, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -659,7 +662,7 @@ func TestWriteItem_TitleAmpersandEscaped(t *testing.T) {
 	gen := newTestGenerator()
 	var buf bytes.Buffer
 	if _, err := gen.WriteItem(&buf, "https://example.com", "Q&A: Test", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -676,7 +679,7 @@ func TestWriteItem_LinkHrefEscaped(t *testing.T) {
 	var buf bytes.Buffer
 	link := "https://example.com/?a=1&b=2"
 	if _, err := gen.WriteItem(&buf, link, "Title", "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -702,7 +705,7 @@ func TestWriteItem_TitleWithPreEscapedEntityNotDoubleEscaped(t *testing.T) {
 	var buf bytes.Buffer
 	title := `Say &quot;hello&quot; to me`
 	if _, err := gen.WriteItem(&buf, "https://example.com", title, "desc",
-		nil, "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
+		nil, "", "", nil, "guid1", "2020-01-01", "", "", "", "", "", "", ItemsConfig{}); err != nil {
 		t.Fatalf("WriteItem: %s", err)
 	}
 	out := buf.String()
@@ -802,7 +805,7 @@ func TestStripTags(t *testing.T) {
 func TestResolveItemContent(t *testing.T) {
 	t.Run("markdown present, default strip", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "strip"}
-		got, isBlockHTML, err := resolveItemContent("<p>raw</p>", "**bold**", cfg)
+		got, isBlockHTML, err := resolveItemContent("<p>raw</p>", "**bold**", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
@@ -819,7 +822,7 @@ func TestResolveItemContent(t *testing.T) {
 
 	t.Run("markdown present, unsafe", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "unsafe"}
-		got, isBlockHTML, err := resolveItemContent("ignored", "before <script>x</script> after", cfg)
+		got, isBlockHTML, err := resolveItemContent("ignored", "before <script>x</script> after", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
@@ -833,7 +836,7 @@ func TestResolveItemContent(t *testing.T) {
 
 	t.Run("no markdown, default strip", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "strip"}
-		got, isBlockHTML, err := resolveItemContent("<p>raw &amp; unsafe</p>", "", cfg)
+		got, isBlockHTML, err := resolveItemContent("<p>raw &amp; unsafe</p>", "", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
@@ -847,7 +850,7 @@ func TestResolveItemContent(t *testing.T) {
 
 	t.Run("no markdown, escape", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "escape"}
-		got, isBlockHTML, err := resolveItemContent("<b>hi</b>", "", cfg)
+		got, isBlockHTML, err := resolveItemContent("<b>hi</b>", "", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
@@ -861,7 +864,7 @@ func TestResolveItemContent(t *testing.T) {
 
 	t.Run("no markdown, unsafe", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "unsafe"}
-		got, isBlockHTML, err := resolveItemContent("<b>hi</b>", "", cfg)
+		got, isBlockHTML, err := resolveItemContent("<b>hi</b>", "", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
@@ -873,9 +876,23 @@ func TestResolveItemContent(t *testing.T) {
 		}
 	})
 
+	t.Run("full article preferred over markdown", func(t *testing.T) {
+		cfg := ItemsConfig{HTML: "strip"}
+		got, isBlockHTML, err := resolveItemContent("<p>raw</p>", "summary", "The *whole* article.", cfg, nil)
+		if err != nil {
+			t.Fatalf("resolveItemContent: %s", err)
+		}
+		if !strings.Contains(got, "<em>whole</em>") || strings.Contains(got, "summary") {
+			t.Errorf("expected the rendered full article, got %q", got)
+		}
+		if !isBlockHTML {
+			t.Errorf("rendered markdown must be reported as block HTML")
+		}
+	})
+
 	t.Run("truncation applied pre-render", func(t *testing.T) {
 		cfg := ItemsConfig{HTML: "strip", ContentMaxLength: 5}
-		got, _, err := resolveItemContent("ignored", "one two three four five", cfg)
+		got, _, err := resolveItemContent("ignored", "one two three four five", "", cfg, nil)
 		if err != nil {
 			t.Fatalf("resolveItemContent: %s", err)
 		}
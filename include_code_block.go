@@ -28,7 +28,8 @@ import (
 // IncludeCodeBlock takes a text string and replaces the code blocks
 // based on the file path included in the line and the language name.
 // The generated code block uses the `~~~` sequence to delimit the block
-// with the language name provided in the opening delimiter.
+// with the language name provided in the opening delimiter. When no
+// language name is given one is chosen from the file's name.
 //
 // Parameters:
 //
@@ -91,5 +92,9 @@ func replaceCodeBlock(fullMatch string) string {
 		return fullMatch
 	}
 
+	// Without a language name the file's name picks one for highlighting
+	if language == "" {
+		language = codeLanguage(cleanPath)
+	}
 	return fmt.Sprintf("~~~%s\n%s\n~~~", language, string(fileContent))
 }
@@ -0,0 +1,253 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+// JSONFeedVersion is the version of the JSON Feed spec we write, see
+// <https://www.jsonfeed.org/version/1.1/>
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// jsonFeed is the top level object of a JSON Feed
+type jsonFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url,omitempty"`
+	FeedURL     string          `json:"feed_url,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Items       []*jsonFeedItem `json:"items"`
+}
+
+// jsonFeedItem is an item in a JSON Feed
+type jsonFeedItem struct {
+	ID            string                `json:"id"`
+	URL           string                `json:"url,omitempty"`
+	Title         string                `json:"title,omitempty"`
+	ContentHTML   string                `json:"content_html,omitempty"`
+	ContentText   string                `json:"content_text,omitempty"`
+	DatePublished string                `json:"date_published,omitempty"`
+	DateModified  string                `json:"date_modified,omitempty"`
+	Authors       []*jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string              `json:"tags,omitempty"`
+	Attachments   []*jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+// jsonFeedAuthor is an author of a JSON Feed item
+type jsonFeedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// jsonFeedAttachment is a related resource, e.g. a podcast episode
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// rfc3339Date returns a stored pubDate/updated value in RFC 3339 format,
+// or an empty string if it can't be parsed. It is used by the JSON Feed
+// and Atom writers.
+func rfc3339Date(raw string) string {
+	if t, ok := parseItemDate(raw); ok {
+		return t.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// feedContent returns the HTML and text content of an item, the
+// content_html and content_text of a JSON Feed.
+// sourceMarkdown is the text and is rendered as the HTML, without it the
+// description is used as the HTML and its text, with the tags removed and
+// entities decoded, as the text. The Markdown is rendered with the
+// generator's cm_filters, filters.
+func feedContent(description string, sourceMarkdown string, filters []string) (string, string, error) {
+	if sourceMarkdown == "" {
+		return description, strings.TrimSpace(html.UnescapeString(stripTags(description))), nil
+	}
+	doc := &CommonMark{Text: sourceMarkdown}
+	doc.UseFilters(filters)
+	innerHTML, err := doc.ToHTML()
+	if err != nil {
+		return "", "", err
+	}
+	return innerHTML, sourceMarkdown, nil
+}
+
+// WriteJSONFeed writes the collection's items as a JSON Feed, using the
+// same items as WriteRSS.
+func (gen *Generator) WriteJSONFeed(out io.Writer, db *sql.DB, collection *Collection) error {
+	feedLink := collection.feedLink(gen.BaseURL, ".json")
+	return gen.WriteCustomJSONFeed(out, db, SQLDisplayItems, feedLink, collection)
+}
+
+// WriteCustomJSONFeed generates a JSON Feed given one of the SQL
+// statements used by WriteCustomRSS.
+func (gen *Generator) WriteCustomJSONFeed(out io.Writer, db *sql.DB, sqlStmt string, feedLink string, collection *Collection, args ...any) error {
+	feed := &jsonFeed{
+		Version:     JSONFeedVersion,
+		Title:       collection.Title,
+		FeedURL:     feedLink,
+		Description: strings.TrimSpace(collection.Description),
+		Language:    collection.Language,
+		Items:       []*jsonFeedItem{},
+	}
+	if feed.Title == "" {
+		feed.Title = collection.baseName()
+	}
+	if gen.BaseURL != "" {
+		feed.HomePageURL = fmt.Sprintf("%s/%s.html", gen.BaseURL, collection.baseName())
+	}
+	rows, err := db.Query(sqlStmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			link           string
+			title          string
+			description    string
+			authorsSrc     string
+			enclosuresSrc  string
+			guid           string
+			pubDate        string
+			dcExt          string
+			channel        string
+			status         string
+			updated        string
+			label          string
+			postPath       string
+			sourceMarkdown string
+			categories     string
+			fullMarkdown   string
+			sourceOutline  string
+			podcastExt     string
+		)
+		if err := rows.Scan(&link, &title, &description, &authorsSrc,
+			&enclosuresSrc, &guid, &pubDate, &dcExt,
+			&channel, &status, &updated, &label, &postPath, &sourceMarkdown,
+			&categories, &fullMarkdown, &sourceOutline, &podcastExt); err != nil {
+			return err
+		}
+		item := &jsonFeedItem{
+			ID:            guid,
+			URL:           link,
+			Title:         title,
+			DatePublished: rfc3339Date(pubDate),
+			DateModified:  rfc3339Date(updated),
+		}
+		if item.ID == "" {
+			item.ID = link
+		}
+		item.ContentHTML, item.ContentText, err = feedContent(description, sourceMarkdown, gen.CMarkFilters)
+		if err != nil {
+			fmt.Fprintf(gen.eout, "error (%s): %s\n", link, err)
+			item.ContentHTML = description
+		}
+		if authorsSrc != "" {
+			authors := []*gofeed.Person{}
+			if err := json.Unmarshal([]byte(authorsSrc), &authors); err != nil {
+				fmt.Fprintf(gen.eout, "error (%s): %s\n", authorsSrc, err)
+			}
+			for _, author := range authors {
+				if author == nil || (author.Name == "" && author.Email == "") {
+					continue
+				}
+				a := &jsonFeedAuthor{Name: author.Name}
+				if author.Email != "" {
+					a.URL = "mailto:" + author.Email
+				}
+				item.Authors = append(item.Authors, a)
+			}
+		}
+		if categories != "" {
+			cats := []string{}
+			if err := json.Unmarshal([]byte(categories), &cats); err == nil {
+				for _, cat := range cats {
+					if cat = strings.TrimSpace(cat); cat != "" {
+						item.Tags = append(item.Tags, cat)
+					}
+				}
+			}
+		}
+		enclosures := []*Enclosure{}
+		if enclosuresSrc != "" {
+			if err := json.Unmarshal([]byte(enclosuresSrc), &enclosures); err != nil {
+				fmt.Fprintf(gen.eout, "error (%s): %s\n", enclosuresSrc, err)
+				enclosures = nil
+			}
+		}
+		// Posts include their Markdown document like they do in RSS, a
+		// podcast episode's enclosure is its media, not the Markdown
+		if postPath != "" && !(collection.Podcast && len(enclosures) > 0) {
+			if fi, err := os.Stat(postPath); err == nil {
+				markdownURL := gen.BaseURL + "/" + postPath
+				found := false
+				for _, enclosure := range enclosures {
+					if enclosure.Url == markdownURL {
+						found = true
+						break
+					}
+				}
+				if !found {
+					enclosures = append(enclosures, &Enclosure{
+						Url:    markdownURL,
+						Length: fmt.Sprintf("%d", fi.Size()),
+						Type:   "text/markdown",
+					})
+				}
+			}
+		}
+		for _, enclosure := range enclosures {
+			if enclosure == nil || strings.TrimSpace(enclosure.Url) == "" {
+				continue
+			}
+			attachment := &jsonFeedAttachment{
+				URL:      strings.TrimSpace(enclosure.Url),
+				MimeType: strings.TrimSpace(enclosure.Type),
+			}
+			if attachment.MimeType == "" {
+				attachment.MimeType = "application/octet-stream"
+			}
+			fmt.Sscanf(enclosure.Length, "%d", &attachment.SizeInBytes)
+			item.Attachments = append(item.Attachments, attachment)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// Leave the markup in content_html readable
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
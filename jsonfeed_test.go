@@ -0,0 +1,185 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+func TestWriteCustomJSONFeed(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	_, err := db.Exec(`INSERT INTO items
+		(link, title, description, authors, enclosures, guid, pubDate,
+		 dcExt, channel, status, updated, label, postPath, sourceMarkdown, categories)
+		VALUES (?, ?, ?, ?, ?, ?, ?, '', '', 'published', ?, '', '', ?, ?)`,
+		"https://example.com/episode-1",
+		"Episode 1",
+		"<p>The <em>first</em> episode</p>",
+		`[{"name":"Jane Doe","email":"jane@example.com"}]`,
+		`[{"url":"https://example.com/episode-1.mp3","length":"1234","type":"audio/mpeg"}]`,
+		"https://example.com/episode-1",
+		"2025-09-01 10:30:00",
+		"2025-09-02 08:00:00",
+		"The **first** episode",
+		`["podcast","antenna"]`,
+	)
+	if err != nil {
+		t.Fatalf("insert item: %s", err)
+	}
+	_, err = db.Exec(`INSERT INTO items
+		(link, title, description, authors, enclosures, guid, pubDate,
+		 dcExt, channel, status, updated, label, postPath, sourceMarkdown, categories)
+		VALUES (?, '', ?, '', '', '', '', '', '', 'published', '', '', '', '', '')`,
+		"https://example.com/note",
+		"A note with <b>markup</b> &amp; no Markdown",
+	)
+	if err != nil {
+		t.Fatalf("insert item: %s", err)
+	}
+
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	col := &Collection{
+		Title:       "Test Feed",
+		Description: "A feed of test items",
+		File:        "test.md",
+		Language:    "en-US",
+	}
+	var buf bytes.Buffer
+	if err := gen.WriteJSONFeed(&buf, db, col); err != nil {
+		t.Fatalf("WriteJSONFeed: %s", err)
+	}
+	feed := &jsonFeed{}
+	if err := json.Unmarshal(buf.Bytes(), feed); err != nil {
+		t.Fatalf("JSON Feed is not valid JSON: %s\n%s", err, buf.String())
+	}
+	if feed.Version != JSONFeedVersion || feed.Title != "Test Feed" || feed.FeedURL != "https://example.com/test.json" ||
+		feed.HomePageURL != "https://example.com/test.html" || feed.Language != "en-US" {
+		t.Errorf("unexpected feed metadata %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d\n%s", len(feed.Items), buf.String())
+	}
+	item := feed.Items[0]
+	if item.ID != "https://example.com/episode-1" || item.Title != "Episode 1" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item.ContentText != "The **first** episode" || !strings.Contains(item.ContentHTML, "<strong>first</strong>") {
+		t.Errorf("expected content from sourceMarkdown, got %q and %q", item.ContentText, item.ContentHTML)
+	}
+	if item.DatePublished != "2025-09-01T10:30:00Z" || item.DateModified != "2025-09-02T08:00:00Z" {
+		t.Errorf("expected RFC 3339 dates, got %q and %q", item.DatePublished, item.DateModified)
+	}
+	if len(item.Authors) != 1 || item.Authors[0].Name != "Jane Doe" || item.Authors[0].URL != "mailto:jane@example.com" {
+		t.Errorf("unexpected authors %+v", item.Authors)
+	}
+	if strings.Join(item.Tags, ",") != "podcast,antenna" {
+		t.Errorf("expected tags from categories, got %v", item.Tags)
+	}
+	if len(item.Attachments) != 1 || item.Attachments[0].URL != "https://example.com/episode-1.mp3" ||
+		item.Attachments[0].MimeType != "audio/mpeg" || item.Attachments[0].SizeInBytes != 1234 {
+		t.Errorf("unexpected attachments %+v", item.Attachments)
+	}
+	note := feed.Items[1]
+	if note.ID != "https://example.com/note" || note.ContentText != "A note with markup & no Markdown" {
+		t.Errorf("expected the description to stand in for Markdown, got %+v", note)
+	}
+
+	// Other feed readers must be able to read it
+	parsed, err := gofeed.NewParser().Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("gofeed failed to parse the JSON Feed: %s", err)
+	}
+	if parsed.FeedType != "json" || len(parsed.Items) != 2 {
+		t.Errorf("expected a JSON Feed with 2 items, got %q with %d items", parsed.FeedType, len(parsed.Items))
+	}
+}
+
+func TestJSONFeedLinksUseBaseName(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	// An ODT collection in a subdirectory is written as htdocs/links.html
+	col := &Collection{File: filepath.Join("feeds", "links.odt")}
+	var buf bytes.Buffer
+	if err := gen.WriteJSONFeed(&buf, db, col); err != nil {
+		t.Fatalf("WriteJSONFeed: %s", err)
+	}
+	feed := &jsonFeed{}
+	if err := json.Unmarshal(buf.Bytes(), feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.FeedURL != "https://example.com/links.json" || feed.HomePageURL != "https://example.com/links.html" || feed.Title != "links" {
+		t.Errorf("expected links named for the collection's base name, got %+v", feed)
+	}
+
+	// The collection's link names the RSS feed, the JSON Feed is next to it
+	for link, expected := range map[string]string{
+		"https://feeds.example.org/planet.xml": "https://feeds.example.org/planet.json",
+		"feeds/planet.xml":                     "https://example.com/feeds/planet.json",
+	} {
+		buf.Reset()
+		if err := gen.WriteJSONFeed(&buf, db, &Collection{File: "planet.md", Link: link}); err != nil {
+			t.Fatalf("WriteJSONFeed: %s", err)
+		}
+		feed := &jsonFeed{}
+		if err := json.Unmarshal(buf.Bytes(), feed); err != nil {
+			t.Fatal(err)
+		}
+		if feed.FeedURL != expected {
+			t.Errorf("expected feed_url %q for link %q, got %q", expected, link, feed.FeedURL)
+		}
+	}
+}
+
+func TestJSONFeedPodcastAttachments(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("episode-1.md", []byte("The first episode\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := newTestItemsDB(t)
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, enclosures, guid, status, postPath)
+		VALUES ('https://example.com/episode-1.html', 'Episode 1', 'The first episode', ?, 'https://example.com/episode-1.html', 'published', 'episode-1.md')`,
+		`[{"url":"https://example.com/episode-1.mp3","length":"1234","type":"audio/mpeg"}]`); err != nil {
+		t.Fatal(err)
+	}
+	gen := &Generator{eout: io.Discard, BaseURL: "https://example.com"}
+	// A podcast episode's attachment is its media, like its RSS enclosure
+	for podcast, expected := range map[bool]int{true: 1, false: 2} {
+		var buf bytes.Buffer
+		if err := gen.WriteJSONFeed(&buf, db, &Collection{File: "podcast.md", Podcast: podcast}); err != nil {
+			t.Fatalf("WriteJSONFeed: %s", err)
+		}
+		feed := &jsonFeed{}
+		if err := json.Unmarshal(buf.Bytes(), feed); err != nil {
+			t.Fatal(err)
+		}
+		if len(feed.Items) != 1 || len(feed.Items[0].Attachments) != expected || feed.Items[0].Attachments[0].MimeType != "audio/mpeg" {
+			t.Errorf("expected %d attachments with podcast %t, got %s", expected, podcast, buf.String())
+		}
+	}
+}
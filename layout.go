@@ -0,0 +1,374 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+// LayoutData is the data a layout template is executed with.
+//
+// Example layout.html:
+//
+//	<!doctype html>
+//	<html lang="{{.Page.Lang}}">
+//	{{.Page.Head}}
+//	<body>
+//	  <header>{{.Site.Header}}</header>
+//	  <div class="columns">
+//	    <main id="main-content">{{.Page.Content}}</main>
+//	    <aside>
+//	      <ul>{{range .Items}}<li><a href="{{.Link}}">{{.Title}}</a></li>{{end}}</ul>
+//	    </aside>
+//	  </div>
+//	  <footer>{{.Site.Footer}}</footer>
+//	</body>
+//	</html>
+type LayoutData struct {
+	// Page describes the page being rendered
+	Page *LayoutPage
+
+	// Items holds the items of an aggregate page, empty otherwise
+	Items []*LayoutItem
+
+	// Pages holds the links of a page-index collection page, empty otherwise
+	Pages []*LayoutPageLink
+
+	// Collection is the collection from antenna.yaml being rendered, it
+	// is nil for pages
+	Collection *Collection
+
+	// Site holds the generator's settings shared by every page
+	Site *LayoutSite
+
+	// FrontMatter holds the front matter of a post or page, nil otherwise
+	FrontMatter map[string]interface{}
+}
+
+// LayoutPage describes the page a layout renders
+type LayoutPage struct {
+	// Title is the page's title, a post's or page's front matter title
+	// takes precedence over the generator's
+	Title string
+
+	// Lang is the generator's lang value
+	Lang string
+
+	// Description is the generator's description
+	Description string
+
+	// Head is the head element the slot model writes, with the meta,
+	// link, script and style elements
+	Head template.HTML
+
+	// Heading is the heading of a tag, author or archive page
+	Heading string
+
+	// Content is what the slot model writes in the main element, the
+	// rendered items, page list or post
+	Content template.HTML
+
+	// Link, PostPath and PubDate are set for posts
+	Link     string
+	PostPath string
+	PubDate  string
+
+	// Pagination is set for a page of a paginated aggregate page
+	Pagination *LayoutPagination
+}
+
+// LayoutPagination describes where a page sits in a paginated aggregate page
+type LayoutPagination struct {
+	Number int
+	Count  int
+	// Prev and Next are the file names of the previous and next pages,
+	// empty on the first and last pages
+	Prev string
+	Next string
+}
+
+// LayoutItem is an item of an aggregate page
+type LayoutItem struct {
+	Link        string
+	Title       string
+	Description string
+	Authors     []*gofeed.Person
+	Enclosures  []*Enclosure
+	GUID        string
+	PubDate     string
+	Updated     string
+	Channel     string
+	Label       string
+	PostPath    string
+	Categories  []string
+
+	// HTML is the item as the slot model renders it, following the
+	// generator's items settings
+	HTML template.HTML
+}
+
+// LayoutPageLink is a link in a page-index collection page
+type LayoutPageLink struct {
+	Href       string
+	Name       string
+	InputPath  string
+	OutputPath string
+}
+
+// LayoutSite holds the generator settings shared by every page
+type LayoutSite struct {
+	AppName string
+	Version string
+	BaseURL string
+
+	// Header, Nav, TopContent, BottomContent and Footer are the
+	// generator's slots so a layout can place them where it likes
+	Header        template.HTML
+	Nav           template.HTML
+	TopContent    template.HTML
+	BottomContent template.HTML
+	Footer        template.HTML
+
+	// Built is the time the site is being generated at
+	Built time.Time
+}
+
+// loadLayout parses the files matching Layout. The template named
+// "layout", when one of the files defines it, is executed, otherwise the
+// first file is.
+func (gen *Generator) loadLayout() (*template.Template, error) {
+	if gen.layout != nil {
+		return gen.layout, nil
+	}
+	files, err := filepath.Glob(gen.Layout)
+	if err != nil {
+		return nil, fmt.Errorf("layout %q: %s", gen.Layout, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("layout %q matches no files", gen.Layout)
+	}
+	tmpl, err := template.New(filepath.Base(files[0])).ParseFiles(files...)
+	if err != nil {
+		return nil, err
+	}
+	if t := tmpl.Lookup("layout"); t != nil {
+		tmpl = t
+	}
+	gen.layout = tmpl
+	return tmpl, nil
+}
+
+// layoutSource returns the names and contents of the files matching
+// Layout, so the build manifest sees a layout change.
+func (gen *Generator) layoutSource() string {
+	if gen.Layout == "" {
+		return ""
+	}
+	files, _ := filepath.Glob(gen.Layout)
+	src := new(strings.Builder)
+	for _, fName := range files {
+		data, err := os.ReadFile(fName)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(src, "%s\n%d\n%s", fName, len(data), data)
+	}
+	return src.String()
+}
+
+// layoutData returns the data for a page with the head element the slot
+// model would write.
+func (gen *Generator) layoutData(postPath string, frontMatter map[string]interface{}) *LayoutData {
+	head := new(bytes.Buffer)
+	gen.writeHeadElement(head, postPath, frontMatter)
+	title := gen.Title
+	if t, ok := frontMatter["title"].(string); ok && t != "" {
+		title = t
+	}
+	data := &LayoutData{
+		Page: &LayoutPage{
+			Title:       title,
+			Lang:        gen.Lang,
+			Description: gen.Description,
+			Head:        template.HTML(strings.TrimSpace(head.String())),
+			PostPath:    postPath,
+		},
+		Collection: gen.collection,
+		Site: &LayoutSite{
+			AppName:       gen.AppName,
+			Version:       gen.Version,
+			BaseURL:       gen.BaseURL,
+			Header:        template.HTML(strings.TrimSpace(gen.Header)),
+			Nav:           template.HTML(strings.TrimSpace(gen.navHTML())),
+			TopContent:    template.HTML(strings.TrimSpace(gen.TopContent)),
+			BottomContent: template.HTML(strings.TrimSpace(gen.BottomContent)),
+			Footer:        template.HTML(strings.TrimSpace(gen.Footer)),
+			Built:         gen.buildTime(),
+		},
+		FrontMatter: frontMatter,
+	}
+	if gen.pagination != nil {
+		data.Page.Pagination = &LayoutPagination{
+			Number: gen.pagination.Number,
+			Count:  gen.pagination.Count,
+			Prev:   gen.pagination.Prev,
+			Next:   gen.pagination.Next,
+		}
+	}
+	return data
+}
+
+// executeLayout renders data through the layout
+func (gen *Generator) executeLayout(out io.Writer, data *LayoutData) error {
+	tmpl, err := gen.loadLayout()
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		return fmt.Errorf("layout %q: %s", gen.Layout, err)
+	}
+	return nil
+}
+
+// writeLayoutItems renders the items returned by sqlStmt through the layout
+func (gen *Generator) writeLayoutItems(out io.Writer, db *sql.DB, heading string, sqlStmt string, args ...any) error {
+	rows, err := db.Query(sqlStmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	data := gen.layoutData("", nil)
+	data.Page.Heading = heading
+	content := new(bytes.Buffer)
+	if heading != "" {
+		fmt.Fprintf(content, "    <h2>%s</h2>\n", html.EscapeString(heading))
+	}
+	for rows.Next() {
+		item, err := gen.scanDisplayItem(rows)
+		if err != nil {
+			fmt.Fprintf(gen.eout, "error (%s): %s\n", sqlStmt, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		omitted, err := gen.writeDisplayItem(buf, item)
+		if err != nil {
+			return err
+		}
+		if omitted {
+			continue
+		}
+		content.Write(buf.Bytes())
+		var categories []string
+		if item.categories != "" {
+			if err := json.Unmarshal([]byte(item.categories), &categories); err != nil {
+				categories = nil
+			}
+		}
+		data.Items = append(data.Items, &LayoutItem{
+			Link:        item.link,
+			Title:       item.title,
+			Description: item.description,
+			Authors:     item.authors,
+			Enclosures:  item.enclosures,
+			GUID:        item.guid,
+			PubDate:     item.pubDate,
+			Updated:     item.updated,
+			Channel:     item.channel,
+			Label:       item.label,
+			PostPath:    item.postPath,
+			Categories:  categories,
+			HTML:        template.HTML(strings.TrimSpace(buf.String())),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if gen.pagination != nil {
+		gen.pagination.write(content)
+	}
+	data.Page.Content = template.HTML(content.String())
+	return gen.executeLayout(out, data)
+}
+
+// writeLayoutPageIndex renders a page-index collection page through the
+// layout
+func (gen *Generator) writeLayoutPageIndex(out io.Writer, pages []*LayoutPageLink) error {
+	data := gen.layoutData("", nil)
+	data.Pages = pages
+	content := new(bytes.Buffer)
+	writePageList(content, pages)
+	data.Page.Content = template.HTML(content.String())
+	return gen.executeLayout(out, data)
+}
+
+// writeListPage writes a page with a heading and content, a list of
+// links, in its main element. It is used for the tag and archive indexes.
+func (gen *Generator) writeListPage(out io.Writer, heading string, content string) error {
+	content = fmt.Sprintf("    <h2>%s</h2>\n%s", html.EscapeString(heading), content)
+	if gen.Layout != "" {
+		data := gen.layoutData("", nil)
+		data.Page.Heading = heading
+		data.Page.Content = template.HTML(content)
+		return gen.executeLayout(out, data)
+	}
+	fmt.Fprintf(out, "<!doctype html>\n<html lang=%q>\n", gen.Lang)
+	gen.writeHeadElement(out, "", nil)
+	fmt.Fprintln(out, "<body>")
+	fmt.Fprintln(out, `  <a href="#main-content" class="skip-link">Skip to main content</a>`)
+	if gen.Header != "" {
+		fmt.Fprintf(out, "  <header>\n    %s\n  </header>\n", indentText(strings.TrimSpace(gen.Header), 4))
+	}
+	if nav := gen.navHTML(); nav != "" {
+		fmt.Fprintf(out, "  <nav aria-label=\"Site navigation\">\n    %s\n  </nav>\n", indentText(strings.TrimSpace(nav), 4))
+	}
+	fmt.Fprintln(out, `  <main id="main-content">`)
+	fmt.Fprint(out, content)
+	fmt.Fprintln(out, "  </main>")
+	if gen.Footer != "" {
+		fmt.Fprintf(out, "  <footer>\n    %s\n  </footer>\n", indentText(strings.TrimSpace(gen.Footer), 4))
+	}
+	fmt.Fprintln(out, "</body>")
+	fmt.Fprintln(out, "</html>")
+	return nil
+}
+
+// writeLayoutPage renders a post or page through the layout
+func (gen *Generator) writeLayoutPage(out io.Writer, link string, postPath string, pubDate string, innerHTML string, frontMatter map[string]interface{}) error {
+	data := gen.layoutData(postPath, frontMatter)
+	data.Page.Link = link
+	data.Page.PubDate = pubDate
+	if pubDate != "" && link != "" {
+		data.Page.Content = template.HTML(fmt.Sprintf("<article data-published=%q data-link=%q>\n%s\n</article>",
+			pubDate, link, innerHTML))
+	} else {
+		data.Page.Content = template.HTML(innerHTML)
+	}
+	return gen.executeLayout(out, data)
+}
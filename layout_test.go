@@ -0,0 +1,142 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestLayout writes the layout files in dName and returns a
+// generator using them.
+func writeTestLayout(t *testing.T, dName string, files map[string]string) *Generator {
+	t.Helper()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dName, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen, _ := NewGenerator("antenna", "https://example.com")
+	gen.eout = io.Discard
+	gen.Title = "Blog"
+	gen.Header = "<h1>Blog</h1>"
+	gen.Footer = "<p>Footer</p>"
+	gen.Layout = filepath.Join(dName, "*.html")
+	return gen
+}
+
+func TestWriteCustomHTMLLayout(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, pubDate, status, categories)
+		VALUES ('https://example.com/one', 'One & Only', 'The first item', '2025-09-01', 'published', '["Go"]')`); err != nil {
+		t.Fatal(err)
+	}
+	gen := writeTestLayout(t, t.TempDir(), map[string]string{
+		"base.html": `{{define "layout"}}<html lang="{{.Page.Lang}}">
+{{.Page.Head}}
+<body>
+<div class="top">{{.Site.Header}}</div>
+<main>{{.Page.Content}}</main>
+{{template "sidebar" .}}
+<div class="bottom">{{.Site.Footer}}</div>
+</body>
+</html>{{end}}`,
+		"sidebar.html": `{{define "sidebar"}}<aside><ul>{{range .Items}}<li data-tags="{{range .Categories}}{{.}}{{end}}">{{.Title}}</li>{{end}}</ul></aside>{{end}}`,
+	})
+	buf := new(bytes.Buffer)
+	if err := gen.WriteHTML(buf, db, "", &Collection{File: "blog.md"}); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, expected := range []string{
+		`<html lang="en-US">`,
+		"<title>Blog</title>",
+		`<div class="top"><h1>Blog</h1></div>`,
+		`<div class="bottom"><p>Footer</p></div>`,
+		`<li data-tags="Go">One &amp; Only</li>`,
+		`data-link="https://example.com/one"`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected %q in the page\n%s", expected, page)
+		}
+	}
+	if strings.Contains(page, "skip-link") {
+		t.Errorf("expected the layout to replace the slot model\n%s", page)
+	}
+}
+
+func TestWriteHtmlPageLayout(t *testing.T) {
+	dName := t.TempDir()
+	gen := writeTestLayout(t, dName, map[string]string{
+		"post.html": `<html>{{.Page.Head}}<body><h1>{{.Page.Title}}</h1><p class="author">{{.FrontMatter.author}}</p>` +
+			`<p class="collection">{{with .Collection}}{{.Title}}{{end}}</p>{{.Page.Content}}</body></html>`,
+	})
+	gen.collection = &Collection{Title: "The Blog"}
+	htmlName := filepath.Join(dName, "hello.html.out")
+	if err := gen.WriteHtmlPage(htmlName, "https://example.com/hello.html", "hello.md", "2025-09-01",
+		"<p>Hello <em>world</em></p>", map[string]interface{}{"title": "Hello", "author": "Jane <Doe>"}); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(htmlName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<h1>Hello</h1>",
+		"<title>Hello</title>",
+		`<p class="author">Jane &lt;Doe&gt;</p>`,
+		`<p class="collection">The Blog</p>`,
+		`<article data-published="2025-09-01" data-link="https://example.com/hello.html">`,
+		"<p>Hello <em>world</em></p>",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in the post\n%s", expected, src)
+		}
+	}
+}
+
+func TestWritePageIndexLayout(t *testing.T) {
+	db := newTestPagesDB(t, [][2]string{{"about.md", "about.html"}})
+	defer db.Close()
+	gen := writeTestLayout(t, t.TempDir(), map[string]string{
+		"index.html": `<nav>{{range .Pages}}<a href="{{.Href}}">{{.Name}}</a>{{end}}</nav><main>{{.Page.Content}}</main>`,
+	})
+	buf := new(bytes.Buffer)
+	if err := gen.WritePageIndex(buf, db); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<nav><a href="/about.html">About</a></nav>`) ||
+		!strings.Contains(buf.String(), `<li><a href="/about.html">About</a></li>`) {
+		t.Errorf("unexpected page index\n%s", buf.String())
+	}
+}
+
+func TestLayoutMissing(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	gen, _ := NewGenerator("antenna", "https://example.com")
+	gen.eout = io.Discard
+	gen.Layout = filepath.Join(t.TempDir(), "missing.html")
+	if err := gen.WriteHTML(io.Discard, db, "", &Collection{}); err == nil {
+		t.Errorf("expected an error for a missing layout")
+	}
+}
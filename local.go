@@ -0,0 +1,252 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// localFetcher reads feeds written to disk by other tools. Links may use
+// the file:// scheme or be plain paths, relative paths are resolved from
+// the directory holding the collection file. A link to a directory is read
+// as a feed where each Markdown file with front matter is an item.
+type localFetcher struct {
+	baseDir string
+}
+
+// localDateLayouts are the layouts tried for dates in front matter
+var localDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// drivePath matches a path starting with a Windows drive letter, e.g.
+// C:/feeds or C:\feeds
+var drivePath = regexp.MustCompile(`^[A-Za-z]:[/\\]`)
+
+// filePath returns the path named by a local feed link, a file:// URL or
+// a plain path. ok is false when href uses another scheme. The host of
+// file://feeds/digest.xml is the first part of a relative path and the
+// leading slash of file:///C:/feeds/digest.xml is dropped.
+func filePath(href string) (fName string, ok bool) {
+	if drivePath.MatchString(href) {
+		return href, true
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		return u.Path, true
+	case "file":
+	default:
+		return "", false
+	}
+	if u.Opaque != "" {
+		// e.g. file:feeds/digest.xml
+		return u.Opaque, true
+	}
+	fName = u.Path
+	if u.Host != "" && u.Host != "localhost" {
+		fName = u.Host + fName
+	}
+	if drivePath.MatchString(strings.TrimPrefix(fName, "/")) {
+		fName = strings.TrimPrefix(fName, "/")
+	}
+	return fName, true
+}
+
+// localPath returns the file system path of a local feed link.
+func (fetcher *localFetcher) localPath(href string) (string, error) {
+	fName, ok := filePath(href)
+	if !ok {
+		return "", fmt.Errorf("%q is not a local path", href)
+	}
+	if fName == "" {
+		return "", fmt.Errorf("no path in %q", href)
+	}
+	fName = filepath.FromSlash(fName)
+	if !filepath.IsAbs(fName) {
+		fName = filepath.Join(fetcher.baseDir, fName)
+	}
+	return fName, nil
+}
+
+// fetchFeed reads the feed at href. The file's modification time stands in
+// for the Last-Modified header so unchanged sources are skipped like a
+// 304 Not Modified response.
+func (fetcher *localFetcher) fetchFeed(href string, cache feedCache) (*feedResponse, error) {
+	fName, err := fetcher.localPath(href)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(fName)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		feed     *gofeed.Feed
+		schedule feedSchedule
+		modified time.Time
+	)
+	if info.IsDir() {
+		feed, modified, err = readMarkdownDir(fName)
+		if err != nil {
+			return nil, err
+		}
+		if cache.LastModified != "" && cache.LastModified == modified.UTC().Format(http.TimeFormat) {
+			return &feedResponse{NotModified: true, LastModified: cache.LastModified}, nil
+		}
+	} else {
+		modified = info.ModTime()
+		if cache.LastModified != "" && cache.LastModified == modified.UTC().Format(http.TimeFormat) {
+			return &feedResponse{NotModified: true, LastModified: cache.LastModified}, nil
+		}
+		src, err := os.ReadFile(fName)
+		if err != nil {
+			return nil, err
+		}
+		feed, schedule, err = parseFeedSource(fileURL(fName), src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &feedResponse{
+		Feed:         feed,
+		LastModified: modified.UTC().Format(http.TimeFormat),
+		Schedule:     schedule,
+	}, nil
+}
+
+// fileURL returns the file:// URL of a path
+func fileURL(fName string) string {
+	if abs, err := filepath.Abs(fName); err == nil {
+		fName = abs
+	}
+	fName = filepath.ToSlash(fName)
+	if drivePath.MatchString(fName) {
+		fName = "/" + fName
+	}
+	return (&url.URL{Scheme: "file", Path: fName}).String()
+}
+
+// readMarkdownDir reads each Markdown file with front matter in dName as
+// a feed item. It returns the feed along with the latest modification
+// time of the directory and its Markdown files.
+func readMarkdownDir(dName string) (*gofeed.Feed, time.Time, error) {
+	entries, err := os.ReadDir(dName)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	feed := &gofeed.Feed{
+		Title:    filepath.Base(dName),
+		Link:     fileURL(dName),
+		FeedType: "directory",
+		Items:    []*gofeed.Item{},
+	}
+	modified := time.Time{}
+	if info, err := os.Stat(dName); err == nil {
+		modified = info.ModTime()
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.ToLower(filepath.Ext(entry.Name())) != ".md" {
+			continue
+		}
+		fName := filepath.Join(dName, entry.Name())
+		if info, err := entry.Info(); err == nil && info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		src, err := os.ReadFile(fName)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		doc := &CommonMark{}
+		if err := doc.Parse(src); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to parse %q: %w", fName, err)
+		}
+		if len(doc.FrontMatter) == 0 {
+			continue
+		}
+		item, err := markdownItem(fName, doc)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("%s, %s", fName, err)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, modified, nil
+}
+
+// markdownItem turns a Markdown document with front matter into a feed
+// item. The document's Markdown is passed along as source:markdown so
+// saveItem keeps it rather than converting the description back.
+func markdownItem(fName string, doc *CommonMark) (*gofeed.Item, error) {
+	innerHTML, err := doc.ToHTML()
+	if err != nil {
+		return nil, err
+	}
+	authors, err := doc.GetPersons("author", false)
+	if err != nil {
+		return nil, err
+	}
+	link := doc.GetAttributeString("link", fileURL(fName))
+	item := &gofeed.Item{
+		Title:       doc.GetAttributeString("title", ""),
+		Link:        link,
+		GUID:        doc.GetAttributeString("guid", link),
+		Description: doc.GetAttributeString("description", doc.GetAttributeString("abstract", "")),
+		Content:     innerHTML,
+		Categories:  doc.GetAttributeStringSlice("categories"),
+		Extensions: ext.Extensions{
+			"source": {
+				"markdown": []ext.Extension{{Name: "markdown", Value: doc.Text}},
+			},
+		},
+	}
+	if item.Description == "" {
+		item.Description = innerHTML
+	}
+	if len(authors) > 0 {
+		item.Authors = authors
+	}
+	item.Published, item.PublishedParsed = parseLocalDate(doc.GetAttributeString("pubDate", doc.GetAttributeString("datePublished", "")))
+	item.Updated, item.UpdatedParsed = parseLocalDate(doc.GetAttributeString("dateModified", ""))
+	return item, nil
+}
+
+// parseLocalDate parses a front matter date, the raw value is returned
+// with a nil time when none of localDateLayouts match.
+func parseLocalDate(val string) (string, *time.Time) {
+	for _, layout := range localDateLayouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return val, &t
+		}
+	}
+	return val, nil
+}
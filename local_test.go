@@ -0,0 +1,161 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHarvestLocalSources(t *testing.T) {
+	dName := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dName, "feeds"), 0775); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dName, "feeds", "digest.xml"), []byte(testFeedRSS), 0664); err != nil {
+		t.Fatal(err)
+	}
+	logDir := filepath.Join(dName, "build-logs")
+	if err := os.MkdirAll(logDir, 0775); err != nil {
+		t.Fatal(err)
+	}
+	posts := map[string]string{
+		"build-42.md": "---\ntitle: Build 42\nlink: https://ci.example.org/42\npubDate: 2026-01-02\n---\n\nAll **green**.\n",
+		"README.md":   "# Not an item\n\nThis file has no front matter.\n",
+	}
+	for name, src := range posts {
+		if err := os.WriteFile(filepath.Join(logDir, name), []byte(src), 0664); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cName := filepath.Join(dName, "local.md")
+	src := fmt.Sprintf("# Local\n\n- [Digest](feeds/digest.xml)\n- [Build logs](%s)\n", fileURL(logDir))
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "local.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	var out, eout bytes.Buffer
+	if err := col.Harvest(&out, &eout, &AppConfig{}, nil); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	if eout.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", eout.String())
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	count := 0
+	if err := db.QueryRow(SQLItemCount).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected an item from the feed file and one from the directory, got %d", count)
+	}
+	var title, sourceMarkdown string
+	if err := db.QueryRow(`SELECT title, sourceMarkdown FROM items WHERE link = ?`, "https://ci.example.org/42").Scan(&title, &sourceMarkdown); err != nil {
+		t.Fatalf("expected the Markdown item to be saved, %s", err)
+	}
+	if title != "Build 42" || !strings.Contains(sourceMarkdown, "All **green**.") {
+		t.Errorf("unexpected Markdown item %q, %q", title, sourceMarkdown)
+	}
+
+	// Unchanged files are skipped on the next harvest
+	out.Reset()
+	if err := col.Harvest(&out, &eout, &AppConfig{}, nil); err != nil {
+		t.Fatalf("Harvest (again): %s", err)
+	}
+	if !strings.Contains(out.String(), "2 of 2 feeds unchanged") {
+		t.Errorf("expected both local sources to be unchanged, got %s", out.String())
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	dName := t.TempDir()
+	fetcher := &localFetcher{baseDir: dName}
+	for href, expected := range map[string]string{
+		"feeds/digest.xml":                filepath.Join(dName, "feeds", "digest.xml"),
+		"file:feeds/digest.xml":           filepath.Join(dName, "feeds", "digest.xml"),
+		"file://feeds/digest.xml":         filepath.Join(dName, "feeds", "digest.xml"),
+		"file:///srv/feeds/digest.xml":    filepath.FromSlash("/srv/feeds/digest.xml"),
+		"file://localhost/srv/digest.xml": filepath.FromSlash("/srv/digest.xml"),
+	} {
+		fName, err := fetcher.localPath(href)
+		if err != nil {
+			t.Errorf("localPath(%q): %s", href, err)
+		} else if fName != expected {
+			t.Errorf("localPath(%q) = %q, expected %q", href, fName, expected)
+		}
+	}
+	if _, err := fetcher.localPath("https://example.org/feed.xml"); err == nil {
+		t.Errorf("expected an error for a web link")
+	}
+}
+
+func TestFilePathDriveLetters(t *testing.T) {
+	for href, expected := range map[string]string{
+		"file:///C:/feeds/digest.xml": "C:/feeds/digest.xml",
+		"file:///c:/feeds/digest.xml": "c:/feeds/digest.xml",
+		"C:/feeds/digest.xml":         "C:/feeds/digest.xml",
+		`C:\feeds\digest.xml`:         `C:\feeds\digest.xml`,
+	} {
+		fName, ok := filePath(href)
+		if !ok || fName != expected {
+			t.Errorf("filePath(%q) = %q, %t, expected %q", href, fName, ok, expected)
+		}
+	}
+	// The drive letter isn't taken as a URL scheme
+	fetcher := &localFetcher{baseDir: t.TempDir()}
+	fetchers := feedFetchers{"file": fetcher}
+	if _, err := fetchers.fetchFeed("C:/feeds/digest.xml", feedCache{}); err == nil || strings.Contains(err.Error(), "unsupported URL scheme") {
+		t.Errorf("expected the drive path to be read as a file, got %v", err)
+	}
+}
+
+func TestHarvestFileHostPath(t *testing.T) {
+	dName := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dName, "feeds"), 0775); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dName, "feeds", "digest.xml"), []byte(testFeedRSS), 0664); err != nil {
+		t.Fatal(err)
+	}
+	cName := filepath.Join(dName, "local.md")
+	if err := os.WriteFile(cName, []byte("# Local\n\n- [Digest](file://feeds/digest.xml)\n"), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "local.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	var out, eout bytes.Buffer
+	if err := col.Harvest(&out, &eout, &AppConfig{}, nil); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	if eout.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", eout.String())
+	}
+}
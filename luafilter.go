@@ -0,0 +1,1134 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	// 3rd Party Packages
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	lua "github.com/yuin/gopher-lua"
+)
+
+/*
+ * cm_filters are Lua filters, in the style of Pandoc's Lua filters, run
+ * over the parsed CommonMark document before it is rendered as HTML.
+ *
+ * A filter defines functions named for the elements they change, Header,
+ * Link, Para and so on. Each is called with the element as a table and
+ * returns nil to leave it alone, the element, a replacement element or a
+ * list of elements, an empty list removes it. Like Pandoc, the inline
+ * elements of the whole document are filtered before the blocks, each
+ * from the leaves up. A filter may instead return a list of filter tables
+ * which are run in turn.
+ *
+ * Example, links-to-html.lua:
+ *
+ *	function Link(el)
+ *	  el.target = string.gsub(el.target, "%.md", ".html")
+ *	  return el
+ *	end
+ */
+
+var (
+	// kindLuaRawBlock and kindLuaRawInline are the kinds of the HTML a
+	// filter returns with pandoc.RawBlock and pandoc.RawInline.
+	kindLuaRawBlock  = ast.NewNodeKind("LuaRawBlock")
+	kindLuaRawInline = ast.NewNodeKind("LuaRawInline")
+
+	// luaInlineTags and luaBlockTags are the elements a filter can name,
+	// "Inline" and "Block" are called for any inline or block element
+	// without a function of its own.
+	luaInlineTags = []string{"Str", "Space", "SoftBreak", "LineBreak", "Emph", "Strong",
+		"Strikeout", "Code", "Link", "Image", "RawInline", "Inline"}
+	luaBlockTags = []string{"Header", "Para", "Plain", "CodeBlock", "RawBlock",
+		"HorizontalRule", "BlockQuote", "BulletList", "OrderedList", "Block"}
+)
+
+// luaRawBlock is HTML a filter put in place of a block
+type luaRawBlock struct {
+	ast.BaseBlock
+	HTML string
+}
+
+// Kind implements ast.Node
+func (n *luaRawBlock) Kind() ast.NodeKind {
+	return kindLuaRawBlock
+}
+
+// Dump implements ast.Node
+func (n *luaRawBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"HTML": n.HTML}, nil)
+}
+
+// luaRawInline is HTML a filter put in place of an inline
+type luaRawInline struct {
+	ast.BaseInline
+	HTML string
+}
+
+// Kind implements ast.Node
+func (n *luaRawInline) Kind() ast.NodeKind {
+	return kindLuaRawInline
+}
+
+// Dump implements ast.Node
+func (n *luaRawInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"HTML": n.HTML}, nil)
+}
+
+// luaRawRenderer writes the HTML of luaRawBlock and luaRawInline as is
+type luaRawRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *luaRawRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindLuaRawBlock, r.renderRawBlock)
+	reg.Register(kindLuaRawInline, r.renderRawInline)
+}
+
+func (r *luaRawRenderer) renderRawBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		src := n.(*luaRawBlock).HTML
+		w.WriteString(src)
+		if !strings.HasSuffix(src, "\n") {
+			w.WriteByte('\n')
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *luaRawRenderer) renderRawInline(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(n.(*luaRawInline).HTML)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// convertMarkdown renders src as HTML with md like md.Convert, running
+// the Lua filters over the parsed document first.
+func convertMarkdown(md goldmark.Markdown, src []byte, filters []string, out io.Writer) error {
+	if len(filters) == 0 {
+		return md.Convert(src, out)
+	}
+	doc := md.Parser().Parse(text.NewReader(src))
+	for _, fName := range filters {
+		if err := runLuaFilter(fName, doc, src); err != nil {
+			return err
+		}
+	}
+	md.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&luaRawRenderer{}, 500)))
+	return md.Renderer().Render(out, src, doc)
+}
+
+// filterSource returns the names and contents of the cm_filters, so the
+// build manifest sees a filter change.
+func (gen *Generator) filterSource() string {
+	src := new(strings.Builder)
+	for _, fName := range gen.CMarkFilters {
+		data, err := os.ReadFile(fName)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(src, "%s\n%d\n%s", fName, len(data), data)
+	}
+	return src.String()
+}
+
+// luaFilter runs a filter script over a document
+type luaFilter struct {
+	L      *lua.LState
+	fName  string
+	source []byte
+}
+
+// runLuaFilter runs the Lua filter in fName over doc, parsed from source
+func runLuaFilter(fName string, doc ast.Node, source []byte) error {
+	L := lua.NewState()
+	defer L.Close()
+	f := &luaFilter{L: L, fName: fName, source: source}
+	L.SetGlobal("FORMAT", lua.LString("html"))
+	L.SetGlobal("pandoc", f.pandocModule())
+	fn, err := L.LoadFile(fName)
+	if err != nil {
+		return fmt.Errorf("cm_filters %s: %s", fName, err)
+	}
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		return fmt.Errorf("cm_filters %s: %s", fName, err)
+	}
+	ret := L.Get(-1)
+	L.Pop(1)
+	for _, filter := range f.filterTables(ret) {
+		if err := f.apply(filter, doc); err != nil {
+			return fmt.Errorf("cm_filters %s: %s", fName, err)
+		}
+	}
+	return nil
+}
+
+// filterTables returns the filters a script defines, the list of filter
+// tables it returns, the filter table it returns or its global functions.
+func (f *luaFilter) filterTables(ret lua.LValue) []*lua.LTable {
+	if tbl, ok := ret.(*lua.LTable); ok {
+		if tbl.Len() == 0 {
+			return []*lua.LTable{tbl}
+		}
+		filters := []*lua.LTable{}
+		for i := 1; i <= tbl.Len(); i++ {
+			if filter, ok := tbl.RawGetInt(i).(*lua.LTable); ok {
+				filters = append(filters, filter)
+			}
+		}
+		return filters
+	}
+	filter := f.L.NewTable()
+	for _, tag := range append(luaInlineTags, luaBlockTags...) {
+		if fn, ok := f.L.GetGlobal(tag).(*lua.LFunction); ok {
+			filter.RawSetString(tag, fn)
+		}
+	}
+	return []*lua.LTable{filter}
+}
+
+// lookup returns the filter's function for tag, falling back to fallback
+func lookup(filter *lua.LTable, tag string, fallback string) *lua.LFunction {
+	if fn, ok := filter.RawGetString(tag).(*lua.LFunction); ok {
+		return fn
+	}
+	if fn, ok := filter.RawGetString(fallback).(*lua.LFunction); ok {
+		return fn
+	}
+	return nil
+}
+
+// postOrder returns the nodes under doc with each node's children before it
+func postOrder(doc ast.Node) []ast.Node {
+	nodes := []ast.Node{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering && n != doc {
+			nodes = append(nodes, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	return nodes
+}
+
+// apply runs a filter table over doc, the inlines first then the blocks
+func (f *luaFilter) apply(filter *lua.LTable, doc ast.Node) error {
+	for _, n := range postOrder(doc) {
+		if n.Parent() == nil || n.Type() != ast.TypeInline {
+			continue
+		}
+		if err := f.filterInline(filter, n); err != nil {
+			return err
+		}
+	}
+	for _, n := range postOrder(doc) {
+		if n.Parent() == nil || n.Type() != ast.TypeBlock {
+			continue
+		}
+		if err := f.filterBlock(filter, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call calls fn with el and returns the elements it returned. It reports
+// false when the element is left unchanged.
+func (f *luaFilter) call(fn *lua.LFunction, el *lua.LTable) ([]lua.LValue, bool, error) {
+	before := luaFingerprint(el)
+	if err := f.L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, el); err != nil {
+		return nil, false, err
+	}
+	ret := f.L.Get(-1)
+	f.L.Pop(1)
+	switch ret := ret.(type) {
+	case *lua.LNilType:
+		return nil, false, nil
+	case *lua.LTable:
+		if ret == el && luaFingerprint(el) == before {
+			return nil, false, nil
+		}
+		if ret.RawGetString("t") != lua.LNil {
+			return []lua.LValue{ret}, true, nil
+		}
+		results := []lua.LValue{}
+		for i := 1; i <= ret.Len(); i++ {
+			results = append(results, ret.RawGetInt(i))
+		}
+		return results, true, nil
+	}
+	return nil, false, fmt.Errorf("%s filter returned %s, expected an element or list", el.RawGetString("t"), ret.Type())
+}
+
+// filterInline runs the filter's function for an inline node, a text
+// node is filtered as its Str, Space and SoftBreak elements.
+func (f *luaFilter) filterInline(filter *lua.LTable, n ast.Node) error {
+	els := f.inlineElements(n)
+	changed := false
+	results := []lua.LValue{}
+	for _, el := range els {
+		tbl := el.(*lua.LTable)
+		tag := tbl.RawGetString("t").String()
+		fn := lookup(filter, tag, "Inline")
+		if tag == "Node" || fn == nil {
+			results = append(results, el)
+			continue
+		}
+		ret, ok, err := f.call(fn, tbl)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			results = append(results, el)
+			continue
+		}
+		changed = true
+		results = append(results, ret...)
+	}
+	if !changed {
+		return nil
+	}
+	nodes, err := f.toInlines(results)
+	if err != nil {
+		return err
+	}
+	replaceNode(n, nodes)
+	return nil
+}
+
+// filterBlock runs the filter's function for a block node
+func (f *luaFilter) filterBlock(filter *lua.LTable, n ast.Node) error {
+	el := f.blockElement(n)
+	if el == nil {
+		return nil
+	}
+	fn := lookup(filter, el.RawGetString("t").String(), "Block")
+	if fn == nil {
+		return nil
+	}
+	ret, ok, err := f.call(fn, el)
+	if err != nil || !ok {
+		return err
+	}
+	nodes, err := f.toBlocks(ret)
+	if err != nil {
+		return err
+	}
+	replaceNode(n, nodes)
+	return nil
+}
+
+// replaceNode puts nodes in the place of n
+func replaceNode(n ast.Node, nodes []ast.Node) {
+	parent, prev := n.Parent(), n.PreviousSibling()
+	parent.RemoveChild(parent, n)
+	for _, node := range nodes {
+		switch {
+		case prev != nil:
+			parent.InsertAfter(parent, prev, node)
+		case parent.FirstChild() != nil:
+			parent.InsertBefore(parent, parent.FirstChild(), node)
+		default:
+			parent.AppendChild(parent, node)
+		}
+		prev = node
+	}
+}
+
+/*
+ * Go to Lua, goldmark nodes become Pandoc style elements
+ */
+
+// element returns a new element table for tag
+func (f *luaFilter) element(tag string) *lua.LTable {
+	el := f.L.NewTable()
+	el.RawSetString("t", lua.LString(tag))
+	el.RawSetString("tag", lua.LString(tag))
+	return el
+}
+
+// list returns a Lua list of values
+func (f *luaFilter) list(values []lua.LValue) *lua.LTable {
+	tbl := f.L.NewTable()
+	for _, v := range values {
+		tbl.Append(v)
+	}
+	return tbl
+}
+
+// words splits s into Str and Space elements
+func (f *luaFilter) words(s string) []lua.LValue {
+	els := []lua.LValue{}
+	word := new(strings.Builder)
+	space := false
+	flush := func() {
+		if word.Len() > 0 {
+			el := f.element("Str")
+			el.RawSetString("text", lua.LString(word.String()))
+			els = append(els, el)
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			flush()
+			if !space {
+				els = append(els, f.element("Space"))
+			}
+			space = true
+			continue
+		}
+		space = false
+		word.WriteRune(r)
+	}
+	flush()
+	return els
+}
+
+// nodeText returns the text under n
+func (f *luaFilter) nodeText(n ast.Node) string {
+	buf := new(bytes.Buffer)
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			buf.Write(c.Segment.Value(f.source))
+		case *ast.String:
+			buf.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.String()
+}
+
+// lines returns the source lines of a block
+func (f *luaFilter) lines(n ast.Node) string {
+	buf := new(bytes.Buffer)
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(f.source))
+	}
+	return buf.String()
+}
+
+// setAttr copies the attributes of n to el as identifier, classes and
+// attributes.
+func (f *luaFilter) setAttr(el *lua.LTable, n ast.Node) {
+	classes := f.L.NewTable()
+	attributes := f.L.NewTable()
+	el.RawSetString("identifier", lua.LString(""))
+	for _, attr := range n.Attributes() {
+		value := ""
+		switch v := attr.Value.(type) {
+		case []byte:
+			value = string(v)
+		case string:
+			value = v
+		default:
+			value = fmt.Sprintf("%v", v)
+		}
+		switch string(attr.Name) {
+		case "id":
+			el.RawSetString("identifier", lua.LString(value))
+		case "class":
+			for _, class := range strings.Fields(value) {
+				classes.Append(lua.LString(class))
+			}
+		default:
+			attributes.RawSetString(string(attr.Name), lua.LString(value))
+		}
+	}
+	el.RawSetString("classes", classes)
+	el.RawSetString("attributes", attributes)
+}
+
+// opaque returns a Node element holding a node filters can't change, it is
+// put back as is.
+func (f *luaFilter) opaque(n ast.Node) *lua.LTable {
+	el := f.element("Node")
+	ud := f.L.NewUserData()
+	ud.Value = n
+	el.RawSetString("node", ud)
+	el.RawSetString("text", lua.LString(f.nodeText(n)))
+	return el
+}
+
+// inlines returns the elements of the inline children of n
+func (f *luaFilter) inlines(n ast.Node) *lua.LTable {
+	els := []lua.LValue{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		els = append(els, f.inlineElements(c)...)
+	}
+	return f.list(els)
+}
+
+// blocks returns the elements of the block children of n
+func (f *luaFilter) blocks(n ast.Node) *lua.LTable {
+	els := []lua.LValue{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if el := f.blockElement(c); el != nil {
+			els = append(els, el)
+		} else {
+			els = append(els, f.opaque(c))
+		}
+	}
+	return f.list(els)
+}
+
+// inlineElements returns the elements of an inline node, a text node is
+// several.
+func (f *luaFilter) inlineElements(n ast.Node) []lua.LValue {
+	var el *lua.LTable
+	switch n := n.(type) {
+	case *ast.Text:
+		els := f.words(string(n.Segment.Value(f.source)))
+		if n.HardLineBreak() {
+			els = append(els, f.element("LineBreak"))
+		} else if n.SoftLineBreak() {
+			els = append(els, f.element("SoftBreak"))
+		}
+		return els
+	case *ast.String:
+		if n.IsCode() || n.IsRaw() {
+			break
+		}
+		return f.words(string(n.Value))
+	case *ast.Emphasis:
+		el = f.element("Emph")
+		if n.Level == 2 {
+			el = f.element("Strong")
+		}
+		el.RawSetString("content", f.inlines(n))
+	case *east.Strikethrough:
+		el = f.element("Strikeout")
+		el.RawSetString("content", f.inlines(n))
+	case *ast.CodeSpan:
+		el = f.element("Code")
+		el.RawSetString("text", lua.LString(f.nodeText(n)))
+		f.setAttr(el, n)
+	case *ast.Link:
+		el = f.element("Link")
+		el.RawSetString("content", f.inlines(n))
+		el.RawSetString("target", lua.LString(n.Destination))
+		el.RawSetString("title", lua.LString(n.Title))
+		f.setAttr(el, n)
+	case *ast.AutoLink:
+		el = f.element("Link")
+		el.RawSetString("content", f.list(f.words(string(n.Label(f.source)))))
+		el.RawSetString("target", lua.LString(n.URL(f.source)))
+		el.RawSetString("title", lua.LString(""))
+		f.setAttr(el, n)
+	case *ast.Image:
+		el = f.element("Image")
+		el.RawSetString("caption", f.inlines(n))
+		el.RawSetString("src", lua.LString(n.Destination))
+		el.RawSetString("title", lua.LString(n.Title))
+		f.setAttr(el, n)
+	case *ast.RawHTML:
+		buf := new(bytes.Buffer)
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			buf.Write(segment.Value(f.source))
+		}
+		el = f.element("RawInline")
+		el.RawSetString("format", lua.LString("html"))
+		el.RawSetString("text", lua.LString(buf.String()))
+	case *luaRawInline:
+		el = f.element("RawInline")
+		el.RawSetString("format", lua.LString("html"))
+		el.RawSetString("text", lua.LString(n.HTML))
+	}
+	if el == nil {
+		el = f.opaque(n)
+	}
+	return []lua.LValue{el}
+}
+
+// blockElement returns the element of a block node, nil for the blocks
+// filters aren't called for.
+func (f *luaFilter) blockElement(n ast.Node) *lua.LTable {
+	var el *lua.LTable
+	switch n := n.(type) {
+	case *ast.Heading:
+		el = f.element("Header")
+		el.RawSetString("level", lua.LNumber(n.Level))
+		el.RawSetString("content", f.inlines(n))
+		f.setAttr(el, n)
+	case *ast.Paragraph:
+		el = f.element("Para")
+		el.RawSetString("content", f.inlines(n))
+	case *ast.TextBlock:
+		el = f.element("Plain")
+		el.RawSetString("content", f.inlines(n))
+	case *ast.FencedCodeBlock:
+		el = f.element("CodeBlock")
+		el.RawSetString("text", lua.LString(f.lines(n)))
+		f.setAttr(el, n)
+		if lang := n.Language(f.source); len(lang) > 0 {
+			el.RawGetString("classes").(*lua.LTable).Append(lua.LString(lang))
+		}
+	case *ast.CodeBlock:
+		el = f.element("CodeBlock")
+		el.RawSetString("text", lua.LString(f.lines(n)))
+		f.setAttr(el, n)
+	case *ast.HTMLBlock:
+		src := f.lines(n)
+		if n.HasClosure() {
+			src += string(n.ClosureLine.Value(f.source))
+		}
+		el = f.element("RawBlock")
+		el.RawSetString("format", lua.LString("html"))
+		el.RawSetString("text", lua.LString(src))
+	case *luaRawBlock:
+		el = f.element("RawBlock")
+		el.RawSetString("format", lua.LString("html"))
+		el.RawSetString("text", lua.LString(n.HTML))
+	case *ast.ThematicBreak:
+		el = f.element("HorizontalRule")
+	case *ast.Blockquote:
+		el = f.element("BlockQuote")
+		el.RawSetString("content", f.blocks(n))
+	case *ast.List:
+		el = f.element("BulletList")
+		if n.IsOrdered() {
+			el = f.element("OrderedList")
+			el.RawSetString("start", lua.LNumber(n.Start))
+		}
+		items := f.L.NewTable()
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			items.Append(f.blocks(c))
+		}
+		el.RawSetString("content", items)
+	}
+	return el
+}
+
+/*
+ * Lua to Go, elements become goldmark nodes
+ */
+
+// field returns the string field of an element
+func field(el *lua.LTable, name string) string {
+	if v := el.RawGetString(name); v != lua.LNil {
+		return lua.LVAsString(v)
+	}
+	return ""
+}
+
+// values returns the values of a Lua list, a single element is a list of
+// one.
+func values(v lua.LValue) []lua.LValue {
+	tbl, ok := v.(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	if tbl.RawGetString("t") != lua.LNil {
+		return []lua.LValue{tbl}
+	}
+	list := []lua.LValue{}
+	for i := 1; i <= tbl.Len(); i++ {
+		list = append(list, tbl.RawGetInt(i))
+	}
+	return list
+}
+
+// applyAttr sets the identifier, classes and attributes of el on n
+func applyAttr(n ast.Node, el *lua.LTable) {
+	if id := field(el, "identifier"); id != "" {
+		n.SetAttributeString("id", []byte(id))
+	}
+	classes := []string{}
+	for _, class := range values(el.RawGetString("classes")) {
+		classes = append(classes, lua.LVAsString(class))
+	}
+	if len(classes) > 0 {
+		n.SetAttributeString("class", []byte(strings.Join(classes, " ")))
+	}
+	if attributes, ok := el.RawGetString("attributes").(*lua.LTable); ok {
+		attributes.ForEach(func(k lua.LValue, v lua.LValue) {
+			n.SetAttributeString(lua.LVAsString(k), []byte(lua.LVAsString(v)))
+		})
+	}
+}
+
+// elementTable checks v is an element and returns it with its tag
+func elementTable(v lua.LValue) (*lua.LTable, string, error) {
+	el, ok := v.(*lua.LTable)
+	if !ok || el.RawGetString("t") == lua.LNil {
+		return nil, "", fmt.Errorf("expected an element, got %s", v.Type())
+	}
+	return el, field(el, "t"), nil
+}
+
+// originalNode returns the node held by a Node element
+func originalNode(el *lua.LTable) ast.Node {
+	if ud, ok := el.RawGetString("node").(*lua.LUserData); ok {
+		if n, ok := ud.Value.(ast.Node); ok {
+			return n
+		}
+	}
+	return nil
+}
+
+// toInlines returns the nodes of a list of inline elements
+func (f *luaFilter) toInlines(els []lua.LValue) ([]ast.Node, error) {
+	nodes := []ast.Node{}
+	for _, v := range els {
+		el, tag, err := elementTable(v)
+		if err != nil {
+			return nil, err
+		}
+		var n ast.Node
+		switch tag {
+		case "Str":
+			n = ast.NewString([]byte(field(el, "text")))
+		case "Space":
+			n = ast.NewString([]byte(" "))
+		case "SoftBreak":
+			n = ast.NewString([]byte("\n"))
+		case "LineBreak":
+			n = &luaRawInline{HTML: "<br />\n"}
+		case "Emph", "Strong", "Strikeout":
+			if tag == "Strikeout" {
+				n = east.NewStrikethrough()
+			} else if tag == "Strong" {
+				n = ast.NewEmphasis(2)
+			} else {
+				n = ast.NewEmphasis(1)
+			}
+			if err := f.appendInlines(n, el.RawGetString("content")); err != nil {
+				return nil, err
+			}
+		case "Code":
+			n = &luaRawInline{HTML: "<code>" + html.EscapeString(field(el, "text")) + "</code>"}
+		case "Link":
+			link := ast.NewLink()
+			link.Destination = []byte(field(el, "target"))
+			if title := field(el, "title"); title != "" {
+				link.Title = []byte(title)
+			}
+			applyAttr(link, el)
+			if err := f.appendInlines(link, el.RawGetString("content")); err != nil {
+				return nil, err
+			}
+			n = link
+		case "Image":
+			link := ast.NewLink()
+			link.Destination = []byte(field(el, "src"))
+			if title := field(el, "title"); title != "" {
+				link.Title = []byte(title)
+			}
+			img := ast.NewImage(link)
+			applyAttr(img, el)
+			if err := f.appendInlines(img, el.RawGetString("caption")); err != nil {
+				return nil, err
+			}
+			n = img
+		case "RawInline":
+			if field(el, "format") != "html" {
+				// Like Pandoc, raw content for other formats is dropped
+				continue
+			}
+			n = &luaRawInline{HTML: field(el, "text")}
+		case "Node":
+			if n = originalNode(el); n == nil {
+				return nil, fmt.Errorf("Node element without a node")
+			}
+		default:
+			return nil, fmt.Errorf("%s isn't an inline element", tag)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// appendInlines appends the nodes of the inline elements in content, or
+// the words of a string, to n
+func (f *luaFilter) appendInlines(n ast.Node, content lua.LValue) error {
+	nodes, err := f.toInlines(values(f.inlineArg(content)))
+	if err != nil {
+		return err
+	}
+	for _, c := range nodes {
+		n.AppendChild(n, c)
+	}
+	return nil
+}
+
+// appendBlocks appends the nodes of the block elements in content to n
+func (f *luaFilter) appendBlocks(n ast.Node, content lua.LValue) error {
+	nodes, err := f.toBlocks(values(content))
+	if err != nil {
+		return err
+	}
+	for _, c := range nodes {
+		n.AppendChild(n, c)
+	}
+	return nil
+}
+
+// toBlocks returns the nodes of a list of block elements
+func (f *luaFilter) toBlocks(els []lua.LValue) ([]ast.Node, error) {
+	nodes := []ast.Node{}
+	for _, v := range els {
+		el, tag, err := elementTable(v)
+		if err != nil {
+			return nil, err
+		}
+		var n ast.Node
+		switch tag {
+		case "Header":
+			level := int(lua.LVAsNumber(el.RawGetString("level")))
+			if level < 1 || level > 6 {
+				return nil, fmt.Errorf("Header level %d out of range", level)
+			}
+			n = ast.NewHeading(level)
+			applyAttr(n, el)
+			if err := f.appendInlines(n, el.RawGetString("content")); err != nil {
+				return nil, err
+			}
+		case "Para", "Plain":
+			if tag == "Para" {
+				n = ast.NewParagraph()
+			} else {
+				n = ast.NewTextBlock()
+			}
+			if err := f.appendInlines(n, el.RawGetString("content")); err != nil {
+				return nil, err
+			}
+		case "CodeBlock":
+			code := field(el, "text")
+			if code != "" && !strings.HasSuffix(code, "\n") {
+				code += "\n"
+			}
+			opts := &codeOptions{LineStart: 1}
+			if classes := values(el.RawGetString("classes")); len(classes) > 0 {
+				opts.Language = lua.LVAsString(classes[0])
+			}
+			buf := new(bytes.Buffer)
+			if err := highlightCode(buf, code, opts); err != nil {
+				return nil, err
+			}
+			n = &luaRawBlock{HTML: buf.String()}
+		case "RawBlock":
+			if field(el, "format") != "html" {
+				// Like Pandoc, raw content for other formats is dropped
+				continue
+			}
+			n = &luaRawBlock{HTML: field(el, "text")}
+		case "HorizontalRule":
+			n = ast.NewThematicBreak()
+		case "BlockQuote":
+			n = ast.NewBlockquote()
+			if err := f.appendBlocks(n, el.RawGetString("content")); err != nil {
+				return nil, err
+			}
+		case "BulletList", "OrderedList":
+			list := ast.NewList('-')
+			if tag == "OrderedList" {
+				list = ast.NewList('.')
+				list.Start = 1
+				if start := el.RawGetString("start"); start != lua.LNil {
+					list.Start = int(lua.LVAsNumber(start))
+				}
+			}
+			for _, item := range values(el.RawGetString("content")) {
+				li := ast.NewListItem(0)
+				if err := f.appendBlocks(li, item); err != nil {
+					return nil, err
+				}
+				list.AppendChild(list, li)
+			}
+			n = list
+		case "Node":
+			if n = originalNode(el); n == nil {
+				return nil, fmt.Errorf("Node element without a node")
+			}
+		default:
+			return nil, fmt.Errorf("%s isn't a block element", tag)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// luaFingerprint returns a string describing v so a filter changing an
+// element in place can be told from one returning it unchanged.
+func luaFingerprint(v lua.LValue) string {
+	buf := new(strings.Builder)
+	writeFingerprint(buf, v, 0)
+	return buf.String()
+}
+
+func writeFingerprint(buf *strings.Builder, v lua.LValue, depth int) {
+	switch v := v.(type) {
+	case *lua.LTable:
+		if depth > 64 {
+			buf.WriteString("{…}")
+			return
+		}
+		keys := []string{}
+		fields := map[string]lua.LValue{}
+		v.ForEach(func(k lua.LValue, value lua.LValue) {
+			key := k.Type().String() + ":" + lua.LVAsString(k)
+			keys = append(keys, key)
+			fields[key] = value
+		})
+		sort.Strings(keys)
+		buf.WriteString("{")
+		for _, key := range keys {
+			fmt.Fprintf(buf, "%q=", key)
+			writeFingerprint(buf, fields[key], depth+1)
+			buf.WriteString(",")
+		}
+		buf.WriteString("}")
+	case *lua.LUserData:
+		fmt.Fprintf(buf, "%p", v.Value)
+	case lua.LString:
+		fmt.Fprintf(buf, "%q", string(v))
+	default:
+		buf.WriteString(v.String())
+	}
+}
+
+/*
+ * The pandoc module, the element constructors and pandoc.utils
+ */
+
+// pandocModule returns the pandoc table filters build elements with
+func (f *luaFilter) pandocModule() *lua.LTable {
+	L := f.L
+	mod := L.NewTable()
+	set := func(name string, fn lua.LGFunction) {
+		mod.RawSetString(name, L.NewFunction(fn))
+	}
+	textFn := func(tag string) lua.LGFunction {
+		return func(L *lua.LState) int {
+			el := f.element(tag)
+			el.RawSetString("text", lua.LString(L.CheckString(1)))
+			if tag == "Code" {
+				f.setAttrArg(el, L.Get(2))
+			}
+			L.Push(el)
+			return 1
+		}
+	}
+	empty := func(tag string) lua.LGFunction {
+		return func(L *lua.LState) int {
+			L.Push(f.element(tag))
+			return 1
+		}
+	}
+	content := func(tag string) lua.LGFunction {
+		return func(L *lua.LState) int {
+			el := f.element(tag)
+			el.RawSetString("content", f.inlineArg(L.Get(1)))
+			L.Push(el)
+			return 1
+		}
+	}
+	raw := func(tag string) lua.LGFunction {
+		return func(L *lua.LState) int {
+			el := f.element(tag)
+			el.RawSetString("format", lua.LString(L.CheckString(1)))
+			el.RawSetString("text", lua.LString(L.CheckString(2)))
+			L.Push(el)
+			return 1
+		}
+	}
+	set("Str", textFn("Str"))
+	set("Code", textFn("Code"))
+	set("Space", empty("Space"))
+	set("SoftBreak", empty("SoftBreak"))
+	set("LineBreak", empty("LineBreak"))
+	set("HorizontalRule", empty("HorizontalRule"))
+	set("Emph", content("Emph"))
+	set("Strong", content("Strong"))
+	set("Strikeout", content("Strikeout"))
+	set("Para", content("Para"))
+	set("Plain", content("Plain"))
+	set("RawInline", raw("RawInline"))
+	set("RawBlock", raw("RawBlock"))
+	set("Link", func(L *lua.LState) int {
+		el := f.element("Link")
+		el.RawSetString("content", f.inlineArg(L.Get(1)))
+		el.RawSetString("target", lua.LString(L.CheckString(2)))
+		el.RawSetString("title", lua.LString(L.OptString(3, "")))
+		f.setAttrArg(el, L.Get(4))
+		L.Push(el)
+		return 1
+	})
+	set("Image", func(L *lua.LState) int {
+		el := f.element("Image")
+		el.RawSetString("caption", f.inlineArg(L.Get(1)))
+		el.RawSetString("src", lua.LString(L.CheckString(2)))
+		el.RawSetString("title", lua.LString(L.OptString(3, "")))
+		f.setAttrArg(el, L.Get(4))
+		L.Push(el)
+		return 1
+	})
+	set("Header", func(L *lua.LState) int {
+		el := f.element("Header")
+		el.RawSetString("level", lua.LNumber(L.CheckInt(1)))
+		el.RawSetString("content", f.inlineArg(L.Get(2)))
+		f.setAttrArg(el, L.Get(3))
+		L.Push(el)
+		return 1
+	})
+	set("CodeBlock", func(L *lua.LState) int {
+		el := f.element("CodeBlock")
+		el.RawSetString("text", lua.LString(L.CheckString(1)))
+		f.setAttrArg(el, L.Get(2))
+		L.Push(el)
+		return 1
+	})
+	set("BlockQuote", func(L *lua.LState) int {
+		el := f.element("BlockQuote")
+		el.RawSetString("content", f.list(values(L.Get(1))))
+		L.Push(el)
+		return 1
+	})
+	set("BulletList", func(L *lua.LState) int {
+		el := f.element("BulletList")
+		el.RawSetString("content", f.list(values(L.Get(1))))
+		L.Push(el)
+		return 1
+	})
+	set("OrderedList", func(L *lua.LState) int {
+		el := f.element("OrderedList")
+		el.RawSetString("content", f.list(values(L.Get(1))))
+		el.RawSetString("start", lua.LNumber(L.OptInt(2, 1)))
+		L.Push(el)
+		return 1
+	})
+	set("Attr", func(L *lua.LState) int {
+		attr := f.element("Attr")
+		attr.RawSetString("identifier", lua.LString(L.OptString(1, "")))
+		classes, ok := L.Get(2).(*lua.LTable)
+		if !ok {
+			classes = L.NewTable()
+		}
+		attributes, ok := L.Get(3).(*lua.LTable)
+		if !ok {
+			attributes = L.NewTable()
+		}
+		attr.RawSetString("classes", classes)
+		attr.RawSetString("attributes", attributes)
+		L.Push(attr)
+		return 1
+	})
+	utils := L.NewTable()
+	utils.RawSetString("stringify", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(stringify(L.Get(1))))
+		return 1
+	}))
+	mod.RawSetString("utils", utils)
+	return mod
+}
+
+// inlineArg returns the inline list of a constructor's content argument,
+// a string is split into Str and Space elements.
+func (f *luaFilter) inlineArg(v lua.LValue) *lua.LTable {
+	if s, ok := v.(lua.LString); ok {
+		return f.list(f.words(string(s)))
+	}
+	return f.list(values(v))
+}
+
+// setAttrArg copies a constructor's pandoc.Attr argument to el
+func (f *luaFilter) setAttrArg(el *lua.LTable, v lua.LValue) {
+	attr, ok := v.(*lua.LTable)
+	if !ok {
+		el.RawSetString("identifier", lua.LString(""))
+		el.RawSetString("classes", f.L.NewTable())
+		el.RawSetString("attributes", f.L.NewTable())
+		return
+	}
+	for _, name := range []string{"identifier", "classes", "attributes"} {
+		el.RawSetString(name, attr.RawGetString(name))
+	}
+}
+
+// stringify returns the text of an element or list of elements, like
+// pandoc.utils.stringify.
+func stringify(v lua.LValue) string {
+	switch v := v.(type) {
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		switch field(v, "t") {
+		case "Str", "Code", "CodeBlock", "Node":
+			return field(v, "text")
+		case "Space", "SoftBreak", "LineBreak":
+			return " "
+		case "RawInline", "RawBlock":
+			return ""
+		case "Image":
+			return stringify(v.RawGetString("caption"))
+		case "":
+			// A list of elements
+		default:
+			return stringify(v.RawGetString("content"))
+		}
+		buf := new(strings.Builder)
+		for i := 1; i <= v.Len(); i++ {
+			s := stringify(v.RawGetInt(i))
+			// Blocks in a list are separated like paragraphs
+			if buf.Len() > 0 && isBlockList(v) && s != "" {
+				r, _ := utf8.DecodeLastRuneInString(buf.String())
+				if !unicode.IsSpace(r) {
+					buf.WriteString(" ")
+				}
+			}
+			buf.WriteString(s)
+		}
+		return buf.String()
+	}
+	return ""
+}
+
+// isBlockList reports if a list holds block elements
+func isBlockList(v *lua.LTable) bool {
+	if el, ok := v.RawGetInt(1).(*lua.LTable); ok {
+		tag := field(el, "t")
+		for _, blockTag := range luaBlockTags {
+			if tag == blockTag {
+				return true
+			}
+		}
+		// A list of list items
+		return tag == "" && el.Len() > 0
+	}
+	return false
+}
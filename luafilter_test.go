@@ -0,0 +1,147 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFilter writes a Lua filter in a temporary directory and
+// returns its path.
+func writeTestFilter(t *testing.T, src string) string {
+	t.Helper()
+	fName := filepath.Join(t.TempDir(), "filter.lua")
+	if err := os.WriteFile(fName, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fName
+}
+
+func TestLuaFilterDemo(t *testing.T) {
+	doc := &CommonMark{Text: `# About
+
+See [the archive](archive.md) and *more*.
+
+## What’s new, today?
+
+Plain text stays plain.
+`}
+	doc.UseFilters([]string{"demo/links-to-html.lua", "demo/link-h2-anchor.lua"})
+	src, err := doc.ToUnsafeHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<h1>About</h1>",
+		`<a href="archive.html">the archive</a> and <em>more</em>.`,
+		`<h2><a name="whats-new-today">What’s new, today?</a></h2>`,
+		"<p>Plain text stays plain.</p>",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+}
+
+func TestLuaFilterElements(t *testing.T) {
+	fName := writeTestFilter(t, `
+return {
+  {
+    Str = function (el)
+      if el.text == "TODO" then
+        return pandoc.Strong("Note:")
+      end
+    end,
+    Image = function (el)
+      el.classes = {"photo"}
+      return el
+    end,
+    CodeBlock = function (el)
+      return {}
+    end,
+  },
+  {
+    Header = function (h)
+      h.identifier = "h-" .. h.level
+      h.content = pandoc.utils.stringify(h) .. "!"
+      return h
+    end,
+    BlockQuote = function (el)
+      return pandoc.RawBlock("latex", "\\begin{quote}")
+    end,
+  },
+}
+`)
+	doc := &CommonMark{Text: "## Hello *world*\n\nTODO ![A cat](cat.jpg)\n\n```\ncode\n```\n\n> quoted\n"}
+	doc.UseFilters([]string{fName})
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<h2 id="h-2">Hello world!</h2>`,
+		`<strong>Note:</strong>`,
+		`<img src="cat.jpg" alt="A cat" class="photo">`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+	for _, unexpected := range []string{"code", "quote"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("expected %q to be removed\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestLuaFilterError(t *testing.T) {
+	fName := writeTestFilter(t, `function Para(el) error("broken filter") end`)
+	doc := &CommonMark{Text: "Hello"}
+	doc.UseFilters([]string{fName})
+	if _, err := doc.ToHTML(); err == nil || !strings.Contains(err.Error(), "broken filter") {
+		t.Errorf("expected the filter's error, got %v", err)
+	}
+	doc.UseFilters([]string{filepath.Join(t.TempDir(), "missing.lua")})
+	if _, err := doc.ToHTML(); err == nil {
+		t.Errorf("expected an error for a missing filter")
+	}
+}
+
+func TestLuaFilterUnchanged(t *testing.T) {
+	fName := writeTestFilter(t, `
+function Inline(el) return el end
+function Block(el) return nil end
+function Str(el) return el end
+`)
+	text := "# Title :smile:\n\nA [link](a.md \"A\") with `code`, ~~gone~~ and a note[^1].\n\n- one\n- **two**\n\n> quote\n\n[^1]: The note.\n"
+	expected, err := (&CommonMark{Text: text}).ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &CommonMark{Text: text}
+	doc.UseFilters([]string{fName})
+	src, err := doc.ToHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src != expected {
+		t.Errorf("expected filters leaving elements alone to change nothing\n%s\ngot\n%s", expected, src)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// 3rd Party Packages
+	"gopkg.in/yaml.v3"
+)
+
+// BuildManifest records a hash of the inputs each output of generate was
+// written from, the generator YAML, the source Markdown and the database
+// rows. An output is only written again when the hash of its inputs
+// changes or the output is missing.
+type BuildManifest struct {
+	// Outputs maps the path of an output to the hash of its inputs
+	Outputs map[string]string `json:"outputs"`
+
+	// fName is the path the manifest is saved to
+	fName string
+
+	// full is set by "generate --full", every output is written
+	full bool
+}
+
+// manifestName returns the path of the build manifest kept next to the
+// configuration file, antenna.yaml's manifest is antenna.manifest.json.
+func manifestName(cfgName string) string {
+	return strings.TrimSuffix(cfgName, filepath.Ext(cfgName)) + ".manifest.json"
+}
+
+// LoadBuildManifest reads the build manifest in fName. A missing manifest
+// is empty so everything is written on the first run.
+func LoadBuildManifest(fName string) (*BuildManifest, error) {
+	manifest := &BuildManifest{Outputs: map[string]string{}, fName: fName}
+	src, err := os.ReadFile(fName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(src, manifest); err != nil {
+		return nil, fmt.Errorf("%s: %s", fName, err)
+	}
+	if manifest.Outputs == nil {
+		manifest.Outputs = map[string]string{}
+	}
+	return manifest, nil
+}
+
+// Save writes the build manifest back to its file
+func (manifest *BuildManifest) Save() error {
+	src, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifest.fName, src, 0664)
+}
+
+// Changed reports if output needs to be written, because a full rebuild
+// was asked for, its inputs hash differently than the last time it was
+// written or it no longer exists. A nil manifest always reports true.
+func (manifest *BuildManifest) Changed(output string, sum string) bool {
+	if manifest == nil || manifest.full || manifest.Outputs[output] != sum {
+		return true
+	}
+	_, err := os.Stat(output)
+	return err != nil
+}
+
+// Record saves the hash of the inputs output was written from
+func (manifest *BuildManifest) Record(output string, sum string) {
+	if manifest != nil {
+		manifest.Outputs[output] = sum
+	}
+}
+
+// inputHash returns the hex encoded SHA-256 of the inputs. Each input is
+// prefixed by its length so moving bytes from one input to the next
+// changes the hash. The version of antenna is included so upgrading it
+// writes everything again.
+func inputHash(inputs ...string) string {
+	h := sha256.New()
+	writeInput(h, Version)
+	for _, input := range inputs {
+		writeInput(h, input)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeInput writes a length prefixed input to h
+func writeInput(h hash.Hash, input string) {
+	binary.Write(h, binary.BigEndian, uint64(len(input)))
+	h.Write([]byte(input))
+}
+
+// tableHash returns the hex encoded SHA-256 of every row of table in the
+// order of its first column. A missing table hashes as an empty one.
+func tableHash(db *sql.DB, table string) (string, error) {
+	h := sha256.New()
+	stmt := fmt.Sprintf("SELECT * FROM %s ORDER BY 1", table)
+	rows, err := db.Query(stmt)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return hex.EncodeToString(h.Sum(nil)), nil
+		}
+		return "", fmt.Errorf("%s\nstmt: %s", err, stmt)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		for _, value := range values {
+			if value.Valid {
+				writeInput(h, value.String)
+			} else {
+				// NULL and '' hash differently
+				binary.Write(h, binary.BigEndian, ^uint64(0))
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pageHash returns the path a page is written to with the hash of its
+// inputs, the generator YAML, its layout, site navigation and cm_filters
+// and the page's Markdown with its included text and code blocks.
+func (cfg *AppConfig) pageHash(fName string, oName string) (string, string, error) {
+	doc, err := LoadCommonMark(fName)
+	if err != nil {
+		return "", "", err
+	}
+	src, err := os.ReadFile(fName)
+	if err != nil {
+		return "", "", err
+	}
+	if strings.Contains(doc.Text, "@include-text-block ") {
+		doc.Text = IncludeTextBlock(doc.Text)
+	}
+	if strings.Contains(doc.Text, "@include-code-block ") {
+		doc.Text = IncludeCodeBlock(doc.Text)
+	}
+	genSrc, err := os.ReadFile(cfg.Generator)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+	gen := new(Generator)
+	if err := yaml.Unmarshal(genSrc, gen); err != nil {
+		return "", "", err
+	}
+	gen.useSite(cfg)
+	return cfg.pageHTMLName(doc, fName, oName), inputHash(string(genSrc), gen.layoutSource(), gen.navSource(), gen.filterSource(), cfg.BaseURL, string(src), doc.Text), nil
+}
+
+// collectionHash returns the hash of the inputs of a collection's pages
+// and feeds, the generator YAML and its layout, the collection's settings
+// and the rows of its channels, items and pages tables.
+func collectionHash(genSrc []byte, layoutSrc string, collection *Collection, baseURL string) (string, error) {
+	settings, err := json.Marshal(collection)
+	if err != nil {
+		return "", err
+	}
+	db, err := openCollectionDB(collection.DbName)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	channels, err := tableHash(db, "channels")
+	if err != nil {
+		return "", err
+	}
+	items, err := tableHash(db, "items")
+	if err != nil {
+		return "", err
+	}
+	pages, err := tableHash(db, "pages")
+	if err != nil {
+		return "", err
+	}
+	return inputHash(string(genSrc), layoutSrc, string(settings), baseURL, channels, items, pages), nil
+}
@@ -0,0 +1,152 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuildManifest(t *testing.T) {
+	dName := t.TempDir()
+	output := filepath.Join(dName, "index.html")
+	manifest, err := LoadBuildManifest(manifestName(filepath.Join(dName, "antenna.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := inputHash("page.yaml", "# Hello")
+	if !manifest.Changed(output, sum) {
+		t.Errorf("expected an output missing from the manifest to be changed")
+	}
+	manifest.Record(output, sum)
+	if !manifest.Changed(output, sum) {
+		t.Errorf("expected a missing output to be changed")
+	}
+	if err := os.WriteFile(output, []byte("<p>Hello</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.Save(); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = LoadBuildManifest(filepath.Join(dName, "antenna.manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Changed(output, sum) {
+		t.Errorf("expected the saved output to be unchanged")
+	}
+	if !manifest.Changed(output, inputHash("page.yaml", "# Hello World")) {
+		t.Errorf("expected a new input to change the output")
+	}
+	if inputHash("ab", "c") == inputHash("a", "bc") {
+		t.Errorf("expected inputs split differently to hash differently")
+	}
+	manifest.full = true
+	if !manifest.Changed(output, sum) {
+		t.Errorf("expected a full rebuild to change every output")
+	}
+}
+
+func TestGenerateIncremental(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, src := range map[string]string{
+		"page.yaml": DefaultGeneratorYaml,
+		"blog.md":   "# Blog\n",
+		"antenna.yaml": `htdocs: htdocs
+generator: page.yaml
+collections:
+  - file: blog.md
+    dbName: blog.db
+`,
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll("htdocs", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := setupDatabase("blog.md", "blog.db"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", "blog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO items (link, title, description, authors, enclosures, guid, pubDate, dcExt,
+		channel, status, updated, label, postPath, sourceMarkdown)
+		VALUES ('https://example.com/hello.html', 'Hello', 'Hello', '', '', 'https://example.com/hello.html',
+		'2025-09-01', '', '', 'published', '2025-09-01', '', 'hello.md', '# Hello')`); err != nil {
+		t.Fatal(err)
+	}
+	app := &AntennaApp{appName: "antenna"}
+	generate := func(args ...string) {
+		t.Helper()
+		if err := app.Generate(io.Discard, io.Discard, "antenna.yaml", args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// written moves the outputs back in time and reports if they were
+	// written since the last call
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	written := func() map[string]bool {
+		t.Helper()
+		m := map[string]bool{}
+		for _, fName := range []string{filepath.Join("htdocs", "blog.html"), filepath.Join("htdocs", "hello.html")} {
+			info, err := os.Stat(fName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m[filepath.Base(fName)] = !info.ModTime().Equal(old)
+			if err := os.Chtimes(fName, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return m
+	}
+
+	generate()
+	if m := written(); !m["blog.html"] || !m["hello.html"] {
+		t.Errorf("expected the first run to write everything, %v", m)
+	}
+	if _, err := os.Stat("antenna.manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+	generate()
+	if m := written(); m["blog.html"] || m["hello.html"] {
+		t.Errorf("expected nothing written when nothing changed, %v", m)
+	}
+	if _, err := db.Exec(`UPDATE items SET sourceMarkdown = '# Hello World' WHERE postPath = 'hello.md'`); err != nil {
+		t.Fatal(err)
+	}
+	generate()
+	if m := written(); !m["blog.html"] || !m["hello.html"] {
+		t.Errorf("expected a changed post to be written, %v", m)
+	}
+	generate("--full")
+	if m := written(); !m["blog.html"] || !m["hello.html"] {
+		t.Errorf("expected --full to write everything, %v", m)
+	}
+	if err := app.Generate(io.Discard, io.Discard, "antenna.yaml", []string{"--bogus"}); err == nil {
+		t.Errorf("expected an unknown option to fail")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// movedPermanently returns the final URL of a response when it was reached
+// only through permanent redirects (301 or 308). An empty string is
+// returned when there was no redirect or any of them was temporary.
+func movedPermanently(res *http.Response) string {
+	if res.Request == nil || res.Request.Response == nil {
+		return ""
+	}
+	// Walk back from the final request through the redirect responses
+	for req := res.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		switch req.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		default:
+			return ""
+		}
+	}
+	return res.Request.URL.String()
+}
+
+// rewriteMovedLinks replaces the URLs of moved feeds in the Markdown list
+// of a collection file. Only lines ParseMarkdownLinks recognizes as links
+// are changed, everything else is kept as is. The original file is kept
+// with a .bak extension. It returns the number of links rewritten.
+func rewriteMovedLinks(fName string, moved map[string]string) (int, error) {
+	src, err := os.ReadFile(fName)
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Split(string(src), "\n")
+	cnt := 0
+	for i, line := range lines {
+		links, err := ParseMarkdownLinks(line)
+		if err != nil {
+			return 0, err
+		}
+		if len(links) != 1 {
+			continue
+		}
+		if newURL, ok := moved[links[0].URL]; ok {
+			lines[i] = strings.Replace(line, "("+links[0].URL, "("+newURL, 1)
+			cnt++
+		}
+	}
+	if cnt == 0 {
+		return 0, nil
+	}
+	if err := os.Rename(fName, fName+".bak"); err != nil {
+		return 0, fmt.Errorf("cannot backup %s: %s", fName, err)
+	}
+	if err := os.WriteFile(fName, []byte(strings.Join(lines, "\n")), 0664); err != nil {
+		return 0, err
+	}
+	return cnt, nil
+}
+
+// rewriteMoved updates the collection's links to feeds that have
+// permanently moved and moves their saved channel, items and health to
+// the new URL. ODT collections can't be rewritten, the moves are reported
+// so the document can be edited by hand.
+func (collection *Collection) rewriteMoved(out io.Writer, eout io.Writer, db *sql.DB, moved map[string]string) error {
+	if isODTFile(collection.File) {
+		for oldURL, newURL := range moved {
+			fmt.Fprintf(eout, "warning (%s): %s moved to %s, edit the document by hand\n", collection.File, oldURL, newURL)
+		}
+		return nil
+	}
+	cnt, err := rewriteMovedLinks(collection.File, moved)
+	if err != nil {
+		return err
+	}
+	for oldURL, newURL := range moved {
+		for _, stmt := range []string{SQLRenameChannel, SQLRenameItemChannel, SQLRenameFeedHealth} {
+			if _, err := db.Exec(stmt, newURL, oldURL); err != nil {
+				return fmt.Errorf("%s\nstmt: %s", err, stmt)
+			}
+		}
+	}
+	fmt.Fprintf(out, "rewrote %d moved feeds in %s\n", cnt, collection.File)
+	return nil
+}
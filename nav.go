@@ -0,0 +1,214 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// navPlaceholder is replaced by the generated nav list in a generator's nav
+const navPlaceholder = "{{nav}}"
+
+// navEntry is a link in the generated site navigation
+type navEntry struct {
+	Title string `json:"title"`
+	Href  string `json:"href"`
+	// Order is the nav_order front matter value, entries without one
+	// come after those with one
+	Order    int  `json:"order,omitempty"`
+	HasOrder bool `json:"has_order,omitempty"`
+}
+
+// webPath returns the path of htmlName from the root of htdocs, "/blog.html"
+func webPath(htdocs string, htmlName string) string {
+	rel, err := filepath.Rel(htdocs, htmlName)
+	if err != nil {
+		rel = htmlName
+	}
+	return "/" + strings.TrimLeft(filepath.ToSlash(rel), "/")
+}
+
+// relativeHref returns the link to the web path href from the page at the
+// web path pagePath, e.g. "../about.html" from "/blog/post.html", so the
+// links work when the site is published under a base_url with a path.
+func relativeHref(pagePath string, href string) string {
+	if pagePath == "" || !strings.HasPrefix(href, "/") {
+		return href
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(pagePath)), filepath.FromSlash(href))
+	if err != nil {
+		return href
+	}
+	return filepath.ToSlash(rel)
+}
+
+// frontMatterNavOrder returns the nav_order front matter value
+func frontMatterNavOrder(doc *CommonMark) (int, bool) {
+	switch v := doc.FrontMatter["nav_order"].(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	case string:
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// frontMatterNavExclude reports if the front matter sets nav_exclude
+func frontMatterNavExclude(doc *CommonMark) bool {
+	switch v := doc.FrontMatter["nav_exclude"].(type) {
+	case bool:
+		return v
+	case string:
+		exclude, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return exclude
+	}
+	return false
+}
+
+// navTitle returns the nav_title front matter value, then the title,
+// then fallback.
+func navTitle(doc *CommonMark, fallback string) string {
+	for _, key := range []string{"nav_title", "title"} {
+		if title := strings.TrimSpace(doc.GetAttributeString(key, "")); title != "" {
+			return title
+		}
+	}
+	return fallback
+}
+
+// navEntries returns the site navigation built from the collections in
+// antenna.yaml and the pages whose front matter sets nav_order. An entry
+// is left out when its front matter sets nav_exclude. Entries are sorted
+// by nav_order, those without one keep the order of antenna.yaml and
+// come last. The entries are built once per AppConfig.
+func (cfg *AppConfig) navEntries() []*navEntry {
+	if cfg.nav != nil {
+		return cfg.nav
+	}
+	entries := []*navEntry{}
+	for _, collection := range cfg.Collections {
+		doc, err := LoadCommonMark(collection.File)
+		if err != nil {
+			doc = &CommonMark{FrontMatter: map[string]interface{}{}}
+		}
+		if frontMatterNavExclude(doc) {
+			continue
+		}
+		title := collection.Title
+		if title == "" {
+			title = pageDisplayName(collection.File)
+		}
+		bName := filepath.Base(collection.File)
+		entry := &navEntry{
+			Title: navTitle(doc, title),
+			Href:  "/" + strings.TrimSuffix(bName, filepath.Ext(bName)) + ".html",
+		}
+		entry.Order, entry.HasOrder = frontMatterNavOrder(doc)
+		entries = append(entries, entry)
+	}
+	// Pages are only listed when they ask to be
+	if pages, err := cfg.GetPages(); err == nil {
+		for _, page := range pages {
+			doc, err := LoadCommonMark(page["inputPath"])
+			if err != nil || frontMatterNavExclude(doc) {
+				continue
+			}
+			order, ok := frontMatterNavOrder(doc)
+			if !ok {
+				continue
+			}
+			entries = append(entries, &navEntry{
+				Title:    navTitle(doc, pageDisplayName(page["inputPath"])),
+				Href:     webPath(cfg.Htdocs, cfg.pageHTMLName(doc, page["inputPath"], page["outputPath"])),
+				Order:    order,
+				HasOrder: true,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].HasOrder != entries[j].HasOrder {
+			return entries[i].HasOrder
+		}
+		return entries[i].Order < entries[j].Order
+	})
+	cfg.nav = entries
+	return entries
+}
+
+// autoNav reports if the generator's nav asks for the generated site
+// navigation, "auto" or a nav holding the {{nav}} placeholder.
+func (gen *Generator) autoNav() bool {
+	nav := strings.TrimSpace(gen.Nav)
+	return nav == "auto" || strings.Contains(nav, navPlaceholder)
+}
+
+// useSite gives the generator what it needs from antenna.yaml to render
+// the site navigation and mark the current page.
+func (gen *Generator) useSite(cfg *AppConfig) {
+	gen.htdocs = cfg.Htdocs
+	if gen.autoNav() {
+		gen.siteNav = cfg.navEntries()
+	}
+}
+
+// navSource returns the generated site navigation as JSON so the build
+// manifest sees a change to it. It is empty when nav isn't generated.
+func (gen *Generator) navSource() string {
+	if !gen.autoNav() {
+		return ""
+	}
+	src, _ := json.Marshal(gen.siteNav)
+	return string(src)
+}
+
+// navHTML returns the inner HTML of the page's nav element. When nav is
+// "auto" or holds {{nav}} the generated list is used, with the link to
+// the page being written marked aria-current="page". The links are
+// relative to the page being written, see relativeHref.
+func (gen *Generator) navHTML() string {
+	if !gen.autoNav() {
+		return gen.Nav
+	}
+	list := new(strings.Builder)
+	if len(gen.siteNav) > 0 {
+		fmt.Fprintln(list, "<ul>")
+		for _, entry := range gen.siteNav {
+			current := ""
+			if gen.pagePath != "" && entry.Href == gen.pagePath {
+				current = ` aria-current="page"`
+			}
+			fmt.Fprintf(list, "  <li><a href=\"%s\"%s>%s</a></li>\n",
+				html.EscapeString(relativeHref(gen.pagePath, entry.Href)), current, html.EscapeString(entry.Title))
+		}
+		fmt.Fprint(list, "</ul>")
+	}
+	if strings.TrimSpace(gen.Nav) == "auto" {
+		return list.String()
+	}
+	return strings.ReplaceAll(gen.Nav, navPlaceholder, list.String())
+}
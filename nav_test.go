@@ -0,0 +1,106 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNavEntries(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, src := range map[string]string{
+		"blog.md":   "---\ntitle: The Blog\n---\n\n# Blog\n",
+		"links.md":  "---\nnav_order: 1\nnav_title: Links\n---\n\n# Links\n",
+		"drafts.md": "---\nnav_exclude: true\n---\n\n# Drafts\n",
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &AppConfig{
+		Htdocs: "htdocs",
+		Collections: []*Collection{
+			{File: "blog.md"},
+			{File: "links.md", Title: "Link blog"},
+			{File: "drafts.md"},
+			{File: "news-feed.md"},
+		},
+	}
+	entries := cfg.navEntries()
+	expected := []navEntry{
+		{Title: "Links", Href: "/links.html"},
+		{Title: "The Blog", Href: "/blog.html"},
+		{Title: "News feed", Href: "/news-feed.html"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Title != expected[i].Title || entry.Href != expected[i].Href {
+			t.Errorf("expected entry %d to be %q %q, got %q %q", i,
+				expected[i].Title, expected[i].Href, entry.Title, entry.Href)
+		}
+	}
+}
+
+func TestNavHTML(t *testing.T) {
+	gen := &Generator{
+		Nav: `<a href="/">Home</a> {{nav}}`,
+		siteNav: []*navEntry{
+			{Title: "Blog", Href: "/blog.html"},
+			{Title: "Q & A", Href: "/qa.html"},
+		},
+		pagePath: "/qa.html",
+	}
+	nav := gen.navHTML()
+	for _, expected := range []string{
+		`<a href="/">Home</a> <ul>`,
+		`<li><a href="blog.html">Blog</a></li>`,
+		`<li><a href="qa.html" aria-current="page">Q &amp; A</a></li>`,
+	} {
+		if !strings.Contains(nav, expected) {
+			t.Errorf("expected %q in the nav\n%s", expected, nav)
+		}
+	}
+	// The links are relative so a site under https://example.org/blog
+	// works, a page in a subdirectory links up to the others
+	gen.pagePath = "/notes/2025/qa.html"
+	gen.siteNav = append(gen.siteNav, &navEntry{Title: "Notes", Href: "/notes/index.html"})
+	nav = gen.navHTML()
+	for _, expected := range []string{
+		`<li><a href="../../blog.html">Blog</a></li>`,
+		`<li><a href="../../qa.html">Q &amp; A</a></li>`,
+		`<li><a href="../index.html">Notes</a></li>`,
+	} {
+		if !strings.Contains(nav, expected) {
+			t.Errorf("expected %q in the nav\n%s", expected, nav)
+		}
+	}
+	gen.Nav = "auto"
+	if nav := gen.navHTML(); !strings.HasPrefix(nav, "<ul>") {
+		t.Errorf("expected nav: auto to be the list alone\n%s", nav)
+	}
+	gen.Nav = `<a href="/">Home</a>`
+	if nav := gen.navHTML(); nav != gen.Nav {
+		t.Errorf("expected a nav without {{nav}} to be unchanged, got %q", nav)
+	}
+	if src := gen.navSource(); src != "" {
+		t.Errorf("expected no nav source when nav isn't generated, got %q", src)
+	}
+}
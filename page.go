@@ -144,7 +144,7 @@ func (cfg *AppConfig) Unpage(fName string) error {
 		return err
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open %s, %s", dsn, err)
 	}
@@ -156,6 +156,17 @@ func (cfg *AppConfig) Unpage(fName string) error {
 	return nil
 }
 
+// pageHTMLName returns the path in htdocs a page is written to, oName
+// when set, otherwise the page's postPath with an .html extension.
+func (cfg *AppConfig) pageHTMLName(doc *CommonMark, fName string, oName string) string {
+	htmlName := filepath.Join(cfg.Htdocs, doc.GetAttributeString("postPath", fName))
+	if oName != "" {
+		htmlName = filepath.Join(cfg.Htdocs, oName)
+	}
+	// Normalize the HTML filename, replacing any source document extension with .html
+	return normalizeToHTMLExt(htmlName)
+}
+
 func (cfg *AppConfig) Page(fName string, oName string) error {
 	doc, err := LoadCommonMark(fName)
 	if err != nil {
@@ -170,38 +181,39 @@ func (cfg *AppConfig) Page(fName string, oName string) error {
 		doc.Text = IncludeCodeBlock(doc.Text)
 	}
 
-	// Convert our document text to HTML
+	gen, err := NewGenerator(path.Base(os.Args[0]), cfg.BaseURL)
+	if err != nil {
+		return err
+	}
+	if err := gen.LoadConfig(cfg.Generator); err != nil {
+		return err
+	}
+
+	// Convert our document text to HTML, running the generator's cm_filters
 	// NOTE: Pages are allowed to have "unsafe" embedded HTML because they are
 	// not reading from a feed, they are being read from your file system.
+	doc.UseFilters(gen.CMarkFilters)
+	gen.useHeadings(doc)
 	innerHTML, err := doc.ToUnsafeHTML()
 	if err != nil {
 		return err
 	}
 	postPath := doc.GetAttributeString("postPath", fName)
-	htmlName := filepath.Join(cfg.Htdocs, postPath)
-	if oName != "" {
-		htmlName = filepath.Join(cfg.Htdocs, oName)
-	} else if postPath != "" {
-		oName = normalizeToHTMLExt(postPath)
-	} else {
-		oName = normalizeToHTMLExt(fName)
+	htmlName := cfg.pageHTMLName(doc, fName, oName)
+	if oName == "" {
+		if postPath != "" {
+			oName = normalizeToHTMLExt(postPath)
+		} else {
+			oName = normalizeToHTMLExt(fName)
+		}
 	}
-
-	// Normalize the HTML filename, replacing any source document extension with .html
-	htmlName = normalizeToHTMLExt(htmlName)
 	dName := filepath.Dir(htmlName)
 	if _, err := os.Stat(dName); err != nil {
 		if err := os.MkdirAll(dName, 0775); err != nil {
 			return err
 		}
 	}
-	gen, err := NewGenerator(path.Base(os.Args[0]), cfg.BaseURL)
-	if err != nil {
-		return err
-	}
-	if err := gen.LoadConfig(cfg.Generator); err != nil {
-		return err
-	}
+	gen.useSite(cfg)
 	if err := gen.WriteHtmlPage(htmlName, "", postPath, "", innerHTML, doc.FrontMatter); err != nil {
 		return err
 	}
@@ -213,7 +225,7 @@ func (cfg *AppConfig) Page(fName string, oName string) error {
 		return err
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open %s, %s", dsn, err)
 	}
@@ -232,7 +244,7 @@ func (cfg *AppConfig) GetPages() ([]map[string]string, error) {
 		return nil, err
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +297,7 @@ func (cfg *AppConfig) Pages() error {
 		return err
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open %s, %s", dsn, err)
 	}
@@ -0,0 +1,143 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// pageLinks describes where a page sits in a paginated aggregate page
+type pageLinks struct {
+	Number int
+	Count  int
+	Prev   string
+	Next   string
+}
+
+// pageName returns the file name of page number n of htmlName, page one
+// is htmlName itself, page two is NAME-2.html and so on.
+func pageName(htmlName string, n int) string {
+	if n <= 1 {
+		return htmlName
+	}
+	xName := filepath.Ext(htmlName)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(htmlName, xName), n, xName)
+}
+
+// write writes the Pagination nav element with the previous and next
+// page links.
+func (p *pageLinks) write(out io.Writer) {
+	fmt.Fprintln(out, `    <nav aria-label="Pagination">`)
+	fmt.Fprintln(out, "      <ul>")
+	if p.Prev != "" {
+		fmt.Fprintf(out, "        <li><a href=\"%s\" rel=\"prev\">Previous page</a></li>\n", html.EscapeString(p.Prev))
+	}
+	fmt.Fprintf(out, "        <li aria-current=\"page\">Page %d of %d</li>\n", p.Number, p.Count)
+	if p.Next != "" {
+		fmt.Fprintf(out, "        <li><a href=\"%s\" rel=\"next\">Next page</a></li>\n", html.EscapeString(p.Next))
+	}
+	fmt.Fprintln(out, "      </ul>")
+	fmt.Fprintln(out, "    </nav>")
+}
+
+// pageGenerator returns a copy of gen for one page of a paginated
+// aggregate page. The title names the page and the head links to the
+// previous and next pages.
+func (gen *Generator) pageGenerator(p *pageLinks) *Generator {
+	page := *gen
+	page.Link = slices.Clone(gen.Link)
+	if p.Number > 1 && gen.Title != "" {
+		page.Title = fmt.Sprintf("%s, page %d of %d", gen.Title, p.Number, p.Count)
+	}
+	if p.Prev != "" {
+		page.Link = append(page.Link, map[string]string{"rel": "prev", "href": p.Prev})
+	}
+	if p.Next != "" {
+		page.Link = append(page.Link, map[string]string{"rel": "next", "href": p.Next})
+	}
+	page.pagination = p
+	if gen.pagePath != "" {
+		page.pagePath = pageName(gen.pagePath, p.Number)
+	}
+	return &page
+}
+
+// WritePagedHTML writes the items of SQLDisplayItems as pages of
+// items.page_size items. The first page is written to out, the rest are
+// written next to htmlName as NAME-2.html, NAME-3.html and so on. Pages
+// left from an earlier run with more items are removed. When page_size
+// isn't set it writes a single page like WriteHTML.
+func (gen *Generator) WritePagedHTML(out io.Writer, db *sql.DB, htmlName string) error {
+	size := gen.Items.PageSize
+	if size <= 0 {
+		return gen.WriteCustomHTML(out, db, "", SQLDisplayItems)
+	}
+	total := 0
+	if err := db.QueryRow(SQLCountDisplayItems).Scan(&total); err != nil {
+		return fmt.Errorf("%s\nstmt: %s", err, SQLCountDisplayItems)
+	}
+	count := (total + size - 1) / size
+	if count <= 1 {
+		// Everything fits on one page so there is nothing to navigate
+		if err := gen.WriteCustomHTML(out, db, "", SQLDisplayItems); err != nil {
+			return err
+		}
+		return removePages(htmlName, 2)
+	}
+	for n := 1; n <= count; n++ {
+		p := &pageLinks{Number: n, Count: count}
+		if n > 1 {
+			p.Prev = filepath.Base(pageName(htmlName, n-1))
+		}
+		if n < count {
+			p.Next = filepath.Base(pageName(htmlName, n+1))
+		}
+		page := gen.pageGenerator(p)
+		if n == 1 {
+			if err := page.WriteCustomHTML(out, db, "", SQLDisplayItemsPage, size, 0); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeFile(pageName(htmlName, n), func(out io.Writer) error {
+			return page.WriteCustomHTML(out, db, "", SQLDisplayItemsPage, size, (n-1)*size)
+		}); err != nil {
+			return err
+		}
+	}
+	return removePages(htmlName, count+1)
+}
+
+// removePages removes the pages of htmlName numbered from n on
+func removePages(htmlName string, n int) error {
+	for ; ; n++ {
+		fName := pageName(htmlName, n)
+		if _, err := os.Stat(fName); err != nil {
+			return nil
+		}
+		if err := os.Remove(fName); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hasHeadLink reports if page has a link element with rel and href
+func hasHeadLink(page string, rel string, href string) bool {
+	for _, line := range strings.Split(page, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "<link ") && strings.Contains(line, fmt.Sprintf("rel=%q", rel)) &&
+			strings.Contains(line, fmt.Sprintf("href=%q", href)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPageName(t *testing.T) {
+	for n, expected := range map[int]string{
+		1: "htdocs/blog.html",
+		2: "htdocs/blog-2.html",
+		9: "htdocs/blog-9.html",
+	} {
+		if got := pageName("htdocs/blog.html", n); got != expected {
+			t.Errorf("pageName(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
+
+func TestWritePagedHTML(t *testing.T) {
+	db := newTestItemsDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for i := 1; i <= 5; i++ {
+		if _, err := db.Exec(`INSERT INTO items (link, title, description, pubDate, status)
+			VALUES (?, ?, 'An item', ?, 'published')`,
+			fmt.Sprintf("https://example.com/%d", i), fmt.Sprintf("Item %d", i),
+			fmt.Sprintf("2025-09-0%d 12:00:00", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dName := t.TempDir()
+	htmlName := filepath.Join(dName, "blog.html")
+	// A page left from an earlier run with more items
+	if err := os.WriteFile(pageName(htmlName, 4), []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gen, _ := NewGenerator("antenna", "https://example.com")
+	gen.eout = io.Discard
+	gen.Title = "Blog"
+	gen.Header = "<h1>Blog</h1>"
+	gen.Items.PageSize = 2
+	buf := new(bytes.Buffer)
+	if err := gen.WritePagedHTML(buf, db, htmlName); err != nil {
+		t.Fatal(err)
+	}
+	first := buf.String()
+	if !strings.Contains(first, "https://example.com/5") || !strings.Contains(first, "https://example.com/4") ||
+		strings.Contains(first, "https://example.com/3") {
+		t.Errorf("expected the two newest items on the first page\n%s", first)
+	}
+	if !strings.Contains(first, `<nav aria-label="Pagination">`) || !hasHeadLink(first, "next", "blog-2.html") ||
+		!strings.Contains(first, `<a href="blog-2.html" rel="next">`) || strings.Contains(first, `rel="prev"`) {
+		t.Errorf("unexpected pagination on the first page\n%s", first)
+	}
+	src, err := os.ReadFile(pageName(htmlName, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := string(src)
+	if !strings.Contains(second, "https://example.com/3") || !strings.Contains(second, "https://example.com/2") {
+		t.Errorf("expected the next two items on the second page\n%s", second)
+	}
+	if !hasHeadLink(second, "prev", "blog.html") || !hasHeadLink(second, "next", "blog-3.html") ||
+		!strings.Contains(second, "<title>Blog, page 2 of 3</title>") || !strings.Contains(second, `<li aria-current="page">Page 2 of 3</li>`) {
+		t.Errorf("unexpected pagination on the second page\n%s", second)
+	}
+	src, err = os.ReadFile(pageName(htmlName, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "https://example.com/1") || strings.Contains(string(src), `rel="next"`) {
+		t.Errorf("unexpected last page\n%s", src)
+	}
+	if _, err := os.Stat(pageName(htmlName, 4)); err == nil {
+		t.Errorf("expected the stale page removed")
+	}
+
+	// When everything fits on one page there is no pagination
+	gen.Items.PageSize = 10
+	buf.Reset()
+	if err := gen.WritePagedHTML(buf, db, htmlName); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Pagination") || !strings.Contains(buf.String(), "https://example.com/1") {
+		t.Errorf("expected a single page of items\n%s", buf.String())
+	}
+	if _, err := os.Stat(pageName(htmlName, 2)); err == nil {
+		t.Errorf("expected the second page removed")
+	}
+}
@@ -0,0 +1,337 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// itunesNamespace is the XML namespace of Apple's podcast elements
+	itunesNamespace = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+	// podcastNamespace is the XML namespace of Podcasting 2.0, see
+	// <https://podcastindex.org/namespace/1.0>
+	podcastNamespace = "https://podcastindex.org/namespace/1.0"
+)
+
+// PodcastEpisode holds the podcast details of a post, they are set in
+// the post's front matter and written to the RSS feed of collections
+// flagged as a podcast.
+type PodcastEpisode struct {
+	// Duration of the episode, in seconds or as HH:MM:SS
+	Duration string `json:"duration,omitempty"`
+	// Explicit is set when the front matter says if the episode is explicit
+	Explicit *bool `json:"explicit,omitempty"`
+	// Episode number
+	Episode int `json:"episode,omitempty"`
+	// Season number
+	Season int `json:"season,omitempty"`
+	// EpisodeType is "full", "trailer" or "bonus"
+	EpisodeType string `json:"episodeType,omitempty"`
+	// Image is the URL of the episode's artwork
+	Image string `json:"image,omitempty"`
+	// Transcripts of the episode
+	Transcripts []*PodcastLink `json:"transcripts,omitempty"`
+	// Chapters of the episode
+	Chapters *PodcastLink `json:"chapters,omitempty"`
+}
+
+// PodcastLink is a file related to an episode, e.g. a podcast:transcript
+// or podcast:chapters element.
+type PodcastLink struct {
+	Url      string `json:"url,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Language string `json:"language,omitempty"`
+	Rel      string `json:"rel,omitempty"`
+}
+
+// mediaTypes maps the extensions of podcast media to their MIME types.
+// They are checked before the system's MIME types which often lack audio
+// formats or vary between machines.
+var mediaTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/opus",
+	".flac": "audio/flac",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+	".m4v":  "video/x-m4v",
+	".mov":  "video/quicktime",
+	".vtt":  "text/vtt",
+	".srt":  "application/x-subrip",
+	".txt":  "text/plain",
+	".html": "text/html",
+	".json": "application/json",
+}
+
+// mediaType returns the MIME type of a media file from its extension
+func mediaType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType, ok := mediaTypes[ext]; ok {
+		return mimeType
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// postMedia returns the enclosure for a media file named in a post's
+// front matter. A URL is used as is. A local file, relative to the site or
+// to the post, is copied into htdocs and its size and MIME type computed.
+func (cfg *AppConfig) postMedia(postPath string, src string) (*Enclosure, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("missing media url or path")
+	}
+	if u, err := url.Parse(src); err == nil && u.IsAbs() {
+		return &Enclosure{Url: src, Length: "0", Type: mediaType(u.Path)}, nil
+	}
+	fName := filepath.Clean(src)
+	if _, err := os.Stat(fName); err != nil && postPath != "" {
+		fName = filepath.Join(filepath.Dir(postPath), src)
+	}
+	if filepath.IsAbs(fName) || strings.HasPrefix(fName, "..") {
+		return nil, fmt.Errorf("%q must be inside the site directory", src)
+	}
+	info, err := os.Stat(fName)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%q is not a file", src)
+	}
+	if cfg.Htdocs != "" && filepath.Clean(cfg.Htdocs) != "." {
+		if err := copyMedia(fName, filepath.Join(cfg.Htdocs, fName)); err != nil {
+			return nil, err
+		}
+	}
+	return &Enclosure{
+		Url:    cfg.BaseURL + "/" + filepath.ToSlash(fName),
+		Length: fmt.Sprintf("%d", info.Size()),
+		Type:   mediaType(fName),
+	}, nil
+}
+
+// copyMedia copies a media file into htdocs
+func copyMedia(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	// Security: Use 0755 instead of 0777 for directory permissions
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// frontMatterString returns a front matter value written as a string or
+// a number, e.g. a duration of 1800 or "30:00".
+func frontMatterString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// frontMatterInt returns a front matter value written as a number or a
+// string of digits.
+func frontMatterInt(val interface{}) int {
+	i := 0
+	fmt.Sscanf(frontMatterString(val), "%d", &i)
+	return i
+}
+
+// postMediaList returns the media named by a front matter value. The
+// value is a path or URL, a map holding url, type, length, language and
+// rel, or a list of either.
+func (cfg *AppConfig) postMediaList(postPath string, key string, val interface{}) ([]*PodcastLink, []*Enclosure, error) {
+	links, enclosures := []*PodcastLink{}, []*Enclosure{}
+	items := []interface{}{val}
+	if list, ok := val.([]interface{}); ok {
+		items = list
+	}
+	for i, item := range items {
+		link := &PodcastLink{}
+		length := ""
+		switch v := item.(type) {
+		case string:
+			link.Url = v
+		case map[string]interface{}:
+			link.Url = frontMatterString(v["url"])
+			link.Type = frontMatterString(v["type"])
+			link.Language = frontMatterString(v["language"])
+			link.Rel = frontMatterString(v["rel"])
+			length = frontMatterString(v["length"])
+		default:
+			return nil, nil, fmt.Errorf("failed to parse %q (%d) -> %T %+v", key, i, item, item)
+		}
+		enclosure, err := cfg.postMedia(postPath, link.Url)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s, %s", key, err)
+		}
+		// Values in the front matter take precedence
+		if link.Type != "" {
+			enclosure.Type = link.Type
+		}
+		if length != "" {
+			enclosure.Length = length
+		}
+		link.Url, link.Type = enclosure.Url, enclosure.Type
+		links = append(links, link)
+		enclosures = append(enclosures, enclosure)
+	}
+	return links, enclosures, nil
+}
+
+// postEnclosures returns the enclosures named in a post's front matter
+// by the "enclosure" key.
+func (cfg *AppConfig) postEnclosures(doc *CommonMark, postPath string) ([]*Enclosure, error) {
+	val, ok := doc.FrontMatter["enclosure"]
+	if !ok || val == nil {
+		return []*Enclosure{}, nil
+	}
+	_, enclosures, err := cfg.postMediaList(postPath, "enclosure", val)
+	return enclosures, err
+}
+
+// postPodcastEpisode returns the podcast details in a post's front
+// matter, or nil if it has none.
+func (cfg *AppConfig) postPodcastEpisode(doc *CommonMark, postPath string) (*PodcastEpisode, error) {
+	episode := &PodcastEpisode{
+		Duration:    frontMatterString(doc.FrontMatter["duration"]),
+		Episode:     frontMatterInt(doc.FrontMatter["episode"]),
+		Season:      frontMatterInt(doc.FrontMatter["season"]),
+		EpisodeType: doc.GetAttributeString("episodeType", ""),
+		Image:       doc.GetAttributeString("image", ""),
+	}
+	if explicit, ok := doc.FrontMatter["explicit"].(bool); ok {
+		episode.Explicit = &explicit
+	}
+	if val, ok := doc.FrontMatter["transcript"]; ok && val != nil {
+		transcripts, _, err := cfg.postMediaList(postPath, "transcript", val)
+		if err != nil {
+			return nil, err
+		}
+		episode.Transcripts = transcripts
+	}
+	if val, ok := doc.FrontMatter["chapters"]; ok && val != nil {
+		chapters, _, err := cfg.postMediaList(postPath, "chapters", val)
+		if err != nil {
+			return nil, err
+		}
+		episode.Chapters = chapters[0]
+		// The Podcasting 2.0 chapters format has its own MIME type
+		if episode.Chapters.Type == "application/json" {
+			episode.Chapters.Type = "application/json+chapters"
+		}
+	}
+	if episode.Duration == "" && episode.Explicit == nil && episode.Episode == 0 && episode.Season == 0 &&
+		episode.EpisodeType == "" && episode.Image == "" && len(episode.Transcripts) == 0 && episode.Chapters == nil {
+		return nil, nil
+	}
+	return episode, nil
+}
+
+// writePodcastChannel writes the iTunes elements of a podcast collection
+func writePodcastChannel(out io.Writer, collection *Collection) {
+	if collection.PodcastAuthor != "" {
+		fmt.Fprintf(out, "    <itunes:author>%s</itunes:author>\n", toXMLString(collection.PodcastAuthor))
+	}
+	if collection.PodcastImage != "" {
+		fmt.Fprintf(out, "    <itunes:image href=\"%s\" />\n", toXMLString(collection.PodcastImage))
+	}
+	for _, category := range collection.PodcastCategories {
+		// Subcategories are written as "Parent/Child"
+		parent, child, found := strings.Cut(category, "/")
+		if !found {
+			fmt.Fprintf(out, "    <itunes:category text=\"%s\" />\n", toXMLString(strings.TrimSpace(category)))
+			continue
+		}
+		fmt.Fprintf(out, "    <itunes:category text=\"%s\">\n      <itunes:category text=\"%s\" />\n    </itunes:category>\n",
+			toXMLString(strings.TrimSpace(parent)), toXMLString(strings.TrimSpace(child)))
+	}
+	fmt.Fprintf(out, "    <itunes:explicit>%t</itunes:explicit>\n", collection.PodcastExplicit)
+}
+
+// writePodcastItem writes the iTunes and Podcasting 2.0 elements of an
+// episode saved as JSON in the podcastExt column.
+func writePodcastItem(out io.Writer, src string) error {
+	episode := &PodcastEpisode{}
+	if err := json.Unmarshal([]byte(src), episode); err != nil {
+		return err
+	}
+	if episode.Duration != "" {
+		fmt.Fprintf(out, "      <itunes:duration>%s</itunes:duration>\n", toXMLString(episode.Duration))
+	}
+	if episode.Explicit != nil {
+		fmt.Fprintf(out, "      <itunes:explicit>%t</itunes:explicit>\n", *episode.Explicit)
+	}
+	if episode.Season > 0 {
+		fmt.Fprintf(out, "      <itunes:season>%d</itunes:season>\n", episode.Season)
+	}
+	if episode.Episode > 0 {
+		fmt.Fprintf(out, "      <itunes:episode>%d</itunes:episode>\n", episode.Episode)
+	}
+	if episode.EpisodeType != "" {
+		fmt.Fprintf(out, "      <itunes:episodeType>%s</itunes:episodeType>\n", toXMLString(episode.EpisodeType))
+	}
+	if episode.Image != "" {
+		fmt.Fprintf(out, "      <itunes:image href=\"%s\" />\n", toXMLString(episode.Image))
+	}
+	for _, transcript := range episode.Transcripts {
+		fmt.Fprintf(out, "      <podcast:transcript url=\"%s\" type=\"%s\"", toXMLString(transcript.Url), toXMLString(transcript.Type))
+		if transcript.Language != "" {
+			fmt.Fprintf(out, " language=\"%s\"", toXMLString(transcript.Language))
+		}
+		if transcript.Rel != "" {
+			fmt.Fprintf(out, " rel=\"%s\"", toXMLString(transcript.Rel))
+		}
+		fmt.Fprintf(out, " />\n")
+	}
+	if episode.Chapters != nil {
+		fmt.Fprintf(out, "      <podcast:chapters url=\"%s\" type=\"%s\" />\n",
+			toXMLString(episode.Chapters.Url), toXMLString(episode.Chapters.Type))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+func TestMediaType(t *testing.T) {
+	for name, expected := range map[string]string{
+		"episode.mp3":   "audio/mpeg",
+		"episode.M4A":   "audio/mp4",
+		"episode.vtt":   "text/vtt",
+		"episode.jpg":   "image/jpeg",
+		"episode.bogus": "application/octet-stream",
+	} {
+		if got := mediaType(name); got != expected {
+			t.Errorf("mediaType(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestPostPodcastEpisode(t *testing.T) {
+	t.Chdir(t.TempDir())
+	audio := bytes.Repeat([]byte("ID3"), 100)
+	for _, dName := range []string{"episodes", filepath.Join("htdocs", "episodes")} {
+		if err := os.MkdirAll(dName, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, src := range map[string][]byte{
+		"episodes/episode-1.mp3": audio,
+		"episodes/episode-1.vtt": []byte("WEBVTT\n"),
+		"page.yaml":              []byte(DefaultGeneratorYaml),
+		"episodes/episode-1.md": []byte(`---
+title: Episode 1
+pubDate: "2025-09-01"
+enclosure: episode-1.mp3
+transcript:
+  url: episode-1.vtt
+  language: en
+chapters: https://example.com/episodes/episode-1-chapters.json
+duration: 1800
+explicit: false
+episode: 1
+season: 2
+---
+
+The first episode.
+`),
+	} {
+		if err := os.WriteFile(name, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	col := &Collection{
+		Title:             "The Podcast",
+		Description:       "A test podcast",
+		File:              "podcast.md",
+		DbName:            "podcast.db",
+		Generator:         "page.yaml",
+		Language:          "en-US",
+		Podcast:           true,
+		PodcastAuthor:     "Jane Doe",
+		PodcastImage:      "https://example.com/artwork.jpg",
+		PodcastCategories: []string{"Technology", "Society & Culture/Documentary"},
+	}
+	if err := os.WriteFile(col.File, []byte("# The Podcast\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setupDatabase(col.File, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &AppConfig{BaseURL: "https://example.com", Htdocs: "htdocs", Collections: []*Collection{col}}
+	if err := cfg.Post(col.File, "episodes/episode-1.md"); err != nil {
+		t.Fatalf("Post: %s", err)
+	}
+
+	// The local media is copied into htdocs
+	if src, err := os.ReadFile(filepath.Join("htdocs", "episodes", "episode-1.mp3")); err != nil || !bytes.Equal(src, audio) {
+		t.Errorf("expected the audio copied into htdocs, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("htdocs", "episodes", "episode-1.vtt")); err != nil {
+		t.Errorf("expected the transcript copied into htdocs, %s", err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var enclosures string
+	if err := db.QueryRow(`SELECT enclosures FROM items`).Scan(&enclosures); err != nil {
+		t.Fatal(err)
+	}
+	if enclosures != `[{"url":"https://example.com/episodes/episode-1.mp3","length":"300","type":"audio/mpeg"}]` {
+		t.Errorf("unexpected enclosures %s", enclosures)
+	}
+
+	buf := new(bytes.Buffer)
+	gen := &Generator{eout: io.Discard, BaseURL: cfg.BaseURL}
+	if err := gen.WriteCustomRSS(buf, db, SQLRssPosts, "https://example.com/podcast.xml", "antenna", col); err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range validateRSS(buf.Bytes()) {
+		t.Errorf("invalid RSS: %s", problem)
+	}
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, buf.String())
+	}
+	if feed.ITunesExt == nil || feed.ITunesExt.Author != "Jane Doe" || feed.ITunesExt.Image != col.PodcastImage ||
+		len(feed.ITunesExt.Categories) != 2 || feed.ITunesExt.Categories[1].Subcategory == nil ||
+		feed.ITunesExt.Categories[1].Subcategory.Text != "Documentary" || feed.ITunesExt.Explicit != "false" {
+		t.Errorf("unexpected itunes channel elements %+v\n%s", feed.ITunesExt, buf.String())
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("expected one item, got %d", len(feed.Items))
+	}
+	item := feed.Items[0]
+	// Only the audio is enclosed, not the post's Markdown
+	if len(item.Enclosures) != 1 || item.Enclosures[0].URL != "https://example.com/episodes/episode-1.mp3" {
+		t.Errorf("unexpected enclosures %+v", item.Enclosures)
+	}
+	if item.ITunesExt == nil || item.ITunesExt.Duration != "1800" || item.ITunesExt.Explicit != "false" ||
+		item.ITunesExt.Episode != "1" || item.ITunesExt.Season != "2" {
+		t.Errorf("unexpected itunes item elements %+v", item.ITunesExt)
+	}
+	transcripts := item.Extensions["podcast"]["transcript"]
+	if len(transcripts) != 1 || transcripts[0].Attrs["url"] != "https://example.com/episodes/episode-1.vtt" ||
+		transcripts[0].Attrs["type"] != "text/vtt" || transcripts[0].Attrs["language"] != "en" {
+		t.Errorf("unexpected podcast:transcript %+v", transcripts)
+	}
+	chapters := item.Extensions["podcast"]["chapters"]
+	if len(chapters) != 1 || chapters[0].Attrs["type"] != "application/json+chapters" {
+		t.Errorf("unexpected podcast:chapters %+v", chapters)
+	}
+
+	// Collections that aren't podcasts leave the episode out
+	col.Podcast = false
+	buf.Reset()
+	if err := gen.WriteCustomRSS(buf, db, SQLRssPosts, "https://example.com/podcast.xml", "antenna", col); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "itunes:") || strings.Contains(buf.String(), "podcast:") {
+		t.Errorf("expected no podcast elements\n%s", buf.String())
+	}
+}
+
+func TestPostPodcastBaselineDatabase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, src := range map[string]string{
+		"page.yaml":  DefaultGeneratorYaml,
+		"podcast.md": "# The Podcast\n",
+		"episode-1.md": `---
+title: Episode 1
+pubDate: "2025-09-01"
+duration: 1800
+episode: 1
+---
+
+The first episode.
+`,
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll("htdocs", 0755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", "podcast.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// A database from before podcastExt was added, without a harvest to
+	// upgrade it
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: "podcast.md", DbName: "podcast.db", Generator: "page.yaml", Podcast: true}
+	cfg := &AppConfig{BaseURL: "https://example.com", Htdocs: "htdocs", Collections: []*Collection{col}}
+	if err := cfg.Post(col.File, "episode-1.md"); err != nil {
+		t.Fatalf("Post: %s", err)
+	}
+	var podcastExt string
+	if err := db.QueryRow(`SELECT podcastExt FROM items`).Scan(&podcastExt); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(podcastExt, "1800") {
+		t.Errorf("expected the episode's podcast fields, got %q", podcastExt)
+	}
+}
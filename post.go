@@ -17,7 +17,6 @@ You should have received a copy of the GNU Affero General Public License
 package antennaApp
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -208,7 +207,7 @@ func (cfg *AppConfig) RssPosts(cName string, rssFeed string, count int, fromDate
 		return fmt.Errorf("%s, %s", cName, err)
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
@@ -219,6 +218,28 @@ func (cfg *AppConfig) RssPosts(cName string, rssFeed string, count int, fromDate
 		return err
 	}
 
+	// A feed filename ending in .atom is written as an Atom feed
+	if strings.ToLower(filepath.Ext(rssFeed)) == ".atom" {
+		switch {
+		case fromDate != "" && toDate != "":
+			return gen.WriteCustomAtom(out, db, SQLRssDateRangePosts, feedLink, appName, collection, fromDate, toDate)
+		case count > 0:
+			return gen.WriteCustomAtom(out, db, SQLRssRecentPosts, feedLink, appName, collection, count)
+		default:
+			return gen.WriteCustomAtom(out, db, SQLRssPosts, feedLink, appName, collection)
+		}
+	}
+	// A feed filename ending in .json is written as a JSON Feed
+	if strings.ToLower(filepath.Ext(rssFeed)) == ".json" {
+		switch {
+		case fromDate != "" && toDate != "":
+			return gen.WriteCustomJSONFeed(out, db, SQLRssDateRangePosts, feedLink, collection, fromDate, toDate)
+		case count > 0:
+			return gen.WriteCustomJSONFeed(out, db, SQLRssRecentPosts, feedLink, collection, count)
+		default:
+			return gen.WriteCustomJSONFeed(out, db, SQLRssPosts, feedLink, collection)
+		}
+	}
 	switch {
 	case fromDate != "" && toDate != "":
 		return gen.WriteCustomRSS(out, db, SQLRssDateRangePosts, feedLink, appName, collection, fromDate, toDate)
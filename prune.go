@@ -0,0 +1,198 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy describes which harvested items a collection keeps.
+// Items with a postPath are posts and are never removed.
+type RetentionPolicy struct {
+	// MaxAge is the number of days an item is kept after it was published
+	MaxAge int `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	// MaxItemsPerFeed is the number of the newest items kept for each feed
+	MaxItemsPerFeed int `json:"max_items_per_feed,omitempty" yaml:"max_items_per_feed,omitempty"`
+	// KeepOnlyPublished removes the items that are not published
+	KeepOnlyPublished bool `json:"keep_only_published,omitempty" yaml:"keep_only_published,omitempty"`
+}
+
+// IsEmpty reports if the policy has no rules
+func (policy *RetentionPolicy) IsEmpty() bool {
+	return policy == nil || (policy.MaxAge <= 0 && policy.MaxItemsPerFeed <= 0 && !policy.KeepOnlyPublished)
+}
+
+// prunedItem is an item selected for removal by a retention rule
+type prunedItem struct {
+	link   string
+	title  string
+	label  string
+	reason string
+}
+
+// pruneCandidates returns the items the policy would remove, each item is
+// listed once with the first rule that selected it. The keep_only_published
+// rule is only applied when unpublished is set.
+func (policy *RetentionPolicy) pruneCandidates(db *sql.DB, now time.Time, unpublished bool) ([]prunedItem, error) {
+	type rule struct {
+		stmt   string
+		args   []interface{}
+		reason string
+	}
+	rules := []rule{}
+	if policy.MaxAge > 0 {
+		cutoff := now.AddDate(0, 0, -policy.MaxAge).Format("2006-01-02 15:04:05")
+		rules = append(rules, rule{SQLPruneByAge, []interface{}{cutoff}, fmt.Sprintf("older than %d days", policy.MaxAge)})
+	}
+	if policy.MaxItemsPerFeed > 0 {
+		rules = append(rules, rule{SQLPruneByFeedCount, []interface{}{policy.MaxItemsPerFeed}, fmt.Sprintf("beyond %d items for its feed", policy.MaxItemsPerFeed)})
+	}
+	if policy.KeepOnlyPublished && unpublished {
+		rules = append(rules, rule{SQLPruneUnpublished, nil, "not published"})
+	}
+	seen := map[string]bool{}
+	items := []prunedItem{}
+	for _, r := range rules {
+		rows, err := db.Query(r.stmt, r.args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s\nstmt: %s", err, r.stmt)
+		}
+		for rows.Next() {
+			item := prunedItem{reason: r.reason}
+			if err := rows.Scan(&item.link, &item.title, &item.label); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !seen[item.link] {
+				seen[item.link] = true
+				items = append(items, item)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// prune applies the collection's retention policy to db. With dryRun
+// set the items are reported to out but not removed. The keep_only_published
+// rule is applied only when unpublished is set, the prune action sets it,
+// harvest doesn't so new items can be curated. It returns the number of
+// items removed, or that would be removed.
+func (collection *Collection) prune(out io.Writer, db *sql.DB, dryRun bool, unpublished bool) (int, error) {
+	if collection.Retention.IsEmpty() {
+		return 0, nil
+	}
+	items, err := collection.Retention.pruneCandidates(db, time.Now(), unpublished)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		if dryRun {
+			fmt.Fprintf(out, "would remove %s %q from %s, %s\n", item.link, item.title, item.label, item.reason)
+			continue
+		}
+		if _, err := db.Exec(SQLPruneItem, item.link); err != nil {
+			return 0, fmt.Errorf("%s\nstmt: %s", err, SQLPruneItem)
+		}
+	}
+	return len(items), nil
+}
+
+/** Prune removes the harvested items a collection's retention policy no
+ * longer keeps. Posts (items with a postPath) are never removed.
+ *
+ * Parameters:
+ *   out    (io.Writer) — destination for the report
+ *   cName  (string)    — collection filename (e.g. "planet.md")
+ *   dryRun (bool)      — report the items without removing them
+ *
+ * Returns:
+ *   error — non-nil if the collection is unknown or the database fails
+ *
+ * Example:
+ *   err := cfg.Prune(os.Stdout, "planet.md", true)
+ */
+func (cfg *AppConfig) Prune(out io.Writer, cName string, dryRun bool) error {
+	collection, err := cfg.GetCollection(cName)
+	if err != nil {
+		return fmt.Errorf("%s, %s", cName, err)
+	}
+	if collection.Retention.IsEmpty() {
+		return fmt.Errorf("no retention policy set for %s", collection.File)
+	}
+	db, err := openCollectionDB(collection.DbName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	cnt, err := collection.prune(out, db, dryRun, true)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(out, "%d items would be pruned from %s\n", cnt, collection.File)
+	} else {
+		fmt.Fprintf(out, "pruned %d items from %s\n", cnt, collection.File)
+	}
+	return nil
+}
+
+/** Prune applies a collection's retention policy from antenna.yaml.
+ *
+ * Parameters:
+ *   out     (io.Writer) — destination for the report
+ *   cfgName (string)    — path to antenna.yaml
+ *   args    ([]string)  — [--dry-run] collection-filename
+ *
+ * Returns:
+ *   error — non-nil on configuration or database failure
+ *
+ * Example:
+ *   err := app.Prune(os.Stdout, "antenna.yaml", []string{"--dry-run", "planet.md"})
+ */
+func (app *AntennaApp) Prune(out io.Writer, cfgName string, args []string) error {
+	dryRun := false
+	names := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			names = append(names, arg)
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "dry-run":
+			dryRun = true
+		default:
+			return fmt.Errorf("unknown prune option %q", arg)
+		}
+	}
+	if len(names) != 1 {
+		return fmt.Errorf("expected a collection name")
+	}
+	cfg := &AppConfig{}
+	if err := cfg.LoadConfig(cfgName); err != nil {
+		return err
+	}
+	return cfg.Prune(out, strings.TrimSpace(names[0]), dryRun)
+}
@@ -0,0 +1,170 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectionPrune(t *testing.T) {
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "planet.md")
+	col := &Collection{
+		File:   cName,
+		DbName: filepath.Join(dName, "planet.db"),
+		Retention: &RetentionPolicy{
+			MaxAge:          30,
+			MaxItemsPerFeed: 2,
+		},
+	}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, -n).Format("2006-01-02 15:04:05")
+	}
+	for _, row := range []struct {
+		link, channel, pubDate, postPath, status string
+	}{
+		{"https://example.org/old-post", "", day(400), "blog/old-post.md", "published"},
+		{"https://a.example.org/old", "https://a.example.org/feed.xml", day(90), "", ""},
+		{"https://a.example.org/1", "https://a.example.org/feed.xml", day(1), "", ""},
+		{"https://a.example.org/2", "https://a.example.org/feed.xml", day(2), "", ""},
+		{"https://a.example.org/3", "https://a.example.org/feed.xml", day(3), "", ""},
+		{"https://b.example.org/1", "https://b.example.org/feed.xml", day(5), "", ""},
+	} {
+		if _, err := db.Exec(`INSERT INTO items (link, title, channel, pubDate, postPath, status, label) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			row.link, "Title", row.channel, row.pubDate, row.postPath, row.status, "Label"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	countItems := func() int {
+		cnt := 0
+		if err := db.QueryRow(SQLItemCount).Scan(&cnt); err != nil {
+			t.Fatal(err)
+		}
+		return cnt
+	}
+
+	var out bytes.Buffer
+	cnt, err := col.prune(&out, db, true, true)
+	if err != nil {
+		t.Fatalf("prune (dry run): %s", err)
+	}
+	if cnt != 2 || countItems() != 6 {
+		t.Errorf("expected a dry run to report 2 items and remove none, got %d and %d items left", cnt, countItems())
+	}
+	if !strings.Contains(out.String(), "would remove https://a.example.org/old") ||
+		!strings.Contains(out.String(), "would remove https://a.example.org/3") {
+		t.Errorf("unexpected dry run report %s", out.String())
+	}
+
+	if _, err := col.prune(&out, db, false, true); err != nil {
+		t.Fatalf("prune: %s", err)
+	}
+	if countItems() != 4 {
+		t.Errorf("expected 4 items to be kept, got %d", countItems())
+	}
+	var postPath string
+	if err := db.QueryRow(`SELECT postPath FROM items WHERE link = ?`, "https://example.org/old-post").Scan(&postPath); err != nil {
+		t.Errorf("expected the post to be kept, %s", err)
+	}
+
+	// Only the post survives keep_only_published
+	col.Retention = &RetentionPolicy{KeepOnlyPublished: true}
+	if _, err := col.prune(&out, db, false, true); err != nil {
+		t.Fatalf("prune (keep only published): %s", err)
+	}
+	if countItems() != 1 {
+		t.Errorf("expected only the post to be kept, got %d items", countItems())
+	}
+}
+
+func TestPruneAfterHarvest(t *testing.T) {
+	dName := t.TempDir()
+	col := &Collection{
+		File:      filepath.Join(dName, "planet.md"),
+		DbName:    filepath.Join(dName, "planet.db"),
+		Retention: &RetentionPolicy{MaxAge: 30, KeepOnlyPublished: true},
+	}
+	if err := setupDatabase(col.File, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	now := time.Now()
+	for _, row := range []struct {
+		link, pubDate, updated, status string
+	}{
+		// A date SQLite can't read falls back to updated
+		{"https://example.org/old", "Mon, 02 Jan 2006 15:04:05 -0700", now.AddDate(0, 0, -90).Format(time.RFC3339), "published"},
+		{"https://example.org/new", now.AddDate(0, 0, -1).Format(time.RFC3339), "", ""},
+		{"https://example.org/published", now.AddDate(0, 0, -2).Format("2006-01-02 15:04:05"), "", "published"},
+	} {
+		if _, err := db.Exec(`INSERT INTO items (link, title, pubDate, updated, status, label) VALUES (?, 'Title', ?, ?, ?, 'Label')`,
+			row.link, row.pubDate, row.updated, row.status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := func() string {
+		t.Helper()
+		rows, err := db.Query(`SELECT link FROM items ORDER BY link`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		found := []string{}
+		for rows.Next() {
+			var link string
+			if err := rows.Scan(&link); err != nil {
+				t.Fatal(err)
+			}
+			found = append(found, link)
+		}
+		return strings.Join(found, " ")
+	}
+
+	// A harvest leaves the new, not yet curated, item alone
+	var out bytes.Buffer
+	if _, err := col.prune(&out, db, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := links(); got != "https://example.org/new https://example.org/published" {
+		t.Errorf("expected the old item removed and the new one kept after a harvest, got %s", got)
+	}
+	// The prune action applies keep_only_published
+	if _, err := col.prune(&out, db, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := links(); got != "https://example.org/published" {
+		t.Errorf("expected only the published item kept, got %s", got)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -60,9 +61,27 @@ func toXMLString(input string) string {
 	return input
 }
 
+// rssDate returns a stored pubDate/updated value as an RFC 1123 date with
+// a numeric zone, the form RSS 2.0 calls for, or an empty string if it
+// can't be parsed.
+func rssDate(raw string) string {
+	if t, ok := parseItemDate(raw); ok {
+		return t.Format(time.RFC1123Z)
+	}
+	return ""
+}
+
+// isPermaLink reports if a guid is a URL that can be opened in a web
+// browser, the guid's isPermaLink attribute in RSS 2.0.
+func isPermaLink(guid string) bool {
+	u, err := url.Parse(guid)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func (gen *Generator) WriteItemRSS(out io.Writer, link string, title string, description string, authors []*gofeed.Person,
 	enclosures []*Enclosure, guid string, pubDate string, dcExt string,
-	channel string, status string, updated string, label string, sourceMarkdown string, categories string) error {
+	channel string, status string, updated string, label string, sourceMarkdown string, categories string, sourceOutline string,
+	podcastExt string) error {
 	// Setup expressing update time.
 	pressTime := pubDate
 	if len(pressTime) > 10 {
@@ -77,7 +96,7 @@ func (gen *Generator) WriteItemRSS(out io.Writer, link string, title string, des
 	// Wrap the Item
 	fmt.Fprintf(out, `    <item>
 `)
-	defer fmt.Fprintf(out, `    </item>`)
+	defer fmt.Fprintf(out, "    </item>\n")
 	// Setup the Title
 	if title != "" {
 		fmt.Fprintf(out, "      <title>%s</title>\n", strings.TrimSpace(toXMLString(title)))
@@ -91,8 +110,15 @@ func (gen *Generator) WriteItemRSS(out io.Writer, link string, title string, des
       </description>
 `, indentText(sanitizeCDATA(strings.TrimSpace(description)), 8))
 	}
+	// The Markdown is written as is, without trimming, so it can be
+	// harvested byte for byte.
 	if sourceMarkdown != "" {
-		fmt.Fprintf(out, "      <source:markdown>%s</source:markdown>\n", strings.TrimSpace(toXMLString(sourceMarkdown)))
+		fmt.Fprintf(out, "      <source:markdown>%s</source:markdown>\n", sourceMarkdownString(sourceMarkdown))
+	}
+	if sourceOutline != "" {
+		if err := writeSourceOutlines(out, sourceOutline, 6); err != nil {
+			fmt.Fprintf(gen.eout, "error (source:outline %s): %s\n", sourceOutline, err)
+		}
 	}
 	if authors != nil {
 		for _, author := range authors {
@@ -107,8 +133,8 @@ func (gen *Generator) WriteItemRSS(out io.Writer, link string, title string, des
 `, strings.TrimSpace(enclosure.Url), enclosure.Length, strings.TrimSpace(enclosure.Type))
 		}
 	}
-	if guid != "" {
-		fmt.Fprintf(out, "      <guid>cid://%s</guid>\n", strings.TrimSpace(toXMLString(guid)))
+	if guid = strings.TrimSpace(guid); guid != "" {
+		fmt.Fprintf(out, "      <guid isPermaLink=\"%t\">%s</guid>\n", isPermaLink(guid), toXMLString(guid))
 	}
 	if categories != "" {
 		var cats []string
@@ -120,12 +146,19 @@ func (gen *Generator) WriteItemRSS(out io.Writer, link string, title string, des
 			}
 		}
 	}
-	if pubDate != "" {
-		d, err := time.Parse("2006-01-02", pubDate)
-		if err == nil {
-			fmt.Fprintf(out, "      <pubDate>%s</pubDate>\n", d.Format(time.RFC822Z))
+	if dcExt != "" && dcExt != "null" {
+		if err := writeDublinCoreRSS(out, dcExt); err != nil {
+			fmt.Fprintf(gen.eout, "error (dc %s): %s\n", dcExt, err)
+		}
+	}
+	if podcastExt != "" {
+		if err := writePodcastItem(out, podcastExt); err != nil {
+			fmt.Fprintf(gen.eout, "error (podcast %s): %s\n", podcastExt, err)
 		}
 	}
+	if d := rssDate(pubDate); d != "" {
+		fmt.Fprintf(out, "      <pubDate>%s</pubDate>\n", d)
+	}
 	return nil
 }
 
@@ -146,13 +179,17 @@ func (gen *Generator) WriteRSS(out io.Writer, db *sql.DB, appName string, collec
 
 // WriteCustomRSS generates a custom RSS feed given a SQL statement
 func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string, feedLink string, appName string, collection *Collection, args ...any) error {
+	// Podcasts declare the iTunes and Podcasting 2.0 namespaces
+	podcastNamespaces := ""
+	if collection.Podcast {
+		podcastNamespaces = fmt.Sprintf(" xmlns:itunes=%q xmlns:podcast=%q", itunesNamespace, podcastNamespace)
+	}
 	fmt.Fprintf(out, `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:source="https://source.scripting.com/">
-  <atom:link href=%q rel="self" type="application/rss+xml" />
+<rss version="2.0" xmlns:atom=%q xmlns:dc=%q xmlns:source=%q%s>
   <channel>
-`, feedLink)
-	defer fmt.Fprintln(out, `
-  </channel>
+    <atom:link href=%q rel="self" type="application/rss+xml" />
+`, atomNamespace, dublinCoreNamespace, sourceNamespace, podcastNamespaces, feedLink)
+	defer fmt.Fprintln(out, `  </channel>
 </rss>`)
 	// Channel Metadata
 	if collection.Title != "" {
@@ -166,6 +203,9 @@ func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string,
 		fmt.Fprintf(out, `    <link>%s</link>
 `, feedLink)
 	}
+	if collection.Language != "" {
+		fmt.Fprintf(out, "    <language>%s</language>\n", toXMLString(strings.TrimSpace(collection.Language)))
+	}
 	if collection.Copyright != "" {
 		fmt.Fprintf(out, `    <copyright>%s</copyright>
 `, strings.TrimSpace(collection.Copyright))
@@ -179,17 +219,25 @@ func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string,
 `, strings.TrimSpace(collection.WebMaster))
 	}
 	if collection.PubDate != "" {
+		pubDate := rssDate(collection.PubDate)
+		if pubDate == "" {
+			pubDate = strings.TrimSpace(collection.PubDate)
+		}
 		fmt.Fprintf(out, `    <pubDate>%s</pubDate>
-`, strings.TrimSpace(collection.PubDate))
+`, pubDate)
 	}
 	// The following are hardcode because they are dependent on the generator and
 	// when it executed.
-	timestamp := time.Now().Format(time.RFC822Z)
+	timestamp := gen.buildTime().Format(time.RFC1123Z)
 	fmt.Fprintf(out, `    <lastBuildDate>%s</lastBuildDate>
 `, timestamp)
 	fmt.Fprintf(out, `    <generator>%s/%s</generator>
     <docs>https://cyber.harvard.edu/rss/rss.html</docs>
 `, appName, Version)
+	writeSourceChannel(out, collection)
+	if collection.Podcast {
+		writePodcastChannel(out, collection)
+	}
 
 	// Setup  items
 	//stmt := SQLDisplayItems
@@ -219,11 +267,16 @@ func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string,
 			postPath       string
 			sourceMarkdown string
 			categories     string
+			// fullMarkdown is the article retrieved from the item's link,
+			// it isn't republished in the feed
+			fullMarkdown  string
+			sourceOutline string
+			podcastExt    string
 		)
 		if err := rows.Scan(&link, &title, &description, &authorsSrc,
 			&enclosuresSrc, &guid, &pubDate, &dcExt,
 			&channel, &status, &updated, &label, &postPath, &sourceMarkdown,
-			&categories); err != nil {
+			&categories, &fullMarkdown, &sourceOutline, &podcastExt); err != nil {
 			return err
 		}
 		if authorsSrc != "" {
@@ -241,7 +294,8 @@ func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string,
 				enclosures = nil
 			}
 		}
-		if postPath != "" {
+		// A podcast episode's enclosure is its media, not the Markdown
+		if postPath != "" && !(collection.Podcast && len(enclosures) > 0) {
 			if fi, err := os.Stat(postPath); err == nil {
 				enclosure := &Enclosure{
 					Url:    gen.BaseURL + "/" + postPath,
@@ -267,9 +321,13 @@ func (gen *Generator) WriteCustomRSS(out io.Writer, db *sql.DB, sqlStmt string,
 				}
 			}
 		}
+		// Episode details are only written to podcast feeds
+		if !collection.Podcast {
+			podcastExt = ""
+		}
 		if err := gen.WriteItemRSS(out, link, title, description, authors,
 			enclosures, guid, pubDate, dcExt,
-			channel, status, updated, label, sourceMarkdown, categories); err != nil {
+			channel, status, updated, label, sourceMarkdown, categories, sourceOutline, podcastExt); err != nil {
 			return err
 		}
 	}
@@ -20,8 +20,14 @@ package antennaApp
 import (
 	"bytes"
 	"encoding/xml"
+	"flag"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // validateXML parses the given XML bytes and returns any parse error.
@@ -78,7 +84,7 @@ func TestWriteItemRSS_ValidXMLWithHTMLEntities(t *testing.T) {
 	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel>`)
 	err := gen.WriteItemRSS(&buf, "http://example.com/", "Title &mdash; Subtitle",
 		"<p>A post&mdash;with entities &amp; more &nbsp; content</p>",
-		nil, nil, "guid-1", "2026-06-27", "", "", "published", "", "", "", "")
+		nil, nil, "guid-1", "2026-06-27", "", "", "published", "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("WriteItemRSS: %s", err)
 	}
@@ -95,7 +101,7 @@ func TestWriteItemRSS_ValidXMLWithCDATATerminatorInDescription(t *testing.T) {
 	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel>`)
 	err := gen.WriteItemRSS(&buf, "http://example.com/", "JS Example",
 		"Use <![CDATA[ ]]> in scripts to embed data",
-		nil, nil, "guid-2", "2026-06-27", "", "", "published", "", "", "", "")
+		nil, nil, "guid-2", "2026-06-27", "", "", "published", "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("WriteItemRSS: %s", err)
 	}
@@ -139,3 +145,218 @@ func TestWriteCustomRSS_ValidXMLWithProblematicContent(t *testing.T) {
 		t.Errorf("RSS feed is not valid XML: %s\nOutput:\n%s", err, buf.String())
 	}
 }
+
+// updateGolden rewrites the golden files from the current output,
+// run `go test -run Golden -update` after an intended change in the RSS.
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// rssGoldenItem is an item row for the RSS golden file tests
+type rssGoldenItem struct {
+	link, title, description, authors, enclosures, guid, pubDate, sourceMarkdown, categories string
+}
+
+// validateRSS checks src against the rules of the RSS 2.0 specification
+// that the writer is responsible for and returns the problems found.
+func validateRSS(src []byte) []string {
+	type guidElement struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	type enclosureElement struct {
+		URL    string `xml:"url,attr"`
+		Length string `xml:"length,attr"`
+		Type   string `xml:"type,attr"`
+	}
+	type itemElement struct {
+		Title       string             `xml:"title"`
+		Description string             `xml:"description"`
+		PubDate     []string           `xml:"pubDate"`
+		GUID        []guidElement      `xml:"guid"`
+		Enclosures  []enclosureElement `xml:"enclosure"`
+	}
+	type rssElement struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		Channel []struct {
+			Title         string        `xml:"title"`
+			Link          string        `xml:"link"`
+			Description   string        `xml:"description"`
+			PubDate       string        `xml:"pubDate"`
+			LastBuildDate string        `xml:"lastBuildDate"`
+			Items         []itemElement `xml:"item"`
+		} `xml:"channel"`
+	}
+	doc := rssElement{}
+	if err := xml.Unmarshal(src, &doc); err != nil {
+		return []string{fmt.Sprintf("not well formed XML: %s", err)}
+	}
+	problems := []string{}
+	isDate := func(s string) bool {
+		_, err := time.Parse(time.RFC1123Z, s)
+		return err == nil
+	}
+	if doc.Version != "2.0" {
+		problems = append(problems, fmt.Sprintf("rss version %q, expected 2.0", doc.Version))
+	}
+	if len(doc.Channel) != 1 {
+		return append(problems, fmt.Sprintf("%d channel elements, expected 1", len(doc.Channel)))
+	}
+	channel := doc.Channel[0]
+	if channel.Title == "" || channel.Link == "" || channel.Description == "" {
+		problems = append(problems, "channel requires title, link and description")
+	}
+	if channel.PubDate != "" && !isDate(channel.PubDate) {
+		problems = append(problems, fmt.Sprintf("channel pubDate %q is not RFC 1123Z", channel.PubDate))
+	}
+	if !isDate(channel.LastBuildDate) {
+		problems = append(problems, fmt.Sprintf("lastBuildDate %q is not RFC 1123Z", channel.LastBuildDate))
+	}
+	for i, item := range channel.Items {
+		if item.Title == "" && item.Description == "" {
+			problems = append(problems, fmt.Sprintf("item %d requires a title or description", i))
+		}
+		if len(item.PubDate) > 1 || len(item.GUID) > 1 {
+			problems = append(problems, fmt.Sprintf("item %d repeats pubDate or guid", i))
+		}
+		for _, pubDate := range item.PubDate {
+			if !isDate(pubDate) {
+				problems = append(problems, fmt.Sprintf("item %d pubDate %q is not RFC 1123Z", i, pubDate))
+			}
+		}
+		for _, guid := range item.GUID {
+			switch guid.IsPermaLink {
+			case "true":
+				if !isPermaLink(guid.Value) {
+					problems = append(problems, fmt.Sprintf("item %d guid %q is not a URL but isPermaLink is true", i, guid.Value))
+				}
+			case "false":
+			default:
+				problems = append(problems, fmt.Sprintf("item %d guid has isPermaLink %q", i, guid.IsPermaLink))
+			}
+		}
+		for _, enclosure := range item.Enclosures {
+			if enclosure.URL == "" || enclosure.Length == "" || enclosure.Type == "" {
+				problems = append(problems, fmt.Sprintf("item %d enclosure requires url, length and type", i))
+			}
+		}
+	}
+	return problems
+}
+
+func TestWriteCustomRSSGolden(t *testing.T) {
+	buildTime := time.Date(2025, time.September, 15, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name       string
+		collection *Collection
+		items      []rssGoldenItem
+	}{
+		{
+			name: "dates",
+			collection: &Collection{
+				Title:       "Dates",
+				Description: "Items stored in each of the date layouts",
+				File:        "dates.md",
+				PubDate:     "2025-09-01",
+			},
+			items: []rssGoldenItem{
+				{link: "https://example.com/rfc3339", title: "RFC 3339", description: "Saved by the sqlite driver",
+					guid: "https://example.com/rfc3339", pubDate: "2025-09-04T08:15:00Z"},
+				{link: "https://example.com/offset", title: "RFC 3339 with an offset", description: "Keeps its zone",
+					guid: "https://example.com/offset", pubDate: "2025-09-03T08:15:00-07:00"},
+				{link: "https://example.com/harvested", title: "Harvested", description: "Saved by saveItem",
+					guid: "https://example.com/harvested", pubDate: "2025-09-02 10:30:00"},
+				{link: "https://example.com/post", title: "Post", description: "Saved by the post action",
+					guid: "https://example.com/post", pubDate: "2025-09-01"},
+				{link: "https://example.com/unparsed", title: "Unparsed", description: "gofeed couldn't parse the date",
+					guid: "https://example.com/unparsed", pubDate: "the first of September"},
+			},
+		},
+		{
+			name: "guids",
+			collection: &Collection{
+				Title:       "GUIDs",
+				Description: "Items with permalink and opaque guids",
+				File:        "guids.md",
+			},
+			items: []rssGoldenItem{
+				{link: "https://example.com/permalink", title: "Permalink", description: "The guid is the link",
+					guid: "https://example.com/permalink", pubDate: "2025-09-04 00:00:00"},
+				{link: "https://example.com/opaque", title: "Opaque", description: "The guid is an identifier",
+					guid: "tag:example.com,2025:opaque", pubDate: "2025-09-03 00:00:00"},
+				{link: "https://example.com/no-guid", title: "No guid", description: "No guid is written",
+					pubDate: "2025-09-02 00:00:00"},
+				// Older versions wrote every guid as cid://GUID, it is now
+				// written as saved
+				{link: "https://example.com/saved", title: "Saved guid", description: "Written as cid://12345 by older versions",
+					guid: "12345", pubDate: "2025-09-02 00:00:00"},
+				{link: "https://example.com/episode", title: "Episode & more", description: "<p>An <em>episode</em></p>",
+					authors:        `[{"name":"Jane Doe","email":"jane@example.com"}]`,
+					enclosures:     `[{"url":"https://example.com/episode.mp3","length":"1234","type":"audio/mpeg"}]`,
+					guid:           "urn:uuid:0b0c6a9e-3c5c-4f4a-9d55-0d8e2b1d1e11",
+					pubDate:        "2025-09-01 00:00:00",
+					sourceMarkdown: "An *episode*\n",
+					categories:     `["podcast"]`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestItemsDB(t)
+			defer db.Close()
+			for _, item := range tc.items {
+				if _, err := db.Exec(`INSERT INTO items
+					(link, title, description, authors, enclosures, guid, pubDate, status, sourceMarkdown, categories)
+					VALUES (?, ?, ?, ?, ?, ?, ?, 'published', ?, ?)`,
+					item.link, item.title, item.description, item.authors, item.enclosures,
+					item.guid, item.pubDate, item.sourceMarkdown, item.categories); err != nil {
+					t.Fatalf("insert item: %s", err)
+				}
+			}
+			gen := &Generator{eout: io.Discard, now: func() time.Time { return buildTime }}
+			buf := new(bytes.Buffer)
+			feedLink := "https://example.com/" + strings.TrimSuffix(tc.collection.File, ".md") + ".xml"
+			if err := gen.WriteCustomRSS(buf, db, SQLDisplayItems, feedLink, "antenna", tc.collection); err != nil {
+				t.Fatalf("WriteCustomRSS: %s", err)
+			}
+			// The version changes with each release
+			got := bytes.ReplaceAll(buf.Bytes(), []byte("antenna/"+Version), []byte("antenna/VERSION"))
+			for _, problem := range validateRSS(got) {
+				t.Errorf("invalid RSS: %s", problem)
+			}
+			gName := filepath.Join("testdata", "rss", tc.name+".xml")
+			if *updateGolden {
+				if err := os.WriteFile(gName, got, 0664); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(gName)
+			if err != nil {
+				t.Fatalf("%s, run go test -run Golden -update to create it", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("RSS differs from %s\nexpected\n%s\ngot\n%s", gName, expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateRSS(t *testing.T) {
+	// The checks must catch the mistakes the writer used to make
+	src := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Old</title>
+    <link>https://example.com/</link>
+    <description>Dates and guids as written before</description>
+    <lastBuildDate>15 Sep 25 12:00 +0000</lastBuildDate>
+    <item>
+      <title>Item</title>
+      <guid>cid://https://example.com/item</guid>
+      <pubDate>01 Sep 25 00:00 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`)
+	if problems := validateRSS(src); len(problems) != 3 {
+		t.Errorf("expected 3 problems, got %d: %v", len(problems), problems)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// feedSchedule holds the hints a feed and its server provide about how
+// often the feed should be harvested.
+type feedSchedule struct {
+	// TTL comes from the feed's <ttl> or <sy:updatePeriod> elements
+	TTL time.Duration
+	// SkipHours holds the hours (0-23, GMT) from the feed's <skipHours>
+	SkipHours []int
+	// SkipDays holds the day names from the feed's <skipDays>
+	SkipDays []string
+	// MaxAge comes from the Cache-Control max-age response header
+	MaxAge time.Duration
+	// RetryAfter comes from the Retry-After response header
+	RetryAfter time.Duration
+}
+
+// syndicationPeriods maps sy:updatePeriod values to durations, see
+// https://web.resource.org/rss/1.0/modules/syndication/
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// feedScheduleFromSource reads the scheduling hints from a parsed feed.
+// The <ttl>, <skipHours> and <skipDays> elements aren't part of gofeed's
+// universal feed so RSS sources are parsed a second time to find them.
+func feedScheduleFromSource(src []byte, feed *gofeed.Feed) feedSchedule {
+	schedule := feedSchedule{}
+	if feed.FeedType == "rss" {
+		fp := &rss.Parser{}
+		if rssFeed, err := fp.Parse(bytes.NewReader(src)); err == nil {
+			if minutes, err := strconv.Atoi(strings.TrimSpace(rssFeed.TTL)); err == nil && minutes > 0 {
+				schedule.TTL = time.Duration(minutes) * time.Minute
+			}
+			for _, val := range rssFeed.SkipHours {
+				if hour, err := strconv.Atoi(strings.TrimSpace(val)); err == nil && hour >= 0 && hour < 24 {
+					schedule.SkipHours = append(schedule.SkipHours, hour)
+				}
+			}
+			for _, val := range rssFeed.SkipDays {
+				if day := strings.TrimSpace(val); day != "" {
+					schedule.SkipDays = append(schedule.SkipDays, day)
+				}
+			}
+		}
+	}
+	if sy, ok := feed.Extensions["sy"]; ok {
+		period := ""
+		if vals, ok := sy["updatePeriod"]; ok && len(vals) > 0 {
+			period = strings.ToLower(strings.TrimSpace(vals[0].Value))
+		}
+		if d, ok := syndicationPeriods[period]; ok {
+			frequency := 1
+			if vals, ok := sy["updateFrequency"]; ok && len(vals) > 0 {
+				if val, err := strconv.Atoi(strings.TrimSpace(vals[0].Value)); err == nil && val > 0 {
+					frequency = val
+				}
+			}
+			if interval := d / time.Duration(frequency); interval > schedule.TTL {
+				schedule.TTL = interval
+			}
+		}
+	}
+	return schedule
+}
+
+// parseCacheHeaders returns the Cache-Control max-age and Retry-After
+// values of a response. Retry-After may be given in seconds or as an
+// HTTP date.
+func parseCacheHeaders(header http.Header, now time.Time) (time.Duration, time.Duration) {
+	var maxAge, retryAfter time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if val, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if seconds, err := strconv.Atoi(strings.Trim(val, `"`)); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if val := strings.TrimSpace(header.Get("Retry-After")); val != "" {
+		if seconds, err := strconv.Atoi(val); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(val); err == nil && t.After(now) {
+			retryAfter = t.Sub(now)
+		}
+	}
+	return maxAge, retryAfter
+}
+
+// skipped reports if t falls in one of the feed's skip hours or skip days.
+// Following the RSS 2.0 specification the hours are in GMT.
+func (schedule feedSchedule) skipped(t time.Time) bool {
+	t = t.UTC()
+	for _, hour := range schedule.SkipHours {
+		if t.Hour() == hour {
+			return true
+		}
+	}
+	for _, day := range schedule.SkipDays {
+		if strings.EqualFold(t.Weekday().String(), day) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextHarvest returns the earliest time the feed should be harvested
+// again. The wait is the longest of the collection's TTL and the feed and
+// server hints, then moved forward past any skip hours or skip days.
+func (schedule feedSchedule) nextHarvest(now time.Time, collectionTTL time.Duration) time.Time {
+	wait := collectionTTL
+	for _, d := range []time.Duration{schedule.TTL, schedule.MaxAge, schedule.RetryAfter} {
+		if d > wait {
+			wait = d
+		}
+	}
+	next := now.Add(wait)
+	// A week of hours is enough to get past any combination of skips.
+	for i := 0; i < 24*7 && schedule.skipped(next); i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+	return next
+}
+
+// encodeSkipHours and encodeSkipDays turn the skip lists into the comma
+// separated strings stored in the feed_health table.
+func encodeSkipHours(hours []int) string {
+	parts := []string{}
+	for _, hour := range hours {
+		parts = append(parts, strconv.Itoa(hour))
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeSkipDays(days []string) string {
+	return strings.Join(days, ",")
+}
+
+// decodeFeedSchedule restores the feed hints saved in the feed_health table.
+func decodeFeedSchedule(ttl int, skipHours string, skipDays string) feedSchedule {
+	schedule := feedSchedule{
+		TTL: time.Duration(ttl) * time.Second,
+	}
+	for _, val := range strings.Split(skipHours, ",") {
+		if hour, err := strconv.Atoi(strings.TrimSpace(val)); err == nil {
+			schedule.SkipHours = append(schedule.SkipHours, hour)
+		}
+	}
+	for _, val := range strings.Split(skipDays, ",") {
+		if day := strings.TrimSpace(val); day != "" {
+			schedule.SkipDays = append(schedule.SkipDays, day)
+		}
+	}
+	return schedule
+}
@@ -0,0 +1,138 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+)
+
+const testScheduledRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel>
+    <title>Scheduled Feed</title>
+    <link>https://example.org/</link>
+    <description>A feed with scheduling hints</description>
+    <ttl>90</ttl>
+    <sy:updatePeriod>hourly</sy:updatePeriod>
+    <sy:updateFrequency>2</sy:updateFrequency>
+    <skipHours><hour>0</hour><hour>1</hour></skipHours>
+    <skipDays><day>Sunday</day></skipDays>
+    <item>
+      <title>First post</title>
+      <link>https://example.org/first</link>
+      <description>Hello World</description>
+    </item>
+  </channel>
+</rss>`
+
+func TestFeedScheduleFromSource(t *testing.T) {
+	src := []byte(testScheduledRSS)
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := feedScheduleFromSource(src, feed)
+	// <ttl> of 90 minutes is longer than sy's every half hour
+	if schedule.TTL != 90*time.Minute {
+		t.Errorf("expected TTL of 90m, got %s", schedule.TTL)
+	}
+	if encodeSkipHours(schedule.SkipHours) != "0,1" {
+		t.Errorf("expected skip hours 0,1, got %v", schedule.SkipHours)
+	}
+	if encodeSkipDays(schedule.SkipDays) != "Sunday" {
+		t.Errorf("expected skip days Sunday, got %v", schedule.SkipDays)
+	}
+	restored := decodeFeedSchedule(int(schedule.TTL.Seconds()), encodeSkipHours(schedule.SkipHours), encodeSkipDays(schedule.SkipDays))
+	if restored.TTL != schedule.TTL || len(restored.SkipHours) != 2 || len(restored.SkipDays) != 1 {
+		t.Errorf("expected schedule to survive encoding, got %+v", restored)
+	}
+}
+
+func TestParseCacheHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set("Cache-Control", "public, max-age=600")
+	header.Set("Retry-After", "120")
+	maxAge, retryAfter := parseCacheHeaders(header, now)
+	if maxAge != 10*time.Minute || retryAfter != 2*time.Minute {
+		t.Errorf("expected 10m and 2m, got %s and %s", maxAge, retryAfter)
+	}
+	header.Set("Retry-After", now.Add(time.Hour).Format(http.TimeFormat))
+	if _, retryAfter = parseCacheHeaders(header, now); retryAfter != time.Hour {
+		t.Errorf("expected Retry-After date to give 1h, got %s", retryAfter)
+	}
+}
+
+func TestNextHarvest(t *testing.T) {
+	// Saturday 23:30 GMT, an hour later falls on a skipped Sunday
+	now := time.Date(2026, 1, 3, 23, 30, 0, 0, time.UTC)
+	schedule := feedSchedule{TTL: time.Hour, SkipDays: []string{"Sunday"}, SkipHours: []int{0}}
+	next := schedule.nextHarvest(now, 0)
+	want := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC).Add(time.Hour)
+	if !next.Equal(want) {
+		t.Errorf("expected %s, got %s", want, next)
+	}
+	// The collection TTL wins when it is the longest wait
+	if next := (feedSchedule{MaxAge: time.Minute}).nextHarvest(now, 2*time.Hour); !next.Equal(now.Add(2 * time.Hour)) {
+		t.Errorf("expected collection TTL to be used, got %s", next)
+	}
+}
+
+func TestHarvestSkipsFeedsNotDue(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testScheduledRSS))
+	}))
+	defer ts.Close()
+
+	dName := t.TempDir()
+	cName := filepath.Join(dName, "scheduled.md")
+	src := fmt.Sprintf("# Scheduled\n\n- [Scheduled](%s/feed.xml)\n", ts.URL)
+	if err := os.WriteFile(cName, []byte(src), 0664); err != nil {
+		t.Fatal(err)
+	}
+	col := &Collection{File: cName, DbName: filepath.Join(dName, "scheduled.db")}
+	if err := setupDatabase(cName, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	for _, opts := range []*HarvestOptions{nil, nil, {Force: true}} {
+		if err := col.Harvest(&out, &out, &AppConfig{}, opts); err != nil {
+			t.Fatalf("Harvest: %s", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected the second harvest to skip the feed, got %d requests", requests)
+	}
+	if !strings.Contains(out.String(), "not due Scheduled") {
+		t.Errorf("expected a not due message, got %s", out.String())
+	}
+}
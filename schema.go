@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -50,6 +51,18 @@ type AppConfig struct {
 	// UserAgent this holds a custom user agent string
 	UserAgent string `json:"userAgent,omitempty" yaml:"userAgent,omitempty"`
 
+	// Concurrency holds the number of feeds harvested at the same time.
+	// A collection can override it. Defaults to 4.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+
+	// HostConcurrency holds the number of feeds harvested at the same time
+	// from a single host. A collection can override it. Defaults to 2.
+	HostConcurrency int `json:"host_concurrency,omitempty" yaml:"host_concurrency,omitempty"`
+
+	// Timeout holds the number of seconds to wait for a feed request when
+	// harvesting. A collection can override it. Defaults to 30.
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
 	// BaseURL for the Antenna instance
 	BaseURL string `json:"base_url,omitempty" yaml:"base_url,omitempty"`
 
@@ -68,6 +81,12 @@ type AppConfig struct {
 	FreqRules   map[string]string // outputPath prefix -> changefreq
 	PriRules    map[string]string // outputPath prefix -> priority
 
+	// manifest, when set, limits generate to the outputs whose inputs
+	// changed since they were last written.
+	manifest *BuildManifest
+
+	// nav caches the generated site navigation, see navEntries
+	nav []*navEntry
 }
 
 // setupDatabase checks to see if anything needs to be setup (or fixed) for AntennaApp to run.
@@ -92,6 +111,105 @@ func setupDatabase(cName string, dbName string) error {
 	return nil
 }
 
+// schemaTables lists the tables added to the collection database after
+// the original schema. upgradeDatabase creates them when missing.
+var schemaTables = []string{
+	SQLCreateFeedHealth,
+	SQLCreateTOFUPins,
+}
+
+// schemaColumns lists the columns added to the collection tables after
+// the original schema. Databases created by older versions of antenna
+// are missing them, upgradeDatabase adds them as needed.
+var schemaColumns = []struct {
+	table  string
+	column string
+	decl   string
+}{
+	{"channels", "etag", "TEXT DEFAULT ''"},
+	{"channels", "last_modified", "TEXT DEFAULT ''"},
+	{"feed_health", "next_harvest", "TEXT DEFAULT ''"},
+	{"feed_health", "ttl", "INTEGER DEFAULT 0"},
+	{"feed_health", "skip_hours", "TEXT DEFAULT ''"},
+	{"feed_health", "skip_days", "TEXT DEFAULT ''"},
+	{"feed_health", "moved_to", "TEXT DEFAULT ''"},
+	{"items", "title_key", "TEXT DEFAULT ''"},
+	{"items", "fullMarkdown", "TEXT DEFAULT ''"},
+	{"items", "sourceOutline", "JSON DEFAULT ''"},
+	{"items", "podcastExt", "JSON DEFAULT ''"},
+	{"channels", "source_accounts", "JSON DEFAULT ''"},
+	{"channels", "source_likes", "TEXT DEFAULT ''"},
+	{"channels", "source_blogroll", "TEXT DEFAULT ''"},
+	{"channels", "discovered_url", "TEXT DEFAULT ''"},
+}
+
+// upgradeDatabase creates the tables listed in schemaTables and adds any
+// missing columns listed in schemaColumns to an existing collection
+// database. Columns are only added to tables that already exist. The
+// harvested items are then saved under their canonical links.
+func upgradeDatabase(db *sql.DB) error {
+	for _, stmt := range schemaTables {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("%s\nstmt: %s", err, stmt)
+		}
+	}
+	known := map[string]map[string]bool{}
+	for _, col := range schemaColumns {
+		columns, ok := known[col.table]
+		if !ok {
+			columns = map[string]bool{}
+			rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", col.table))
+			if err != nil {
+				return err
+			}
+			for rows.Next() {
+				var (
+					cid       int
+					name      string
+					colType   string
+					notNull   int
+					dfltValue sql.NullString
+					pk        int
+				)
+				if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+					rows.Close()
+					return err
+				}
+				columns[name] = true
+			}
+			rows.Close()
+			known[col.table] = columns
+		}
+		if len(columns) == 0 || columns[col.column] {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", col.table, col.column, col.decl)
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("%s\nstmt: %s", err, stmt)
+		}
+		columns[col.column] = true
+	}
+	if len(known["items"]) > 0 {
+		return canonicalizeItemLinks(db)
+	}
+	return nil
+}
+
+// openCollectionDB opens a collection database, upgrading it with
+// upgradeDatabase so databases created by older versions of antenna have
+// the tables and columns the SQL statements expect.
+func openCollectionDB(dbName string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbName)
+	if err != nil {
+		return nil, err
+	}
+	if err := upgradeDatabase(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to upgrade %s, %s", dbName, err)
+	}
+	return db, nil
+}
+
 // AddCollection adds and saves a new collection to AppConfig
 func (cfg *AppConfig) AddCollection(cfgName string, cName string) error {
 	// Default to .md when no extension is given (e.g. "sacbee" -> "sacbee.md")
@@ -271,6 +389,83 @@ type Collection struct {
 	// DbName holds the SQLite3 database filename
 	DbName string `json:"dbName,omitempty" yaml:"dbName,omitempty"`
 
+	// Concurrency overrides the number of feeds harvested at the same time
+	// for this collection.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+
+	// HostConcurrency overrides the number of feeds harvested at the same
+	// time from a single host for this collection.
+	HostConcurrency int `json:"host_concurrency,omitempty" yaml:"host_concurrency,omitempty"`
+
+	// Timeout overrides the number of seconds to wait for a feed request
+	// when harvesting this collection.
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// NearDuplicates collapses items with the same title and similar
+	// content harvested from different feeds into one item listing each
+	// source label.
+	NearDuplicates bool `json:"near_duplicates,omitempty" yaml:"near_duplicates,omitempty"`
+
+	// Retention holds the rules for removing old harvested items, it is
+	// applied after each harvest and by the prune action.
+	Retention *RetentionPolicy `json:"retention,omitempty" yaml:"retention,omitempty"`
+
+	// FullArticle retrieves the web page each harvested item links to and
+	// saves its article as Markdown, for feeds that only publish a summary.
+	FullArticle bool `json:"full_article,omitempty" yaml:"full_article,omitempty"`
+
+	// FullArticleFeeds lists the feeds, by URL or label, to retrieve full
+	// articles for when FullArticle isn't set for the whole collection.
+	FullArticleFeeds []string `json:"full_article_feeds,omitempty" yaml:"full_article_feeds,omitempty"`
+
+	// SourceAccounts lists the publisher's accounts written to the RSS
+	// feed as source:account elements.
+	SourceAccounts []SourceAccount `json:"source_accounts,omitempty" yaml:"source_accounts,omitempty"`
+
+	// SourceLikes holds the likes server written to the RSS feed as a
+	// source:likes element.
+	SourceLikes string `json:"source_likes,omitempty" yaml:"source_likes,omitempty"`
+
+	// SourceBlogroll holds the URL of the publisher's blogroll OPML
+	// written to the RSS feed as a source:blogroll element.
+	SourceBlogroll string `json:"source_blogroll,omitempty" yaml:"source_blogroll,omitempty"`
+
+	// Podcast marks the collection as a podcast, its RSS feed includes the
+	// iTunes and Podcasting 2.0 elements of each post's episode.
+	Podcast bool `json:"podcast,omitempty" yaml:"podcast,omitempty"`
+
+	// PodcastAuthor is written as the podcast's itunes:author
+	PodcastAuthor string `json:"podcast_author,omitempty" yaml:"podcast_author,omitempty"`
+
+	// PodcastImage holds the URL of the podcast's artwork
+	PodcastImage string `json:"podcast_image,omitempty" yaml:"podcast_image,omitempty"`
+
+	// PodcastCategories lists the podcast's iTunes categories, a
+	// subcategory is written as "Parent/Child".
+	PodcastCategories []string `json:"podcast_categories,omitempty" yaml:"podcast_categories,omitempty"`
+
+	// PodcastExplicit marks the podcast as containing explicit content
+	PodcastExplicit bool `json:"podcast_explicit,omitempty" yaml:"podcast_explicit,omitempty"`
+
+	// TagPages writes a page and RSS feed for each category of the
+	// collection's items, NAME/tags/TAG.html and NAME/tags/TAG.xml, and a
+	// tag index, NAME/tags/index.html, NAME being the collection's base name.
+	TagPages bool `json:"tag_pages,omitempty" yaml:"tag_pages,omitempty"`
+
+	// AuthorPages writes a page for each author of the collection's items,
+	// NAME/authors/AUTHOR.html.
+	AuthorPages bool `json:"author_pages,omitempty" yaml:"author_pages,omitempty"`
+
+	// ArchivePages writes a page for each year and month of the
+	// collection's items, NAME-archive-YYYY.html and
+	// NAME-archive-YYYY-MM.html, and an archive index, NAME-archive.html.
+	ArchivePages bool `json:"archive_pages,omitempty" yaml:"archive_pages,omitempty"`
+
+	// Formats lists the syndication formats Generate writes for the
+	// collection, "rss", "atom" and "json". RSS and JSON Feed are written
+	// when it is empty.
+	Formats []string `json:"formats,omitempty" yaml:"formats,omitempty"`
+
 	// Mode controls the HTML rendering strategy for this collection.
 	// "aggregate" (default) renders feed-item cards from the items table.
 	// "page-index" renders a simple link list from the pages table.
@@ -282,6 +477,31 @@ func (col *Collection) Name() string {
 	return strings.TrimSuffix(col.File, ".md")
 }
 
+// DefaultFormats are the syndication formats written when a collection
+// doesn't list its own.
+var DefaultFormats = []string{"rss", "json"}
+
+// SyndicationFormats returns the syndication formats to write for the
+// collection in lower case, an error is returned for an unknown format.
+func (col *Collection) SyndicationFormats() ([]string, error) {
+	if len(col.Formats) == 0 {
+		return DefaultFormats, nil
+	}
+	formats := []string{}
+	for _, format := range col.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		switch format {
+		case "rss", "atom", "json":
+			if !slices.Contains(formats, format) {
+				formats = append(formats, format)
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown format %q, expected rss, atom or json", col.File, format)
+		}
+	}
+	return formats, nil
+}
+
 // Link represents a Markdown link with Label, URL, and optional Description.
 type Link struct {
 	// Label holds the text label that will be used when displaying the feed
@@ -402,7 +622,7 @@ func (cfg *AppConfig) Posts(cName string, options []string) error {
 		return fmt.Errorf("%s, %s", cName, err)
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
@@ -540,7 +760,7 @@ func (cfg *AppConfig) PublishPost(cName string, fName string) error {
 		}
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
@@ -593,7 +813,17 @@ func (cfg *AppConfig) Post(cName string, fName string) error {
 		doc.Text = IncludeCodeBlock(doc.Text)
 	}
 
-	// Convert our document text to HTML
+	gen, err := NewGenerator(path.Base(os.Args[0]), cfg.BaseURL)
+	if err != nil {
+		return err
+	}
+	if err := gen.LoadConfig(collection.Generator); err != nil {
+		return err
+	}
+
+	// Convert our document text to HTML, running the generator's cm_filters
+	doc.UseFilters(gen.CMarkFilters)
+	gen.useHeadings(doc)
 	innerHTML, err := doc.ToUnsafeHTML()
 	if err != nil {
 		return err
@@ -643,23 +873,30 @@ func (cfg *AppConfig) Post(cName string, fName string) error {
 		}
 		// Write out an HTML page to the postPath, normalizing source extension to .html
 		htmlName := normalizeToHTMLExt(filepath.Join(cfg.Htdocs, postPath))
-		gen, err := NewGenerator(path.Base(os.Args[0]), cfg.BaseURL)
-		if err != nil {
-			return err
-		}
-		if err := gen.LoadConfig(collection.Generator); err != nil {
-			return err
-		}
+		gen.collection = collection
+		gen.useSite(cfg)
 		if err := gen.WriteHtmlPage(htmlName, link, postPath, pubDate, innerHTML, doc.FrontMatter); err != nil {
 			return err
 		}
 	}
-	// FIXME: Need to handle getting enclosures and publishing them to posts tree
-	// NOTE: Insert/update item in collection
-	// FIXME: need to populate the enclosures
-	enclosures := []*Enclosure{}
-	// FIXME: need to populate the Dublin Core extension
-	dcExt := &ext.DublinCoreExtension{}
+	// NOTE: Insert/update item in collection, local media files named
+	// in the front matter are copied into htdocs
+	enclosures, err := cfg.postEnclosures(doc, postPath)
+	if err != nil {
+		return fmt.Errorf("%s, %s", fName, err)
+	}
+	episode, err := cfg.postPodcastEpisode(doc, postPath)
+	if err != nil {
+		return fmt.Errorf("%s, %s", fName, err)
+	}
+	podcastSrc := []byte{}
+	if episode != nil {
+		podcastSrc, err = json.Marshal(episode)
+		if err != nil {
+			return fmt.Errorf("failed to marshal podcast episode, %s", err)
+		}
+	}
+	dcExt := frontMatterDublinCore(doc, authors)
 	updated := time.Now().Format(time.RFC3339)
 	if dateModified != "" {
 		d, err := time.Parse("2006-01-02", dateModified)
@@ -685,12 +922,18 @@ func (cfg *AppConfig) Post(cName string, fName string) error {
 		}
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
-	return updateItem(db, link, title, description, fmt.Sprintf("%s", authorsSrc), enclosures, guid, pubDate, dcExt, channel, status, updated, label, postPath, sourceMarkdown, string(categoriesSrc))
+	if err := updateItem(db, link, title, description, fmt.Sprintf("%s", authorsSrc), enclosures, guid, pubDate, dcExt, channel, status, updated, label, postPath, sourceMarkdown, string(categoriesSrc)); err != nil {
+		return err
+	}
+	if _, err := db.Exec(SQLUpdateItemPodcast, string(podcastSrc), link); err != nil {
+		return fmt.Errorf("%s\nstmt: %s", err, SQLUpdateItemPodcast)
+	}
+	return nil
 }
 
 // removePost removes an item from the items table using postPath
@@ -709,7 +952,7 @@ func (cfg *AppConfig) Unpost(cName string, fName string) error {
 		return err
 	}
 	dsn := collection.DbName
-	db, err := sql.Open("sqlite", dsn)
+	db, err := openCollectionDB(dsn)
 	if err != nil {
 		return err
 	}
@@ -780,7 +1023,7 @@ func (cfg *AppConfig) Items(out io.Writer, cName string) error {
 	if err != nil {
 		return fmt.Errorf("%s, %s", cName, err)
 	}
-	db, err := sql.Open("sqlite", collection.DbName)
+	db, err := openCollectionDB(collection.DbName)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,278 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"golang.org/x/net/html/charset"
+)
+
+// sourceNamespace is the XML namespace of the source elements, see
+// <http://source.scripting.com>. Feeds written by older versions of
+// antenna declared it with https, both are accepted when harvesting.
+const sourceNamespace = "http://source.scripting.com/"
+
+// SourceAccount is a source:account element, the account of the feed's
+// publisher on a service such as "twitter" or "github".
+type SourceAccount struct {
+	// Service names the service the account is on
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	// Name holds the account name
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// isSourceNamespace reports if space is the source namespace
+func isSourceNamespace(space string) bool {
+	space = strings.TrimPrefix(strings.TrimPrefix(space, "http://"), "https://")
+	return strings.TrimSuffix(space, "/") == "source.scripting.com"
+}
+
+// parseSourceNamespace reads the source namespace elements from the RSS
+// or Atom document in src and saves them in the "source" extensions of
+// feed and its items, whatever prefix the document used for the
+// namespace. gofeed trims the text of extension elements, here the text of
+// source:markdown is kept as published so the Markdown round-trips byte
+// for byte. The elements gofeed already parsed are kept if src can't be
+// read.
+func parseSourceNamespace(src []byte, feed *gofeed.Feed) {
+	switch gofeed.DetectFeedType(bytes.NewReader(src)) {
+	case gofeed.FeedTypeRSS, gofeed.FeedTypeAtom:
+	default:
+		return
+	}
+	channel, items, err := readSourceElements(src)
+	if err != nil {
+		return
+	}
+	if len(channel) > 0 {
+		if feed.Extensions == nil {
+			feed.Extensions = ext.Extensions{}
+		}
+		feed.Extensions["source"] = channel
+	}
+	for i, item := range feed.Items {
+		if i >= len(items) || len(items[i]) == 0 {
+			continue
+		}
+		if item.Extensions == nil {
+			item.Extensions = ext.Extensions{}
+		}
+		item.Extensions["source"] = items[i]
+	}
+}
+
+// readSourceElements returns the source elements of the channel and of
+// each item, or entry, in document order.
+func readSourceElements(src []byte) (map[string][]ext.Extension, []map[string][]ext.Extension, error) {
+	d := xml.NewDecoder(bytes.NewReader(src))
+	d.Strict = false
+	d.CharsetReader = charset.NewReaderLabel
+	channel := map[string][]ext.Extension{}
+	items := []map[string][]ext.Extension{}
+	var current map[string][]ext.Extension
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return channel, items, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			if isSourceNamespace(el.Name.Space) {
+				e, err := readSourceElement(d, el)
+				if err != nil {
+					return nil, nil, err
+				}
+				target := channel
+				if current != nil {
+					target = current
+				}
+				target[e.Name] = append(target[e.Name], e)
+				continue
+			}
+			if el.Name.Local == "item" || el.Name.Local == "entry" {
+				current = map[string][]ext.Extension{}
+				items = append(items, current)
+			}
+		case xml.EndElement:
+			if el.Name.Local == "item" || el.Name.Local == "entry" {
+				current = nil
+			}
+		}
+	}
+}
+
+// readSourceElement reads the element started by start as a gofeed
+// extension. Text is trimmed like gofeed does except for source:markdown.
+func readSourceElement(d *xml.Decoder, start xml.StartElement) (ext.Extension, error) {
+	e := ext.Extension{
+		Name:     start.Name.Local,
+		Attrs:    map[string]string{},
+		Children: map[string][]ext.Extension{},
+	}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		e.Attrs[attr.Name.Local] = attr.Value
+	}
+	text := new(strings.Builder)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return e, err
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			child, err := readSourceElement(d, el)
+			if err != nil {
+				return e, err
+			}
+			e.Children[child.Name] = append(e.Children[child.Name], child)
+		case xml.CharData:
+			text.Write(el)
+		case xml.EndElement:
+			e.Value = text.String()
+			if e.Name != "markdown" {
+				e.Value = strings.TrimSpace(e.Value)
+			}
+			return e, nil
+		}
+	}
+}
+
+// sourceValue returns the value of the first source element named name
+// in extensions.
+func sourceValue(extensions ext.Extensions, name string) (ext.Extension, bool) {
+	if source, ok := extensions["source"]; ok {
+		if elements := source[name]; len(elements) > 0 {
+			return elements[0], true
+		}
+	}
+	return ext.Extension{}, false
+}
+
+// sourceChannel returns the source:account, source:likes and
+// source:blogroll values of a feed for saving in the channels table.
+// Accounts are encoded as JSON.
+func sourceChannel(feed *gofeed.Feed) (string, string, string, error) {
+	var accounts, likes, blogroll string
+	if source, ok := feed.Extensions["source"]; ok && len(source["account"]) > 0 {
+		list := []SourceAccount{}
+		for _, account := range source["account"] {
+			list = append(list, SourceAccount{Service: account.Attrs["service"], Name: account.Value})
+		}
+		src, err := json.Marshal(list)
+		if err != nil {
+			return "", "", "", err
+		}
+		accounts = string(src)
+	}
+	if e, ok := sourceValue(feed.Extensions, "likes"); ok {
+		likes = e.Attrs["server"]
+	}
+	if e, ok := sourceValue(feed.Extensions, "blogroll"); ok {
+		blogroll = e.Value
+	}
+	return accounts, likes, blogroll, nil
+}
+
+// sourceOutline returns the source:outline elements of an item encoded
+// as JSON for saving in the items table.
+func sourceOutline(item *gofeed.Item) (string, error) {
+	source, ok := item.Extensions["source"]
+	if !ok || len(source["outline"]) == 0 {
+		return "", nil
+	}
+	src, err := json.Marshal(source["outline"])
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// sourceMarkdownString escapes Markdown for a source:markdown element.
+// Only the characters XML requires are escaped, along with carriage
+// returns which XML parsers would otherwise normalize away, so the
+// Markdown reads naturally in the feed and parses back unchanged.
+func sourceMarkdownString(markdown string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace(markdown)
+}
+
+// writeSourceOutlines writes the outlines saved as JSON by sourceOutline
+// as source:outline elements, indented by indent spaces.
+func writeSourceOutlines(out io.Writer, src string, indent int) error {
+	outlines := []ext.Extension{}
+	if err := json.Unmarshal([]byte(src), &outlines); err != nil {
+		return err
+	}
+	for _, outline := range outlines {
+		writeSourceOutline(out, outline, indent)
+	}
+	return nil
+}
+
+// writeSourceOutline writes an outline and its child outlines. Attributes
+// are written in name order so the output is stable.
+func writeSourceOutline(out io.Writer, outline ext.Extension, indent int) {
+	keys := make([]string, 0, len(outline.Attrs))
+	for key := range outline.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := new(strings.Builder)
+	for _, key := range keys {
+		fmt.Fprintf(attrs, ` %s="%s"`, key, toXMLString(outline.Attrs[key]))
+	}
+	children := outline.Children["outline"]
+	if len(children) == 0 {
+		fmt.Fprintf(out, "%s<source:outline%s />\n", strings.Repeat(" ", indent), attrs)
+		return
+	}
+	fmt.Fprintf(out, "%s<source:outline%s>\n", strings.Repeat(" ", indent), attrs)
+	for _, child := range children {
+		writeSourceOutline(out, child, indent+2)
+	}
+	fmt.Fprintf(out, "%s</source:outline>\n", strings.Repeat(" ", indent))
+}
+
+// writeSourceChannel writes the collection's source:account,
+// source:likes and source:blogroll elements.
+func writeSourceChannel(out io.Writer, collection *Collection) {
+	for _, account := range collection.SourceAccounts {
+		fmt.Fprintf(out, "    <source:account service=\"%s\">%s</source:account>\n",
+			toXMLString(account.Service), toXMLString(account.Name))
+	}
+	if collection.SourceLikes != "" {
+		fmt.Fprintf(out, "    <source:likes server=\"%s\" />\n", toXMLString(collection.SourceLikes))
+	}
+	if collection.SourceBlogroll != "" {
+		fmt.Fprintf(out, "    <source:blogroll>%s</source:blogroll>\n", toXMLString(collection.SourceBlogroll))
+	}
+}
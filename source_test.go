@@ -0,0 +1,196 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// 3rd Party pacakges
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+func TestParseSourceNamespace(t *testing.T) {
+	// A feed using its own prefix for the namespace, the Markdown is
+	// indented and ends with a new line
+	src := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:src="http://source.scripting.com/">
+  <channel>
+    <title>Scripting News</title>
+    <link>http://scripting.com/</link>
+    <description>A test feed</description>
+    <src:account service="github">scripting</src:account>
+    <src:account service="mastodon">dave@mastodon.example</src:account>
+    <src:likes server="http://likes.example.com/" />
+    <src:blogroll>http://example.com/blogroll.opml</src:blogroll>
+    <item>
+      <title>First</title>
+      <link>http://scripting.com/2025/09/01.html</link>
+      <description>First &lt;b&gt;post&lt;/b&gt;</description>
+      <src:markdown>    indented code
+</src:markdown>
+      <src:outline text="First" created="Mon, 01 Sep 2025 12:00:00 GMT">
+        <src:outline text="A point" />
+      </src:outline>
+    </item>
+    <item>
+      <title>Second</title>
+      <link>http://scripting.com/2025/09/02.html</link>
+      <description>Second post</description>
+    </item>
+  </channel>
+</rss>`)
+	feed, _, err := parseFeedSource("http://scripting.com/rss.xml", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, likes, blogroll, err := sourceChannel(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts != `[{"service":"github","name":"scripting"},{"service":"mastodon","name":"dave@mastodon.example"}]` {
+		t.Errorf("unexpected source:account values %s", accounts)
+	}
+	if likes != "http://likes.example.com/" || blogroll != "http://example.com/blogroll.opml" {
+		t.Errorf("unexpected source:likes %q or source:blogroll %q", likes, blogroll)
+	}
+	if e, ok := sourceValue(feed.Items[0].Extensions, "markdown"); !ok || e.Value != "    indented code\n" {
+		t.Errorf("expected source:markdown as published, got %q", e.Value)
+	}
+	outline, err := sourceOutline(feed.Items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := writeSourceOutlines(buf, outline, 0); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<source:outline created="Mon, 01 Sep 2025 12:00:00 GMT" text="First">
+  <source:outline text="A point" />
+</source:outline>
+`
+	if buf.String() != expected {
+		t.Errorf("expected outline\n%s\ngot\n%s", expected, buf.String())
+	}
+	if _, ok := sourceValue(feed.Items[1].Extensions, "markdown"); ok {
+		t.Errorf("expected no source:markdown for the second item")
+	}
+}
+
+func TestSourceRoundTrip(t *testing.T) {
+	markdown := "  Leading spaces, a CRLF\r\nand a tab\t.\n\n" +
+		"# Markup & <entities> such as &amp; stay as written\n\n" +
+		"```go\nif a < b && c > d {\n\treturn \"]]>\"\n}\n```\n\n" +
+		"Quotes 'single' and \"double\", accents é and emoji ✓\n\n\n"
+	outline := []ext.Extension{{
+		Name:  "outline",
+		Attrs: map[string]string{"text": "Notes & <thoughts>", "type": "outline"},
+		Children: map[string][]ext.Extension{
+			"outline": {{Name: "outline", Attrs: map[string]string{"text": "One"}, Children: map[string][]ext.Extension{}}},
+		},
+	}}
+
+	// The first instance publishes an item with Markdown and an outline
+	dName := t.TempDir()
+	publisher := &Collection{
+		Title:          "Publisher",
+		Description:    "The first antenna instance",
+		File:           filepath.Join(dName, "publisher.md"),
+		DbName:         filepath.Join(dName, "publisher.db"),
+		SourceAccounts: []SourceAccount{{Service: "github", Name: "antenna"}},
+		SourceLikes:    "http://likes.example.com/",
+		SourceBlogroll: "http://example.com/blogroll.opml",
+	}
+	if err := setupDatabase(publisher.File, publisher.DbName); err != nil {
+		t.Fatal(err)
+	}
+	pubDB, err := sql.Open("sqlite", publisher.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pubDB.Close()
+	item := &gofeed.Item{
+		Title:       "Round trip",
+		Link:        "https://example.com/round-trip",
+		GUID:        "https://example.com/round-trip",
+		Description: "A round trip",
+		Published:   "2025-09-01",
+		Extensions: ext.Extensions{"source": {
+			"markdown": {{Name: "markdown", Value: markdown}},
+			"outline":  outline,
+		}},
+	}
+	if err := saveItem(pubDB, "Publisher", "https://example.com/", "published", item); err != nil {
+		t.Fatal(err)
+	}
+	feed := new(bytes.Buffer)
+	gen := &Generator{eout: io.Discard}
+	if err := gen.WriteCustomRSS(feed, pubDB, SQLDisplayItems, "https://example.com/publisher.xml", "antenna", publisher); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(feed.Bytes())
+	}))
+	defer ts.Close()
+
+	// The second instance harvests the first one's feed
+	cName := filepath.Join(dName, "reader.md")
+	if err := os.WriteFile(cName, []byte(fmt.Sprintf("# Reader\n\n- [Publisher](%s/publisher.xml)\n", ts.URL)), 0664); err != nil {
+		t.Fatal(err)
+	}
+	reader := &Collection{File: cName, DbName: filepath.Join(dName, "reader.db")}
+	if err := setupDatabase(cName, reader.DbName); err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.Harvest(io.Discard, io.Discard, &AppConfig{}, &HarvestOptions{Force: true}); err != nil {
+		t.Fatalf("Harvest: %s", err)
+	}
+	db, err := sql.Open("sqlite", reader.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var harvestedMarkdown, harvestedOutline, savedOutline string
+	if err := db.QueryRow(`SELECT sourceMarkdown, sourceOutline FROM items WHERE link = ?`, item.Link).Scan(&harvestedMarkdown, &harvestedOutline); err != nil {
+		t.Fatal(err)
+	}
+	if harvestedMarkdown != markdown {
+		t.Errorf("expected the Markdown byte for byte\n%q\ngot\n%q\nfeed:\n%s", markdown, harvestedMarkdown, feed.String())
+	}
+	if err := pubDB.QueryRow(`SELECT sourceOutline FROM items WHERE link = ?`, item.Link).Scan(&savedOutline); err != nil {
+		t.Fatal(err)
+	}
+	if harvestedOutline != savedOutline {
+		t.Errorf("expected the outline %s, got %s", savedOutline, harvestedOutline)
+	}
+	var accounts, likes, blogroll string
+	if err := db.QueryRow(`SELECT source_accounts, source_likes, source_blogroll FROM channels`).Scan(&accounts, &likes, &blogroll); err != nil {
+		t.Fatal(err)
+	}
+	if accounts != `[{"service":"github","name":"antenna"}]` || likes != publisher.SourceLikes || blogroll != publisher.SourceBlogroll {
+		t.Errorf("unexpected channel source elements %s, %q, %q", accounts, likes, blogroll)
+	}
+}
@@ -39,7 +39,13 @@ CREATE TABLE IF NOT EXISTS channels (
 	generator TEXT,
 	categories JSON,
 	feed_type TEXT,
-	feed_version TEXT
+	feed_version TEXT,
+	etag TEXT DEFAULT '',
+	last_modified TEXT DEFAULT '',
+	source_accounts JSON DEFAULT '',
+	source_likes TEXT DEFAULT '',
+	source_blogroll TEXT DEFAULT '',
+	discovered_url TEXT DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS items (
@@ -57,7 +63,11 @@ CREATE TABLE IF NOT EXISTS items (
 	status TEXT DEFAULT '',
 	label TEXT DEFAULT '',
 	updated DATETIME,
-	categories JSON DEFAULT ''
+	categories JSON DEFAULT '',
+	title_key TEXT DEFAULT '',
+	fullMarkdown TEXT DEFAULT '',
+	sourceOutline JSON DEFAULT '',
+	podcastExt JSON DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS pages (
@@ -65,8 +75,101 @@ CREATE TABLE IF NOT EXISTS pages (
   outputPath TEXT DEFAULT '',
   updated DATETIME
 );
+` + SQLCreateFeedHealth + SQLCreateTOFUPins
+
+	// SQLCreateFeedHealth creates the table used to track the result of
+	// harvesting each feed in a collection.
+	SQLCreateFeedHealth = `
+CREATE TABLE IF NOT EXISTS feed_health (
+	link PRIMARY KEY,
+	label TEXT DEFAULT '',
+	last_attempt DATETIME,
+	last_success DATETIME,
+	status_code INTEGER DEFAULT 0,
+	error TEXT DEFAULT '',
+	failures INTEGER DEFAULT 0,
+	item_count INTEGER DEFAULT 0,
+	next_harvest TEXT DEFAULT '',
+	ttl INTEGER DEFAULT 0,
+	skip_hours TEXT DEFAULT '',
+	skip_days TEXT DEFAULT '',
+	moved_to TEXT DEFAULT ''
+);
 `
 
+	// SQLCreateTOFUPins creates the table holding the certificates pinned
+	// on first use for the Gemini hosts in a collection.
+	SQLCreateTOFUPins = `
+CREATE TABLE IF NOT EXISTS tofu_pins (
+	host PRIMARY KEY,
+	fingerprint TEXT DEFAULT '',
+	not_after TEXT DEFAULT '',
+	first_seen TEXT DEFAULT ''
+);
+`
+
+	// SQLTOFUPins returns the pinned certificates of a collection
+	SQLTOFUPins = `SELECT host, ifnull(fingerprint, ''), ifnull(not_after, ''), ifnull(first_seen, '')
+FROM tofu_pins;`
+
+	// SQLUpdateTOFUPin saves the pinned certificate of a host
+	SQLUpdateTOFUPin = `REPLACE INTO tofu_pins (host, fingerprint, not_after, first_seen)
+VALUES (?, ?, ?, ?);`
+
+	// SQLRecordFeedSuccess records a successful harvest of a feed, resetting
+	// the consecutive failure count.
+	SQLRecordFeedSuccess = `INSERT INTO feed_health (
+	link, label, last_attempt, last_success, status_code, error, failures, item_count
+) VALUES (
+	?1, ?2, ?3, ?3, ?4, '', 0, ?5
+) ON CONFLICT (link) DO
+  UPDATE SET
+	label = ?2, last_attempt = ?3, last_success = ?3, status_code = ?4,
+	error = '', failures = 0, item_count = ?5;`
+
+	// SQLRecordFeedFailure records a failed harvest of a feed, incrementing
+	// the consecutive failure count.
+	SQLRecordFeedFailure = `INSERT INTO feed_health (
+	link, label, last_attempt, status_code, error, failures, item_count
+) VALUES (
+	?1, ?2, ?3, ?4, ?5, 1, 0
+) ON CONFLICT (link) DO
+  UPDATE SET
+	label = ?2, last_attempt = ?3, status_code = ?4, error = ?5,
+	failures = failures + 1, item_count = 0;`
+
+	// SQLFeedSchedule returns the next harvest time of a feed along with
+	// the scheduling hints saved from the feed.
+	SQLFeedSchedule = `SELECT ifnull(next_harvest, ''), ifnull(ttl, 0),
+  ifnull(skip_hours, ''), ifnull(skip_days, '')
+FROM feed_health
+WHERE link = ?;`
+
+	// SQLUpdateFeedSchedule saves the next harvest time of a feed along with
+	// the scheduling hints found in the feed.
+	SQLUpdateFeedSchedule = `UPDATE feed_health
+SET next_harvest = ?, ttl = ?, skip_hours = ?, skip_days = ?
+WHERE link = ?;`
+
+	// SQLUpdateFeedMovedTo saves the URL a feed has permanently moved to,
+	// an empty string clears it.
+	SQLUpdateFeedMovedTo = `UPDATE feed_health SET moved_to = ? WHERE link = ?;`
+
+	// SQLRenameChannel, SQLRenameItemChannel and SQLRenameFeedHealth move
+	// a feed's saved data from its old URL (?2) to its new one (?1).
+	SQLRenameChannel = `UPDATE OR REPLACE channels SET link = ?1 WHERE link = ?2;`
+
+	SQLRenameItemChannel = `UPDATE items SET channel = ?1 WHERE channel = ?2;`
+
+	SQLRenameFeedHealth = `UPDATE OR REPLACE feed_health SET link = ?1, moved_to = '' WHERE link = ?2;`
+
+	// SQLFeedHealth returns the harvest health of a feed
+	SQLFeedHealth = `SELECT ifnull(last_attempt, ''), ifnull(last_success, ''),
+  ifnull(status_code, 0), ifnull(error, ''), ifnull(failures, 0), ifnull(item_count, 0),
+  ifnull(moved_to, '')
+FROM feed_health
+WHERE link = ?;`
+
 	// SQLResetChannels clear the channels table
 	SQLResetChannels = `DELETE FROM channels;`
 
@@ -82,6 +185,26 @@ categories, feed_type, feed_version
 ?, ?, ?, ?,
 ?, ?, ?
 );`
+	// SQLUpdateChannelSource saves the source namespace elements of a
+	// channel, see <http://source.scripting.com>.
+	SQLUpdateChannelSource = `UPDATE channels
+SET source_accounts = ?, source_likes = ?, source_blogroll = ?
+WHERE link = ?;`
+
+	// SQLChannelCacheHeaders returns the ETag and Last-Modified values saved
+	// from the previous harvest of a feed.
+	SQLChannelCacheHeaders = `SELECT ifnull(etag, ''), ifnull(last_modified, ''),
+  ifnull(discovered_url, '')
+FROM channels
+WHERE link = ?;`
+
+	// SQLUpdateChannelCacheHeaders saves the ETag and Last-Modified values
+	// returned by the server so the next harvest can make a conditional request.
+	// discovered_url is the feed they came from when the link is an HTML page.
+	SQLUpdateChannelCacheHeaders = `UPDATE channels
+SET etag = ?, last_modified = ?, discovered_url = ?
+WHERE link = ?;`
+
 	// Display the channels in the table
 	SQLDisplayChannels = `SELECT 
 link, title, description, feed_link, links,
@@ -92,6 +215,78 @@ FROM channels
 ORDER by title, link
 ;`
 
+	// SQLPruneByAge returns the items, other than posts, published or
+	// updated before the cutoff date. Dates are compared with datetime()
+	// as they are saved in more than one format, an item without a date
+	// SQLite can read is kept.
+	SQLPruneByAge = `SELECT link, ifnull(title, ''), ifnull(label, '')
+FROM items
+WHERE ifnull(postPath, '') = ''
+  AND coalesce(datetime(nullif(pubDate, '')), datetime(updated)) < datetime(?);`
+
+	// SQLPruneByFeedCount returns the items, other than posts, beyond the
+	// newest N of each feed.
+	SQLPruneByFeedCount = `SELECT link, title, label FROM (
+  SELECT link, ifnull(title, '') AS title, ifnull(label, '') AS label,
+    ROW_NUMBER() OVER (
+      PARTITION BY channel
+      ORDER BY coalesce(datetime(nullif(pubDate, '')), datetime(updated)) DESC
+    ) AS rn
+  FROM items
+  WHERE ifnull(postPath, '') = ''
+) WHERE rn > ?;`
+
+	// SQLPruneUnpublished returns the items, other than posts, that are
+	// not published.
+	SQLPruneUnpublished = `SELECT link, ifnull(title, ''), ifnull(label, '')
+FROM items
+WHERE ifnull(postPath, '') = ''
+  AND ifnull(status, '') != 'published';`
+
+	// SQLPruneItem deletes an item, posts are never deleted
+	SQLPruneItem = `DELETE FROM items WHERE link = ? AND ifnull(postPath, '') = '';`
+
+	// SQLHarvestedItemLinks returns the links of the items that weren't
+	// posted
+	SQLHarvestedItemLinks = `SELECT link FROM items WHERE ifnull(postPath, '') = '';`
+
+	// SQLUpdateItemLink saves an item under a new link unless an item is
+	// already saved under it
+	SQLUpdateItemLink = `UPDATE OR IGNORE items SET link = ? WHERE link = ?;`
+
+	// SQLItemLabel returns the source label saved for an item
+	SQLItemLabel = `SELECT ifnull(label, '') FROM items WHERE link = ?;`
+
+	// SQLUpdateItemLabel replaces the source label of an item, used when
+	// near-duplicates are collapsed into one item.
+	SQLUpdateItemLabel = `UPDATE items SET label = ? WHERE link = ?;`
+
+	// SQLUpdateItemTitleKey saves the normalized title used to find
+	// near-duplicates.
+	SQLUpdateItemTitleKey = `UPDATE items SET title_key = ? WHERE link = ?;`
+
+	// SQLNearDuplicates returns the items with the same normalized title
+	// saved under a different link.
+	SQLNearDuplicates = `SELECT link, ifnull(label, ''), ifnull(description, '')
+FROM items
+WHERE title_key = ? AND link != ?;`
+
+	// SQLFullArticleLinks returns the links of the items with a saved
+	// full article.
+	SQLFullArticleLinks = `SELECT link FROM items WHERE ifnull(fullMarkdown, '') != '';`
+
+	// SQLUpdateItemFullMarkdown saves the full article retrieved for an
+	// item as Markdown.
+	SQLUpdateItemFullMarkdown = `UPDATE items SET fullMarkdown = ? WHERE link = ?;`
+
+	// SQLUpdateItemSourceOutline saves the source:outline elements of an
+	// item as JSON.
+	SQLUpdateItemSourceOutline = `UPDATE items SET sourceOutline = ? WHERE link = ?;`
+
+	// SQLUpdateItemPodcast saves the podcast episode details of a post as
+	// JSON.
+	SQLUpdateItemPodcast = `UPDATE items SET podcastExt = ? WHERE link = ?;`
+
 	// Update a feed item in the items table
 	SQLUpdateItem = `INSERT INTO items (
 	link, title, description, authors,
@@ -118,7 +313,10 @@ ORDER by title, link
   enclosures, guid, pubDate, dcExt,
   channel, status, updated, label,
   postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
-  ifnull(categories, '') as categories
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
 FROM items WHERE (description != '' OR title = '') AND status = 'published'
 ORDER BY pubDate DESC, updated DESC;`
 
@@ -127,7 +325,10 @@ ORDER BY pubDate DESC, updated DESC;`
   enclosures, guid, pubDate, dcExt,
   channel, status, updated, label,
   postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
-  ifnull(categories, '') as categories
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
 FROM items
 WHERE (pubDate IS NOT NULL) AND
    (pubDate != "") AND
@@ -139,7 +340,10 @@ ORDER BY pubDate DESC;`
   enclosures, guid, pubDate, dcExt,
   channel, status, updated, label,
   postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
-  ifnull(categories, '') as categories
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
 FROM items
 WHERE (pubDate IS NOT NULL) AND
    (pubDate != '') AND
@@ -152,7 +356,10 @@ LIMIT ?;`
   enclosures, guid, pubDate, dcExt,
   channel, status, updated, label,
   postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
-  ifnull(categories, '') as categories
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
 FROM items
 WHERE (pubDate IS NOT NULL) AND
    (postPath != '') AND
@@ -160,6 +367,102 @@ WHERE (pubDate IS NOT NULL) AND
    (pubDate <= ?)
 ORDER BY pubDate DESC;`
 
+	// SQLDisplayTagItems returns the items listed by SQLDisplayItems with
+	// a category, compared without case.
+	SQLDisplayTagItems = `SELECT
+  link, title, description, authors,
+  enclosures, guid, pubDate, dcExt,
+  channel, status, updated, label,
+  postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
+FROM items, json_each(CASE WHEN json_valid(items.categories) THEN items.categories ELSE '[]' END) AS tag
+WHERE (description != '' OR title = '') AND status = 'published' AND
+  tag.type = 'text' AND lower(tag.value) = lower(?)
+GROUP BY link
+ORDER BY pubDate DESC, updated DESC;`
+
+	// SQLDisplayAuthorItems returns the items listed by SQLDisplayItems
+	// with an author's name, compared without case.
+	SQLDisplayAuthorItems = `SELECT
+  link, title, description, authors,
+  enclosures, guid, pubDate, dcExt,
+  channel, status, updated, label,
+  postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
+FROM items, json_each(CASE WHEN json_valid(items.authors) THEN items.authors ELSE '[]' END) AS author
+WHERE (description != '' OR title = '') AND status = 'published' AND
+  author.type = 'object' AND lower(json_extract(author.value, '$.name')) = lower(?)
+GROUP BY link
+ORDER BY pubDate DESC, updated DESC;`
+
+	// SQLTagCounts returns each category of the published items with the
+	// number of items in it.
+	SQLTagCounts = `SELECT tag.value, COUNT(DISTINCT link)
+FROM items, json_each(CASE WHEN json_valid(items.categories) THEN items.categories ELSE '[]' END) AS tag
+WHERE (description != '' OR title = '') AND status = 'published' AND
+  tag.type = 'text' AND trim(tag.value) != ''
+GROUP BY lower(tag.value)
+ORDER BY lower(tag.value);`
+
+	// SQLAuthorCounts returns each author name of the published items with
+	// the number of items by them.
+	SQLAuthorCounts = `SELECT json_extract(author.value, '$.name'), COUNT(DISTINCT link)
+FROM items, json_each(CASE WHEN json_valid(items.authors) THEN items.authors ELSE '[]' END) AS author
+WHERE (description != '' OR title = '') AND status = 'published' AND
+  author.type = 'object' AND trim(ifnull(json_extract(author.value, '$.name'), '')) != ''
+GROUP BY lower(json_extract(author.value, '$.name'))
+ORDER BY lower(json_extract(author.value, '$.name'));`
+
+	// SQLCountDisplayItems returns the number of items listed by
+	// SQLDisplayItems.
+	SQLCountDisplayItems = `SELECT COUNT(*) FROM items
+WHERE (description != '' OR title = '') AND status = 'published';`
+
+	// SQLDisplayItemsPage returns a page of the items listed by
+	// SQLDisplayItems, it takes the page size and offset.
+	SQLDisplayItemsPage = `SELECT
+  link, title, description, authors,
+  enclosures, guid, pubDate, dcExt,
+  channel, status, updated, label,
+  postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
+FROM items WHERE (description != '' OR title = '') AND status = 'published'
+ORDER BY pubDate DESC, updated DESC
+LIMIT ? OFFSET ?;`
+
+	// SQLDisplayDateItems returns the items listed by SQLDisplayItems whose
+	// pubDate starts with a year, "2025", or a year and month, "2025-09".
+	SQLDisplayDateItems = `SELECT
+  link, title, description, authors,
+  enclosures, guid, pubDate, dcExt,
+  channel, status, updated, label,
+  postPath, ifnull(sourceMarkdown, '') as sourceMarkdown,
+  ifnull(categories, '') as categories,
+  ifnull(fullMarkdown, '') as fullMarkdown,
+  ifnull(sourceOutline, '') as sourceOutline,
+  ifnull(podcastExt, '') as podcastExt
+FROM items WHERE (description != '' OR title = '') AND status = 'published' AND
+  substr(pubDate, 1, length(?1)) = ?1
+ORDER BY pubDate DESC, updated DESC;`
+
+	// SQLArchiveMonths returns each year and month, "2025-09", of the
+	// published items with the number of items in it.
+	SQLArchiveMonths = `SELECT substr(pubDate, 1, 7) AS month, COUNT(*)
+FROM items
+WHERE (description != '' OR title = '') AND status = 'published' AND
+  pubDate GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]*'
+GROUP BY month
+ORDER BY month DESC;`
+
 	// SQLListPosts will list all published posts with a postPath by their descending pubDate
 	SQLListPosts = `SELECT link, title, pubDate, postPath
 FROM items
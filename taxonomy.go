@@ -0,0 +1,246 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// taxonomyTerm is a tag or author name with the number of items for it
+type taxonomyTerm struct {
+	Name  string
+	Slug  string
+	Count int
+}
+
+// taxonomySlug returns the file name used for a tag or author's pages.
+// Letters are lower cased, spaces and punctuation become dashes, and
+// characters like "+" and "#" are kept so "C" and "C++" stay apart.
+func taxonomySlug(name string) string {
+	slug := new(strings.Builder)
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#':
+			if dash && slug.Len() > 0 {
+				slug.WriteRune('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+// taxonomyTerms returns the terms listed by one of SQLTagCounts or
+// SQLAuthorCounts. Terms whose slug is taken by an earlier term are
+// reported to eout and skipped.
+func (gen *Generator) taxonomyTerms(db *sql.DB, stmt string) ([]*taxonomyTerm, error) {
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("%s\nstmt: %s", err, stmt)
+	}
+	defer rows.Close()
+	terms := []*taxonomyTerm{}
+	seen := map[string]string{}
+	for rows.Next() {
+		term := &taxonomyTerm{}
+		if err := rows.Scan(&term.Name, &term.Count); err != nil {
+			return nil, err
+		}
+		term.Name = strings.TrimSpace(term.Name)
+		term.Slug = taxonomySlug(term.Name)
+		if term.Slug == "" {
+			continue
+		}
+		if name, ok := seen[term.Slug]; ok {
+			fmt.Fprintf(gen.eout, "warning: %q and %q share the page %s.html, skipping %q\n", name, term.Name, term.Slug, term.Name)
+			continue
+		}
+		seen[term.Slug] = term.Name
+		terms = append(terms, term)
+	}
+	return terms, rows.Err()
+}
+
+// taxonomyGenerator returns a copy of gen for a page in a tags or authors
+// directory. The collection's alternate links are relative to the
+// collection's page so they are replaced by the page's own feed, if any.
+func (gen *Generator) taxonomyGenerator(title string, feedHref string) *Generator {
+	page := *gen
+	page.Title = title
+	page.Link = []map[string]string{}
+	for _, link := range gen.Link {
+		if link["rel"] != "alternate" {
+			page.Link = append(page.Link, link)
+		}
+	}
+	if feedHref != "" {
+		page.Link = append(page.Link, map[string]string{
+			"rel":  "alternate",
+			"type": "application/rss+xml",
+			"href": feedHref,
+		})
+	}
+	return &page
+}
+
+// writeTaxonomyIndex writes a page listing each term with its item count
+func (gen *Generator) writeTaxonomyIndex(out io.Writer, heading string, terms []*taxonomyTerm) error {
+	content := new(bytes.Buffer)
+	fmt.Fprintln(content, "    <ul>")
+	for _, term := range terms {
+		fmt.Fprintf(content, "      <li><a href=\"%s.html\">%s</a> (%d)</li>\n",
+			url.PathEscape(term.Slug), html.EscapeString(term.Name), term.Count)
+	}
+	fmt.Fprintln(content, "    </ul>")
+	return gen.writeListPage(out, heading, content.String())
+}
+
+// writeFile creates fName and writes to it with fn
+func writeFile(fName string, fn func(out io.Writer) error) error {
+	out, err := os.Create(fName)
+	if err != nil {
+		return err
+	}
+	if err := fn(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// taxonomyDir returns the directory of a collection's tags or authors
+// pages, NAME/tags or NAME/authors in htdocs, so collections don't write
+// over each other's pages.
+func taxonomyDir(cfg *AppConfig, collection *Collection, kind string) string {
+	bName := filepath.Base(collection.File)
+	return filepath.Join(cfg.Htdocs, strings.TrimSuffix(bName, filepath.Ext(bName)), kind)
+}
+
+// removeStaleTerms removes the pages and feeds in dName of terms no longer
+// found in the collection's items. The index page is kept.
+func removeStaleTerms(dName string, terms []*taxonomyTerm) error {
+	current := map[string]bool{"index": true}
+	for _, term := range terms {
+		current[term.Slug] = true
+	}
+	entries, err := os.ReadDir(dName)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || (ext != ".html" && ext != ".xml") || current[strings.TrimSuffix(name, ext)] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dName, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateTags writes a page and RSS feed for each category of the
+// collection's items, NAME/tags/TAG.html and NAME/tags/TAG.xml, and a tag
+// index, NAME/tags/index.html, in htdocs.
+func (gen *Generator) GenerateTags(db *sql.DB, appName string, cfg *AppConfig, collection *Collection) error {
+	terms, err := gen.taxonomyTerms(db, SQLTagCounts)
+	if err != nil {
+		return err
+	}
+	dName := taxonomyDir(cfg, collection, "tags")
+	// Security: Use 0755 instead of 0777 for directory permissions
+	if err := os.MkdirAll(dName, 0755); err != nil {
+		return err
+	}
+	if err := removeStaleTerms(dName, terms); err != nil {
+		return err
+	}
+	title := collection.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(collection.File), filepath.Ext(collection.File))
+	}
+	for _, term := range terms {
+		page := gen.taxonomyGenerator(fmt.Sprintf("%s: %s", title, term.Name), url.PathEscape(term.Slug)+".xml")
+		page.pagePath = webPath(cfg.Htdocs, filepath.Join(dName, term.Slug+".html"))
+		heading := fmt.Sprintf("Tagged %s", term.Name)
+		if err := writeFile(filepath.Join(dName, term.Slug+".html"), func(out io.Writer) error {
+			return page.WriteCustomHTML(out, db, heading, SQLDisplayTagItems, term.Name)
+		}); err != nil {
+			return err
+		}
+		feed := *collection
+		feed.Title = page.Title
+		feed.Link = ""
+		feedLink := fmt.Sprintf("%s%s/%s.xml", gen.BaseURL, webPath(cfg.Htdocs, dName), url.PathEscape(term.Slug))
+		if err := writeFile(filepath.Join(dName, term.Slug+".xml"), func(out io.Writer) error {
+			return gen.WriteCustomRSS(out, db, SQLDisplayTagItems, feedLink, appName, &feed, term.Name)
+		}); err != nil {
+			return err
+		}
+	}
+	index := gen.taxonomyGenerator(fmt.Sprintf("%s: Tags", title), "")
+	index.pagePath = webPath(cfg.Htdocs, filepath.Join(dName, "index.html"))
+	return writeFile(filepath.Join(dName, "index.html"), func(out io.Writer) error {
+		return index.writeTaxonomyIndex(out, "Tags", terms)
+	})
+}
+
+// GenerateAuthors writes a page for each author of the collection's
+// items, NAME/authors/AUTHOR.html, in htdocs.
+func (gen *Generator) GenerateAuthors(db *sql.DB, cfg *AppConfig, collection *Collection) error {
+	terms, err := gen.taxonomyTerms(db, SQLAuthorCounts)
+	if err != nil {
+		return err
+	}
+	dName := taxonomyDir(cfg, collection, "authors")
+	// Security: Use 0755 instead of 0777 for directory permissions
+	if err := os.MkdirAll(dName, 0755); err != nil {
+		return err
+	}
+	if err := removeStaleTerms(dName, terms); err != nil {
+		return err
+	}
+	title := collection.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(collection.File), filepath.Ext(collection.File))
+	}
+	for _, term := range terms {
+		page := gen.taxonomyGenerator(fmt.Sprintf("%s: %s", title, term.Name), "")
+		page.pagePath = webPath(cfg.Htdocs, filepath.Join(dName, term.Slug+".html"))
+		heading := fmt.Sprintf("By %s", term.Name)
+		if err := writeFile(filepath.Join(dName, term.Slug+".html"), func(out io.Writer) error {
+			return page.WriteCustomHTML(out, db, heading, SQLDisplayAuthorItems, term.Name)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
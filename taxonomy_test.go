@@ -0,0 +1,188 @@
+/*
+antennaApp is a package for creating and curating blog, link blogs and social websites
+Copyright (C) 2025 R. S. Doiel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+*/
+package antennaApp
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// 3rd Party Packages
+	"github.com/mmcdole/gofeed"
+)
+
+func TestTaxonomySlug(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Go":               "go",
+		"  Static Sites  ": "static-sites",
+		"C++":              "c++",
+		"C#":               "c#",
+		"R. S. Doiel":      "r-s-doiel",
+		"café/Ünïcode":     "café-ünïcode",
+		"../../etc/passwd": "etc-passwd",
+		"!!!":              "",
+	} {
+		if got := taxonomySlug(name); got != expected {
+			t.Errorf("taxonomySlug(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestGenerateTaxonomies(t *testing.T) {
+	dName := t.TempDir()
+	col := &Collection{
+		Title:       "Blog",
+		Description: "A test blog",
+		File:        filepath.Join(dName, "blog.md"),
+		DbName:      filepath.Join(dName, "blog.db"),
+		TagPages:    true,
+		AuthorPages: true,
+	}
+	if err := setupDatabase(col.File, col.DbName); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", col.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, item := range []struct {
+		link, title, authors, categories, status string
+	}{
+		{"https://example.com/one", "One", `[{"name":"Jane Doe"}]`, `["Go","Static Sites"]`, "published"},
+		{"https://example.com/two", "Two", `[{"name":"John Smith"},{"name":"jane doe"}]`, `["go"]`, "published"},
+		{"https://example.com/three", "Three", `null`, `["Go"]`, "draft"},
+		{"https://example.com/four", "Four", ``, ``, "published"},
+	} {
+		if _, err := db.Exec(`INSERT INTO items (link, title, description, authors, categories, guid, pubDate, status,
+			enclosures, dcExt, channel, label, updated, postPath)
+			VALUES (?, ?, ?, ?, ?, ?, '2025-09-01', ?, '', '', '', '', '', '')`,
+			item.link, item.title, item.title+" description", item.authors, item.categories, item.link, item.status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &AppConfig{Htdocs: filepath.Join(dName, "htdocs")}
+	gen, _ := NewGenerator("antenna", "https://example.com")
+	gen.eout = io.Discard
+	gen.Header = "<h1>Blog</h1>"
+	gen.Link = []map[string]string{
+		{"rel": "stylesheet", "href": "/css/site.css"},
+		{"rel": "alternate", "type": "application/feed+json", "href": "blog.json"},
+	}
+	if err := gen.GenerateTags(db, "antenna", cfg, col); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.GenerateAuthors(db, cfg, col); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tags are grouped without case, drafts are left out
+	page, err := os.ReadFile(filepath.Join(cfg.Htdocs, "blog", "tags", "go.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "https://example.com/one") || !strings.Contains(string(page), "https://example.com/two") ||
+		strings.Contains(string(page), "https://example.com/three") {
+		t.Errorf("unexpected items in the go tag page\n%s", page)
+	}
+	if !strings.Contains(string(page), `href="go.xml"`) || strings.Contains(string(page), "blog.json") ||
+		!strings.Contains(string(page), "/css/site.css") {
+		t.Errorf("expected the page to link its own feed and keep the stylesheet\n%s", page)
+	}
+	src, err := os.ReadFile(filepath.Join(cfg.Htdocs, "blog", "tags", "static-sites.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Blog: Static Sites" || len(feed.Items) != 1 || feed.Items[0].Link != "https://example.com/one" {
+		t.Errorf("unexpected tag feed %q with %d items\n%s", feed.Title, len(feed.Items), src)
+	}
+	index, err := os.ReadFile(filepath.Join(cfg.Htdocs, "blog", "tags", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<a href="go.html">Go</a> (2)`) ||
+		!strings.Contains(string(index), `<a href="static-sites.html">Static Sites</a> (1)`) {
+		t.Errorf("unexpected tag index\n%s", index)
+	}
+
+	page, err = os.ReadFile(filepath.Join(cfg.Htdocs, "blog", "authors", "jane-doe.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "https://example.com/one") || !strings.Contains(string(page), "https://example.com/two") {
+		t.Errorf("unexpected items in the author page\n%s", page)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Htdocs, "blog", "authors", "john-smith.html")); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(src), "https://example.com/blog/tags/static-sites.xml") {
+		t.Errorf("expected the feed's link in the collection's tags directory\n%s", src)
+	}
+
+	// Another collection with the same tag writes its own pages
+	other := &Collection{Title: "News", File: filepath.Join(dName, "news.md"), DbName: filepath.Join(dName, "news.db"), TagPages: true}
+	if err := setupDatabase(other.File, other.DbName); err != nil {
+		t.Fatal(err)
+	}
+	otherDB, err := sql.Open("sqlite", other.DbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherDB.Close()
+	if _, err := otherDB.Exec(`INSERT INTO items (link, title, description, authors, categories, guid, pubDate, status,
+		enclosures, dcExt, channel, label, updated, postPath)
+		VALUES ('https://example.com/news', 'News', 'News description', '', '["Go"]', '', '2025-09-01', 'published', '', '', '', '', '', '')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.GenerateTags(otherDB, "antenna", cfg, other); err != nil {
+		t.Fatal(err)
+	}
+	page, err = os.ReadFile(filepath.Join(cfg.Htdocs, "blog", "tags", "go.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(page), "https://example.com/news") || !strings.Contains(string(page), "https://example.com/one") {
+		t.Errorf("expected the blog's go tag page to keep its own items\n%s", page)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Htdocs, "news", "tags", "go.html")); err != nil {
+		t.Error(err)
+	}
+
+	// Pages of tags no longer used are removed
+	if _, err := db.Exec(`UPDATE items SET categories = '["Go"]' WHERE link = 'https://example.com/one'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.GenerateTags(db, "antenna", cfg, col); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"static-sites.html", "static-sites.xml"} {
+		if _, err := os.Stat(filepath.Join(cfg.Htdocs, "blog", "tags", name)); !os.IsNotExist(err) {
+			t.Errorf("expected the stale %s to be removed, %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Htdocs, "blog", "tags", "index.html")); err != nil {
+		t.Error(err)
+	}
+}
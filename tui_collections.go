@@ -485,7 +485,7 @@ func harvestCollection(scanner *bufio.Scanner, options []string, cfgName string,
 		}
 		term.Printf("Harvesting %s\n", cName)
 		// Harvest the collection
-		if err := col.Harvest(os.Stdout, os.Stderr, cfg.UserAgent); err != nil {
+		if err := col.Harvest(os.Stdout, os.Stderr, cfg, nil); err != nil {
 			displayErrorStatus("warning %s: %s\n", col.File, err)
 		}
 	}